@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/models"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -41,6 +42,9 @@ type accumulator struct {
 	maker MetricMaker
 
 	precision time.Duration
+
+	overflowPolicy string
+	dropped        selfstat.Stat
 }
 
 func (ac *accumulator) AddFields(
@@ -50,7 +54,7 @@ func (ac *accumulator) AddFields(
 	t ...time.Time,
 ) {
 	if m := ac.maker.MakeMetric(measurement, fields, tags, telegraf.Untyped, ac.getTime(t)); m != nil {
-		ac.metrics <- m
+		ac.addMetric(m)
 	}
 }
 
@@ -61,7 +65,7 @@ func (ac *accumulator) AddGauge(
 	t ...time.Time,
 ) {
 	if m := ac.maker.MakeMetric(measurement, fields, tags, telegraf.Gauge, ac.getTime(t)); m != nil {
-		ac.metrics <- m
+		ac.addMetric(m)
 	}
 }
 
@@ -72,7 +76,7 @@ func (ac *accumulator) AddCounter(
 	t ...time.Time,
 ) {
 	if m := ac.maker.MakeMetric(measurement, fields, tags, telegraf.Counter, ac.getTime(t)); m != nil {
-		ac.metrics <- m
+		ac.addMetric(m)
 	}
 }
 
@@ -83,7 +87,7 @@ func (ac *accumulator) AddSummary(
 	t ...time.Time,
 ) {
 	if m := ac.maker.MakeMetric(measurement, fields, tags, telegraf.Summary, ac.getTime(t)); m != nil {
-		ac.metrics <- m
+		ac.addMetric(m)
 	}
 }
 
@@ -94,7 +98,7 @@ func (ac *accumulator) AddHistogram(
 	t ...time.Time,
 ) {
 	if m := ac.maker.MakeMetric(measurement, fields, tags, telegraf.Histogram, ac.getTime(t)); m != nil {
-		ac.metrics <- m
+		ac.addMetric(m)
 	}
 }
 
@@ -109,6 +113,43 @@ func (ac *accumulator) AddError(err error) {
 	log.Printf("E! Error in plugin [%s]: %s", ac.maker.Name(), err)
 }
 
+// SetOverflowPolicy sets what happens to a new metric when the metrics
+// channel is full, see models.OverflowBlock and friends. Dropped metrics are
+// counted in dropped.
+func (ac *accumulator) SetOverflowPolicy(policy string, dropped selfstat.Stat) {
+	ac.overflowPolicy = policy
+	ac.dropped = dropped
+}
+
+// addMetric adds a metric to the metrics channel according to the overflow
+// policy.
+func (ac *accumulator) addMetric(m telegraf.Metric) {
+	switch ac.overflowPolicy {
+	case models.OverflowDropNewest:
+		select {
+		case ac.metrics <- m:
+		default:
+			ac.dropped.Incr(1)
+		}
+	case models.OverflowDropOldest:
+		for {
+			select {
+			case ac.metrics <- m:
+				return
+			default:
+			}
+			// make room, unless the channel was drained in the meantime
+			select {
+			case <-ac.metrics:
+				ac.dropped.Incr(1)
+			default:
+			}
+		}
+	default:
+		ac.metrics <- m
+	}
+}
+
 // SetPrecision takes two time.Duration objects. If the first is non-zero,
 // it sets that as the precision. Otherwise, it takes the second argument
 // as the order of time that the metrics should be rounded to, with the
@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/models"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -309,6 +311,42 @@ func TestAddCounter(t *testing.T) {
 	assert.Equal(t, testm.Type(), telegraf.Counter)
 }
 
+func TestAddOverflowDropNewest(t *testing.T) {
+	metrics := make(chan telegraf.Metric, 2)
+	dropped := selfstat.Register("test", "dropped_newest", map[string]string{})
+	a := NewAccumulator(&TestMetricMaker{}, metrics)
+	a.SetOverflowPolicy(models.OverflowDropNewest, dropped)
+
+	for i := 0; i < 5; i++ {
+		a.AddFields("acctest",
+			map[string]interface{}{"value": i},
+			map[string]string{})
+	}
+
+	require.Len(t, metrics, 2)
+	assert.Equal(t, int64(3), dropped.Get())
+	assert.Equal(t, int64(0), (<-metrics).Fields()["value"])
+	assert.Equal(t, int64(1), (<-metrics).Fields()["value"])
+}
+
+func TestAddOverflowDropOldest(t *testing.T) {
+	metrics := make(chan telegraf.Metric, 2)
+	dropped := selfstat.Register("test", "dropped_oldest", map[string]string{})
+	a := NewAccumulator(&TestMetricMaker{}, metrics)
+	a.SetOverflowPolicy(models.OverflowDropOldest, dropped)
+
+	for i := 0; i < 5; i++ {
+		a.AddFields("acctest",
+			map[string]interface{}{"value": i},
+			map[string]string{})
+	}
+
+	require.Len(t, metrics, 2)
+	assert.Equal(t, int64(3), dropped.Get())
+	assert.Equal(t, int64(3), (<-metrics).Fields()["value"])
+	assert.Equal(t, int64(4), (<-metrics).Fields()["value"])
+}
+
 type TestMetricMaker struct {
 }
 
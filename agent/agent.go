@@ -2,8 +2,10 @@ package agent
 
 import (
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"runtime"
 	"sync"
 	"time"
@@ -12,61 +14,163 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/config"
 	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/telegraf/internal/state"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
+var (
+	// lengths of the channels between the stages of the metric pipeline,
+	// a full channel shows which stage is blocking.
+	ProcessorQueueLength = selfstat.Register("agent", "processor_queue_length", map[string]string{})
+	OutputQueueLength    = selfstat.Register("agent", "output_queue_length", map[string]string{})
+)
+
 // Agent runs telegraf and collects data based on the given config
 type Agent struct {
 	Config *config.Config
+
+	// guards the plugin lists of Config, which are replaced by a reload
+	// while metrics are routed through them
+	mu sync.RWMutex
+
+	reloadC chan reloadRequest
+	// closed when Run returns
+	stopped chan struct{}
+
+	inputs      map[*models.RunningInput]*inputTask
+	aggregators map[*models.RunningAggregator]*aggregatorTask
+
+	// state holds the state of the plugins, kept across reloads and saved
+	// to the state directory
+	state *state.Store
 }
 
 // NewAgent returns an Agent struct based off the given Config
 func NewAgent(config *config.Config) (*Agent, error) {
 	a := &Agent{
-		Config: config,
+		Config:      config,
+		reloadC:     make(chan reloadRequest),
+		stopped:     make(chan struct{}),
+		inputs:      make(map[*models.RunningInput]*inputTask),
+		aggregators: make(map[*models.RunningAggregator]*aggregatorTask),
+	}
+
+	if err := setHostname(config); err != nil {
+		return nil, err
+	}
+
+	var err error
+	a.state, err = state.Open(config.Agent.StateDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading the state of %s, %s",
+			config.Agent.StateDirectory, err)
+	}
+	return a, nil
+}
+
+// setStateStore gives a plugin keeping state the store of its namespace.
+func (a *Agent) setStateStore(plugin interface{}, namespace string) {
+	if p, ok := plugin.(telegraf.StatefulPlugin); ok {
+		p.SetStateStore(a.state.Namespace(namespace))
+	}
+}
+
+// saveState saves the state of the plugins to the state directory.
+func (a *Agent) saveState() {
+	if err := a.state.Save(); err != nil {
+		log.Printf("E! Error saving the state to %s, %s\n",
+			a.Config.Agent.StateDirectory, err)
 	}
+}
 
-	if !a.Config.Agent.OmitHostname {
-		if a.Config.Agent.Hostname == "" {
+// setHostname sets the host tag of the configuration
+func setHostname(config *config.Config) error {
+	if !config.Agent.OmitHostname {
+		if config.Agent.Hostname == "" {
 			hostname, err := os.Hostname()
 			if err != nil {
-				return nil, err
+				return err
 			}
 
-			a.Config.Agent.Hostname = hostname
+			config.Agent.Hostname = hostname
 		}
 
-		config.Tags["host"] = a.Config.Agent.Hostname
+		config.Tags["host"] = config.Agent.Hostname
 	}
-
-	return a, nil
+	return nil
 }
 
 // Connect connects to all configured outputs
 func (a *Agent) Connect() error {
 	for _, o := range a.Config.Outputs {
-		switch ot := o.Output.(type) {
-		case telegraf.ServiceOutput:
-			if err := ot.Start(); err != nil {
-				log.Printf("E! Service for output %s failed to start, exiting\n%s\n",
-					o.Name, err.Error())
-				return err
-			}
+		if err := a.connectOutput(o, false); err != nil {
+			return err
+		}
+	}
+	a.warnUnusedBuffers()
+	return nil
+}
+
+// warnUnusedBuffers warns about the disk buffers no output uses, ie the
+// buffer of an output without an alias whose configuration changed. Their
+// metrics are only written once an output uses them again.
+func (a *Agent) warnUnusedBuffers() {
+	path := a.Config.Agent.MetricBufferPath
+	if path == "" {
+		return
+	}
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return
+	}
+	used := make(map[string]bool)
+	for _, o := range a.Config.Outputs {
+		used[filepath.Base(o.Config.DiskBufferPath)] = true
+	}
+	for _, f := range files {
+		if f.IsDir() && !used[f.Name()] {
+			log.Printf("W! Disk buffer %s is not used by any output, its "+
+				"metrics are not written\n", filepath.Join(path, f.Name()))
 		}
+	}
+}
 
-		log.Printf("D! Attempting connection to output: %s\n", o.Name)
-		err := o.Output.Connect()
+// connectOutput opens the disk buffer of an output, starts and connects it.
+// An output failing to connect is connected in the background if it is
+// configured to, or if background is true.
+func (a *Agent) connectOutput(o *models.RunningOutput, background bool) error {
+	if err := o.OpenDiskBuffer(); err != nil {
+		return err
+	}
+	a.setStateStore(o.Output, o.Config.StateNamespace)
+
+	switch ot := o.Output.(type) {
+	case telegraf.ServiceOutput:
+		if err := ot.Start(); err != nil {
+			log.Printf("E! Service for output %s failed to start, exiting\n%s\n",
+				o.Name, err.Error())
+			return err
+		}
+	}
+
+	log.Printf("D! Attempting connection to output: %s\n", o.Name)
+	err := o.Output.Connect()
+	if err != nil && (o.Config.BackgroundConnect || background) {
+		log.Printf("E! Failed to connect to output %s, connecting in the "+
+			"background, error was '%s' \n", o.Name, err)
+		o.ConnectBackground()
+		return nil
+	}
+	if err != nil {
+		log.Printf("E! Failed to connect to output %s, retrying in 15s, "+
+			"error was '%s' \n", o.Name, err)
+		time.Sleep(15 * time.Second)
+		err = o.Output.Connect()
 		if err != nil {
-			log.Printf("E! Failed to connect to output %s, retrying in 15s, "+
-				"error was '%s' \n", o.Name, err)
-			time.Sleep(15 * time.Second)
-			err = o.Output.Connect()
-			if err != nil {
-				return err
-			}
+			return err
 		}
-		log.Printf("D! Successfully connected to output: %s\n", o.Name)
 	}
+	log.Printf("D! Successfully connected to output: %s\n", o.Name)
 	return nil
 }
 
@@ -74,7 +178,7 @@ func (a *Agent) Connect() error {
 func (a *Agent) Close() error {
 	var err error
 	for _, o := range a.Config.Outputs {
-		err = o.Output.Close()
+		err = o.Close()
 		switch ot := o.Output.(type) {
 		case telegraf.ServiceOutput:
 			ot.Stop()
@@ -96,81 +200,120 @@ func panicRecover(input *models.RunningInput) {
 }
 
 // gatherer runs the inputs that have been configured with their own
-// reporting interval.
+// reporting interval. A collection is skipped when the previous one of the
+// input is still running, so a hung input is never called concurrently.
 func (a *Agent) gatherer(
 	shutdown chan struct{},
 	input *models.RunningInput,
 	interval time.Duration,
-	metricC chan telegraf.Metric,
+	acc *accumulator,
 ) {
-	defer panicRecover(input)
-
 	GatherTime := selfstat.RegisterTiming("gather",
 		"gather_time_ns",
 		map[string]string{"input": input.Config.Name},
 	)
 
-	acc := NewAccumulator(input, metricC)
 	acc.SetPrecision(a.Config.Agent.Precision.Duration,
 		a.Config.Agent.Interval.Duration)
 
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
+	done := make(chan error, 1)
+	running := false
+	var start time.Time
+
 	for {
 		internal.RandomSleep(a.Config.Agent.CollectionJitter.Duration, shutdown)
 
-		start := time.Now()
-		gatherWithTimeout(shutdown, input, acc, interval)
-		elapsed := time.Since(start)
-
-		GatherTime.Incr(elapsed.Nanoseconds())
+		if running {
+			input.GathersSkipped.Incr(1)
+			acc.AddError(fmt.Errorf("took longer to collect than collection "+
+				"interval (%s), skipping collection", interval))
+		} else {
+			running = true
+			start = time.Now()
+			go gather(input, acc, done)
+		}
 
-		select {
-		case <-shutdown:
-			return
-		case <-ticker.C:
-			continue
+		for waiting := true; waiting; {
+			select {
+			case err := <-done:
+				running = false
+				GatherTime.Incr(time.Since(start).Nanoseconds())
+				if err != nil {
+					acc.AddError(err)
+				}
+			case <-ticker.C:
+				waiting = false
+			case <-shutdown:
+				return
+			}
 		}
 	}
 }
 
-// gatherWithTimeout gathers from the given input, with the given timeout.
-//   when the given timeout is reached, gatherWithTimeout logs an error message
-//   but continues waiting for it to return. This is to avoid leaving behind
-//   hung processes, and to prevent re-calling the same hung process over and
-//   over.
-func gatherWithTimeout(
+// gather gathers from the given input once, and sends the result to done.
+func gather(input *models.RunningInput, acc *accumulator, done chan<- error) {
+	var err error
+	defer func() {
+		done <- err
+	}()
+	defer panicRecover(input)
+
+	err = input.Input.Gather(acc)
+}
+
+// forwarder moves the metrics of one input from its queue to the shared
+// metric channel of the processors. It returns when shutdown is closed,
+// dropping the queued metrics, or when drain is closed, once the queued
+// metrics are forwarded.
+func forwarder(
 	shutdown chan struct{},
+	drain chan struct{},
 	input *models.RunningInput,
-	acc *accumulator,
-	timeout time.Duration,
+	queue chan telegraf.Metric,
+	metricC chan telegraf.Metric,
 ) {
-	ticker := time.NewTicker(timeout)
-	defer ticker.Stop()
-	done := make(chan error)
-	go func() {
-		done <- input.Input.Gather(acc)
-	}()
-
 	for {
 		select {
-		case err := <-done:
-			if err != nil {
-				acc.AddError(err)
+		case m := <-queue:
+			input.QueueLength.Set(int64(len(queue)))
+			select {
+			case metricC <- m:
+			case <-shutdown:
+				return
+			case <-drain:
+				metricC <- m
 			}
-			return
-		case <-ticker.C:
-			err := fmt.Errorf("took longer to collect than collection interval (%s)",
-				timeout)
-			acc.AddError(err)
-			continue
 		case <-shutdown:
 			return
+		case <-drain:
+			for len(queue) > 0 {
+				metricC <- <-queue
+			}
+			return
 		}
 	}
 }
 
+// inputQueue returns the queue size and overflow policy of an input.
+func (a *Agent) inputQueue(input *models.RunningInput) (int, string) {
+	size := input.Config.QueueSize
+	if size <= 0 {
+		size = a.Config.Agent.InputQueueSize
+	}
+	if size <= 0 {
+		size = 1
+	}
+
+	policy := input.Config.OverflowPolicy
+	if policy == "" {
+		policy = a.Config.Agent.InputOverflowPolicy
+	}
+	return size, policy
+}
+
 // Test verifies that we can 'Gather' from all inputs with their configured
 // Config struct
 func (a *Agent) Test() error {
@@ -202,6 +345,8 @@ func (a *Agent) Test() error {
 			a.Config.Agent.Interval.Duration)
 		input.SetTrace(true)
 		input.SetDefaultTags(a.Config.Tags)
+		// the state is not saved in test mode
+		a.setStateStore(input.Input, input.Config.StateNamespace)
 
 		fmt.Printf("* Plugin: %s, Collection 1\n", input.Name())
 		if input.Config.Interval != 0 {
@@ -229,6 +374,8 @@ func (a *Agent) Test() error {
 
 // flush writes a list of metrics to all configured outputs
 func (a *Agent) flush() {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	var wg sync.WaitGroup
 
 	wg.Add(len(a.Config.Outputs))
@@ -236,7 +383,12 @@ func (a *Agent) flush() {
 		go func(output *models.RunningOutput) {
 			defer wg.Done()
 			err := output.Write()
-			if err != nil {
+			switch err {
+			case nil:
+			case models.ErrBackoff, models.ErrCircuitOpen, models.ErrNotConnected:
+				log.Printf("D! Skipped writing to output [%s]: %s\n",
+					output.Name, err.Error())
+			default:
 				log.Printf("E! Error writing to output [%s]: %s\n",
 					output.Name, err.Error())
 			}
@@ -268,25 +420,8 @@ func (a *Agent) flusher(shutdown chan struct{}, metricC chan telegraf.Metric, ag
 				}
 				return
 			case m := <-outMetricC:
-				// if dropOriginal is set to true, then we will only send this
-				// metric to the aggregators, not the outputs.
-				var dropOriginal bool
-				if !m.IsAggregate() {
-					for _, agg := range a.Config.Aggregators {
-						if ok := agg.Add(m.Copy()); ok {
-							dropOriginal = true
-						}
-					}
-				}
-				if !dropOriginal {
-					for i, o := range a.Config.Outputs {
-						if i == len(a.Config.Outputs)-1 {
-							o.AddMetric(m)
-						} else {
-							o.AddMetric(m.Copy())
-						}
-					}
-				}
+				OutputQueueLength.Set(int64(len(outMetricC)))
+				a.route(m)
 			}
 		}
 	}()
@@ -303,11 +438,7 @@ func (a *Agent) flusher(shutdown chan struct{}, metricC chan telegraf.Metric, ag
 				}
 				return
 			case metric := <-aggC:
-				metrics := []telegraf.Metric{metric}
-				for _, processor := range a.Config.Processors {
-					metrics = processor.Apply(metrics...)
-				}
-				for _, m := range metrics {
+				for _, m := range a.process(metric) {
 					outMetricC <- m
 				}
 			}
@@ -325,6 +456,8 @@ func (a *Agent) flusher(shutdown chan struct{}, metricC chan telegraf.Metric, ag
 			a.flush()
 			return nil
 		case <-ticker.C:
+			ProcessorQueueLength.Set(int64(len(metricC)))
+			OutputQueueLength.Set(int64(len(outMetricC)))
 			go func() {
 				select {
 				case semaphore <- struct{}{}:
@@ -338,21 +471,55 @@ func (a *Agent) flusher(shutdown chan struct{}, metricC chan telegraf.Metric, ag
 				}
 			}()
 		case metric := <-metricC:
+			ProcessorQueueLength.Set(int64(len(metricC)))
 			// NOTE potential bottleneck here as we put each metric through the
 			// processors serially.
-			mS := []telegraf.Metric{metric}
-			for _, processor := range a.Config.Processors {
-				mS = processor.Apply(mS...)
-			}
-			for _, m := range mS {
+			for _, m := range a.process(metric) {
 				outMetricC <- m
 			}
 		}
 	}
 }
 
+// process passes a metric through the processors.
+func (a *Agent) process(metric telegraf.Metric) []telegraf.Metric {
+	metrics := []telegraf.Metric{metric}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, processor := range a.Config.Processors {
+		metrics = processor.Apply(metrics...)
+	}
+	return metrics
+}
+
+// route adds a processed metric to the aggregators and the outputs.
+func (a *Agent) route(m telegraf.Metric) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	// if dropOriginal is set to true, then we will only send this
+	// metric to the aggregators, not the outputs.
+	var dropOriginal bool
+	if !m.IsAggregate() {
+		for _, agg := range a.Config.Aggregators {
+			if ok := agg.Add(m.Copy()); ok {
+				dropOriginal = true
+			}
+		}
+	}
+	if !dropOriginal {
+		for i, o := range a.Config.Outputs {
+			if i == len(a.Config.Outputs)-1 {
+				o.AddMetric(m)
+			} else {
+				o.AddMetric(m.Copy())
+			}
+		}
+	}
+}
+
 // Run runs the agent daemon, gathering every Interval
 func (a *Agent) Run(shutdown chan struct{}) error {
+	defer close(a.stopped)
 	var wg sync.WaitGroup
 
 	log.Printf("I! Agent Config: Interval:%s, Quiet:%#v, Hostname:%#v, "+
@@ -366,22 +533,23 @@ func (a *Agent) Run(shutdown chan struct{}) error {
 
 	now := time.Now()
 
-	// Start all ServicePlugins
+	for _, p := range a.Config.Processors {
+		a.setStateStore(p.Processor, p.Config.StateNamespace)
+	}
+
+	// every input adds its metrics to its own queue, applying its overflow
+	// policy when the queue is full, so a slow output does not stall all
+	// inputs at once. Start all ServicePlugins.
 	for _, input := range a.Config.Inputs {
-		input.SetDefaultTags(a.Config.Tags)
-		switch p := input.Input.(type) {
-		case telegraf.ServiceInput:
-			acc := NewAccumulator(input, metricC)
-			// Service input plugins should set their own precision of their
-			// metrics.
-			acc.SetPrecision(time.Nanosecond, 0)
-			if err := p.Start(acc); err != nil {
-				log.Printf("E! Service for input %s failed to start, exiting\n%s\n",
-					input.Name(), err.Error())
-				return err
-			}
-			defer p.Stop()
+		t, err := a.startInput(input, metricC)
+		if err != nil {
+			log.Printf("E! Service for input %s failed to start, exiting\n%s\n",
+				input.Name(), err.Error())
+			a.stopInputs()
+			a.saveState()
+			return err
 		}
+		a.inputs[input] = t
 	}
 
 	// Round collection to nearest interval by sleeping
@@ -399,31 +567,50 @@ func (a *Agent) Run(shutdown chan struct{}) error {
 		}
 	}()
 
-	wg.Add(len(a.Config.Aggregators))
 	for _, aggregator := range a.Config.Aggregators {
-		go func(agg *models.RunningAggregator) {
-			defer wg.Done()
-			acc := NewAccumulator(agg, aggC)
-			acc.SetPrecision(a.Config.Agent.Precision.Duration,
-				a.Config.Agent.Interval.Duration)
-			agg.Run(acc, now, shutdown)
-		}(aggregator)
+		a.aggregators[aggregator] = a.startAggregator(aggregator, aggC, now)
 	}
 
-	wg.Add(len(a.Config.Inputs))
 	for _, input := range a.Config.Inputs {
-		interval := a.Config.Agent.Interval.Duration
-		// overwrite global interval if this plugin has it's own.
-		if input.Config.Interval != 0 {
-			interval = input.Config.Interval
+		a.startGatherer(a.inputs[input])
+	}
+
+	// the state is saved every flush interval, so little is lost on a crash
+	stateTicker := time.NewTicker(a.Config.Agent.FlushInterval.Duration)
+	defer stateTicker.Stop()
+
+	for {
+		select {
+		case <-shutdown:
+			for _, t := range a.inputs {
+				t.halt()
+			}
+			for agg, t := range a.aggregators {
+				t.halt()
+				delete(a.aggregators, agg)
+			}
+			wg.Wait()
+			a.Close()
+			for input, t := range a.inputs {
+				t.stopService()
+				delete(a.inputs, input)
+			}
+			a.saveState()
+			return nil
+		case req := <-a.reloadC:
+			req.done <- a.reload(req.config, metricC, aggC)
+			a.saveState()
+		case <-stateTicker.C:
+			a.saveState()
 		}
-		go func(in *models.RunningInput, interv time.Duration) {
-			defer wg.Done()
-			a.gatherer(shutdown, in, interv, metricC)
-		}(input, interval)
 	}
+}
 
-	wg.Wait()
-	a.Close()
-	return nil
+// stopInputs stops the inputs started so far.
+func (a *Agent) stopInputs() {
+	for input, t := range a.inputs {
+		t.halt()
+		t.stopService()
+		delete(a.inputs, input)
+	}
 }
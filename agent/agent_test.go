@@ -1,9 +1,17 @@
 package agent
 
 import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal/config"
+	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/telegraf/internal/state"
 
 	// needing to load the plugins
 	_ "github.com/influxdata/telegraf/plugins/inputs/all"
@@ -11,6 +19,7 @@ import (
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAgent_OmitHostname(t *testing.T) {
@@ -109,3 +118,108 @@ func TestAgent_LoadOutput(t *testing.T) {
 	a, _ = NewAgent(c)
 	assert.Equal(t, 3, len(a.Config.Outputs))
 }
+
+// hungInput blocks in Gather until it is released
+type hungInput struct {
+	calls   int32
+	release chan struct{}
+}
+
+func (i *hungInput) SampleConfig() string { return "" }
+func (i *hungInput) Description() string  { return "" }
+func (i *hungInput) Gather(acc telegraf.Accumulator) error {
+	atomic.AddInt32(&i.calls, 1)
+	<-i.release
+	return nil
+}
+
+func TestAgent_GathererSkipsHungInput(t *testing.T) {
+	c := config.NewConfig()
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+
+	input := &hungInput{release: make(chan struct{})}
+	ri := models.NewRunningInput(input, &models.InputConfig{Name: "hung"})
+	acc := NewAccumulator(ri, make(chan telegraf.Metric, 10))
+
+	shutdown := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		a.gatherer(shutdown, ri, 10*time.Millisecond, acc)
+		close(done)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	close(shutdown)
+	<-done
+	close(input.release)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&input.calls))
+	assert.True(t, ri.GathersSkipped.Get() > 0)
+}
+
+// statefulInput counts its gathers in its state store
+type statefulInput struct {
+	store telegraf.StateStore
+	// start is the count in the store when it was set
+	start int
+}
+
+func (i *statefulInput) SampleConfig() string { return "" }
+func (i *statefulInput) Description() string  { return "" }
+func (i *statefulInput) SetStateStore(store telegraf.StateStore) {
+	i.store = store
+	i.start = i.gathers()
+}
+func (i *statefulInput) Gather(acc telegraf.Accumulator) error {
+	i.store.Set("gathers", []byte(strconv.Itoa(i.gathers()+1)))
+	return nil
+}
+func (i *statefulInput) gathers() int {
+	v, _ := i.store.Get("gathers")
+	n, _ := strconv.Atoi(string(v))
+	return n
+}
+
+func TestAgent_State(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	run := func() *statefulInput {
+		c := newReloadConfig()
+		c.Agent.StateDirectory = dir
+		input := &statefulInput{}
+		c.Inputs = append(c.Inputs, models.NewRunningInput(input,
+			&models.InputConfig{Name: "stateful", StateNamespace: "inputs.stateful"}))
+		a, err := NewAgent(c)
+		require.NoError(t, err)
+
+		shutdown := make(chan struct{})
+		done := make(chan error)
+		go func() {
+			done <- a.Run(shutdown)
+		}()
+		time.Sleep(100 * time.Millisecond)
+		close(shutdown)
+		require.NoError(t, <-done)
+		return input
+	}
+
+	first := run()
+	assert.Equal(t, 0, first.start)
+
+	// the state is saved when the agent stops
+	s, err := state.Open(dir)
+	require.NoError(t, err)
+	v, ok := s.Namespace("inputs.stateful").Get("gathers")
+	require.True(t, ok)
+	saved, err := strconv.Atoi(string(v))
+	require.NoError(t, err)
+	assert.True(t, saved > 0)
+
+	// and is given back to the plugin after a restart
+	second := run()
+	assert.Equal(t, saved, second.start)
+	assert.True(t, second.gathers() > saved)
+}
@@ -0,0 +1,152 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/models"
+)
+
+// Once runs every input once, passes their metrics through the processors
+// and the aggregators, and writes them to the outputs before returning. The
+// service inputs run for the given window, the agent interval when it is
+// zero, and the aggregators push their aggregates when the inputs are done.
+// The outputs must be connected. It returns an error when any plugin failed.
+func (a *Agent) Once(window time.Duration) error {
+	if window <= 0 {
+		window = a.Config.Agent.Interval.Duration
+	}
+	gatherErrors := NErrors.Get()
+	rejected := make(map[*models.RunningOutput]int64)
+	for _, o := range a.Config.Outputs {
+		rejected[o] = o.MetricsRejected.Get()
+	}
+
+	metricC := make(chan telegraf.Metric, 100)
+	aggC := make(chan telegraf.Metric, 100)
+	// closed once the metrics of the inputs, then of the aggregators, are
+	// routed
+	inputsRouted := make(chan struct{})
+	routed := make(chan struct{})
+	go func() {
+		defer close(routed)
+		in, agg := metricC, aggC
+		for in != nil || agg != nil {
+			var metric telegraf.Metric
+			var ok bool
+			select {
+			case metric, ok = <-in:
+				if !ok {
+					in = nil
+					close(inputsRouted)
+					continue
+				}
+			case metric, ok = <-agg:
+				if !ok {
+					agg = nil
+					continue
+				}
+			}
+			for _, m := range a.process(metric) {
+				a.route(m)
+			}
+		}
+	}()
+
+	for _, p := range a.Config.Processors {
+		a.setStateStore(p.Processor, p.Config.StateNamespace)
+	}
+
+	var errs []string
+	for _, input := range a.Config.Inputs {
+		t, err := a.startInput(input, metricC)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Service for input %s failed to start: %s",
+				input.Name(), err))
+			continue
+		}
+		a.inputs[input] = t
+	}
+
+	now := time.Now()
+	for _, agg := range a.Config.Aggregators {
+		a.aggregators[agg] = a.startAggregator(agg, aggC, now)
+	}
+
+	// gather every input once, the service inputs keep adding metrics
+	// until the end of the window
+	var wg sync.WaitGroup
+	var services bool
+	for _, t := range a.inputs {
+		if _, ok := t.input.Input.(telegraf.ServiceInput); ok {
+			services = true
+		} else {
+			t.acc.SetPrecision(a.Config.Agent.Precision.Duration,
+				a.Config.Agent.Interval.Duration)
+		}
+		wg.Add(1)
+		go func(t *inputTask) {
+			defer wg.Done()
+			done := make(chan error, 1)
+			gather(t.input, t.acc, done)
+			if err := <-done; err != nil {
+				t.acc.AddError(err)
+			}
+		}(t)
+	}
+	wg.Wait()
+	if services {
+		time.Sleep(window)
+	}
+
+	// stop the services first, so the metrics they add while stopping are
+	// forwarded, the forwarders pass on what is left in the queues
+	for _, t := range a.inputs {
+		t.stopService()
+	}
+	for input, t := range a.inputs {
+		t.drainHalt()
+		delete(a.inputs, input)
+	}
+	close(metricC)
+	<-inputsRouted
+
+	// the aggregators push the aggregates of the unfinished period
+	for agg, t := range a.aggregators {
+		t.halt()
+		acc := NewAccumulator(agg, aggC)
+		acc.SetPrecision(a.Config.Agent.Precision.Duration,
+			a.Config.Agent.Interval.Duration)
+		agg.Push(acc)
+		delete(a.aggregators, agg)
+	}
+	close(aggC)
+	<-routed
+
+	for _, o := range a.Config.Outputs {
+		if err := o.Write(); err != nil {
+			errs = append(errs, fmt.Sprintf("Error writing to output [%s]: %s",
+				o.Name, err))
+		}
+		if n := o.MetricsRejected.Get() - rejected[o]; n > 0 {
+			errs = append(errs, fmt.Sprintf("Output [%s] rejected %d metrics",
+				o.Name, n))
+		}
+	}
+	if err := a.Close(); err != nil {
+		errs = append(errs, fmt.Sprintf("Error closing the outputs: %s", err))
+	}
+	a.saveState()
+
+	if n := NErrors.Get() - gatherErrors; n > 0 {
+		errs = append(errs, fmt.Sprintf("%d plugin errors, see the log", n))
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
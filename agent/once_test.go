@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serviceInput adds a metric when it starts and when it stops
+type serviceInput struct {
+	acc     telegraf.Accumulator
+	stopped int32
+}
+
+func (i *serviceInput) SampleConfig() string { return "" }
+func (i *serviceInput) Description() string  { return "" }
+func (i *serviceInput) Gather(acc telegraf.Accumulator) error {
+	return nil
+}
+func (i *serviceInput) Start(acc telegraf.Accumulator) error {
+	i.acc = acc
+	acc.AddFields("service", map[string]interface{}{"started": true}, nil)
+	return nil
+}
+func (i *serviceInput) Stop() {
+	atomic.StoreInt32(&i.stopped, 1)
+	i.acc.AddFields("service", map[string]interface{}{"stopped": true}, nil)
+}
+
+// countAggregator counts the metrics added in its period
+type countAggregator struct {
+	count int
+}
+
+func (a *countAggregator) SampleConfig() string { return "" }
+func (a *countAggregator) Description() string  { return "" }
+func (a *countAggregator) Add(in telegraf.Metric) {
+	a.count++
+}
+func (a *countAggregator) Push(acc telegraf.Accumulator) {
+	acc.AddFields("aggregate", map[string]interface{}{"count": a.count}, nil)
+}
+func (a *countAggregator) Reset() {
+	a.count = 0
+}
+
+// errorInput fails to gather
+type errorInput struct{}
+
+func (i *errorInput) SampleConfig() string { return "" }
+func (i *errorInput) Description() string  { return "" }
+func (i *errorInput) Gather(acc telegraf.Accumulator) error {
+	return errors.New("gather failed")
+}
+
+func TestAgent_Once(t *testing.T) {
+	c := newReloadConfig()
+	input := addReloadInput(c, "count", "count")
+	service := &serviceInput{}
+	c.Inputs = append(c.Inputs, models.NewRunningInput(service,
+		&models.InputConfig{Name: "service"}))
+	c.Aggregators = append(c.Aggregators, models.NewRunningAggregator(
+		&countAggregator{}, &models.AggregatorConfig{
+			Name:   "count",
+			Period: time.Hour,
+		}))
+	output := addReloadOutput(c, "once", "once")
+
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+	require.NoError(t, a.Connect())
+	require.NoError(t, a.Once(50*time.Millisecond))
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&input.gathers))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&service.stopped))
+	assert.Empty(t, a.inputs)
+	assert.Empty(t, a.aggregators)
+
+	// the metrics of the inputs, including the one added by the service
+	// input when it stopped, and the aggregate of the unfinished period
+	// are written before Once returns
+	connects, closes, _ := output.stats()
+	assert.Equal(t, 1, connects)
+	assert.Equal(t, 1, closes)
+	output.Lock()
+	defer output.Unlock()
+	names := make(map[string]int)
+	for _, m := range output.metrics {
+		names[m.Name()]++
+		if m.Name() == "aggregate" {
+			assert.Equal(t, map[string]interface{}{"count": int64(3)}, m.Fields())
+		}
+	}
+	assert.Equal(t, map[string]int{"count": 1, "service": 2, "aggregate": 1}, names)
+}
+
+func TestAgent_OnceError(t *testing.T) {
+	c := newReloadConfig()
+	addReloadInput(c, "count", "count")
+	c.Inputs = append(c.Inputs, models.NewRunningInput(&errorInput{},
+		&models.InputConfig{Name: "error"}))
+	output := addReloadOutput(c, "once", "once")
+
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+	require.NoError(t, a.Connect())
+	assert.Error(t, a.Once(0))
+
+	// the metrics of the other inputs are written all the same
+	_, _, written := output.stats()
+	assert.Equal(t, 1, written)
+}
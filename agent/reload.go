@@ -0,0 +1,382 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/config"
+	"github.com/influxdata/telegraf/internal/models"
+)
+
+var (
+	// ErrRestartRequired is returned by Reload when the agent settings or the
+	// global tags changed, which requires restarting every plugin.
+	ErrRestartRequired = errors.New("agent settings or global tags changed")
+
+	// ErrNotRunning is returned by Reload when the agent is not running.
+	ErrNotRunning = errors.New("agent is not running")
+)
+
+// inputTask runs one input: the forwarder of its queue, its service and its
+// gatherer. It is stopped on its own when the input is removed by a reload.
+type inputTask struct {
+	input *models.RunningInput
+	acc   *accumulator
+	queue chan telegraf.Metric
+	stop  chan struct{} // stops the gatherer
+	drop  chan struct{} // stops the forwarder, dropping the queue
+	drain chan struct{} // stops the forwarder once the queue is forwarded
+
+	gathering  sync.WaitGroup
+	forwarding sync.WaitGroup
+}
+
+// startInput creates the queue of an input, forwards it to metricC and
+// starts the input if it is a service input. The gatherer is started with
+// startGatherer.
+func (a *Agent) startInput(
+	input *models.RunningInput,
+	metricC chan telegraf.Metric,
+) (*inputTask, error) {
+	size, policy := a.inputQueue(input)
+	t := &inputTask{
+		input: input,
+		queue: make(chan telegraf.Metric, size),
+		stop:  make(chan struct{}),
+		drop:  make(chan struct{}),
+		drain: make(chan struct{}),
+	}
+	t.acc = NewAccumulator(input, t.queue)
+	t.acc.SetOverflowPolicy(policy, input.MetricsDropped)
+	input.SetDefaultTags(a.Config.Tags)
+	a.setStateStore(input.Input, input.Config.StateNamespace)
+
+	t.forwarding.Add(1)
+	go func() {
+		defer t.forwarding.Done()
+		forwarder(t.drop, t.drain, input, t.queue, metricC)
+	}()
+
+	if p, ok := input.Input.(telegraf.ServiceInput); ok {
+		// Service input plugins should set their own precision of their
+		// metrics.
+		t.acc.SetPrecision(time.Nanosecond, 0)
+		if err := p.Start(t.acc); err != nil {
+			t.halt()
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// startGatherer starts collecting the input on its interval.
+func (a *Agent) startGatherer(t *inputTask) {
+	interval := a.Config.Agent.Interval.Duration
+	// overwrite global interval if this plugin has it's own.
+	if t.input.Config.Interval != 0 {
+		interval = t.input.Config.Interval
+	}
+	t.gathering.Add(1)
+	go func() {
+		defer t.gathering.Done()
+		a.gatherer(t.stop, t.input, interval, t.acc)
+	}()
+}
+
+// halt stops the gatherer and the forwarder of the input, dropping the
+// metrics left in the queue.
+func (t *inputTask) halt() {
+	close(t.stop)
+	t.gathering.Wait()
+	close(t.drop)
+	t.forwarding.Wait()
+}
+
+// drainHalt stops the gatherer and the forwarder of the input once the
+// metrics left in the queue are forwarded, metricC must be read until it
+// returns.
+func (t *inputTask) drainHalt() {
+	close(t.stop)
+	t.gathering.Wait()
+	close(t.drain)
+	t.forwarding.Wait()
+}
+
+// stopService stops the input if it is a service input.
+func (t *inputTask) stopService() {
+	if p, ok := t.input.Input.(telegraf.ServiceInput); ok {
+		p.Stop()
+	}
+}
+
+// aggregatorTask runs one aggregator until it is halted.
+type aggregatorTask struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// startAggregator runs an aggregator, pushing its metrics to aggC.
+func (a *Agent) startAggregator(
+	agg *models.RunningAggregator,
+	aggC chan telegraf.Metric,
+	now time.Time,
+) *aggregatorTask {
+	a.setStateStore(agg.Aggregator(), agg.Config.StateNamespace)
+	t := &aggregatorTask{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(t.done)
+		acc := NewAccumulator(agg, aggC)
+		acc.SetPrecision(a.Config.Agent.Precision.Duration,
+			a.Config.Agent.Interval.Duration)
+		agg.Run(acc, now, t.stop)
+	}()
+	return t
+}
+
+// halt stops the aggregator.
+func (t *aggregatorTask) halt() {
+	close(t.stop)
+	<-t.done
+}
+
+// reloadRequest asks the running agent to switch to a new configuration.
+type reloadRequest struct {
+	config *config.Config
+	done   chan error
+}
+
+// Reload applies a new configuration to the running agent. Only the plugins
+// whose configuration table changed are stopped and started, the other
+// plugins keep running, and unchanged outputs keep their buffered metrics.
+// It returns ErrRestartRequired when the agent settings or the global tags
+// changed, the running configuration is kept in that case.
+func (a *Agent) Reload(c *config.Config) error {
+	if err := setHostname(c); err != nil {
+		return err
+	}
+	req := reloadRequest{config: c, done: make(chan error, 1)}
+	select {
+	case a.reloadC <- req:
+	case <-a.stopped:
+		return ErrNotRunning
+	}
+	return <-req.done
+}
+
+// matchDigests pairs every new plugin with a running plugin with the same
+// digest. It returns the index of the running twin of every new plugin, or
+// -1, and whether every running plugin has a twin.
+func matchDigests(running, next []string) ([]int, []bool) {
+	free := make(map[string][]int)
+	for i, d := range running {
+		free[d] = append(free[d], i)
+	}
+
+	twins := make([]int, len(next))
+	kept := make([]bool, len(running))
+	for i, d := range next {
+		twins[i] = -1
+		if idx := free[d]; len(idx) > 0 {
+			twins[i] = idx[0]
+			kept[idx[0]] = true
+			free[d] = idx[1:]
+		}
+	}
+	return twins, kept
+}
+
+// reload swaps the plugins of the running configuration with the ones of c,
+// keeping the running plugins whose configuration did not change.
+func (a *Agent) reload(
+	c *config.Config,
+	metricC chan telegraf.Metric,
+	aggC chan telegraf.Metric,
+) error {
+	if !reflect.DeepEqual(a.Config.Agent, c.Agent) ||
+		!reflect.DeepEqual(a.Config.Tags, c.Tags) {
+		return ErrRestartRequired
+	}
+
+	// inputs
+	var digests, nextDigests []string
+	for _, in := range a.Config.Inputs {
+		digests = append(digests, in.Digest)
+	}
+	for _, in := range c.Inputs {
+		nextDigests = append(nextDigests, in.Digest)
+	}
+	inputTwins, inputsKept := matchDigests(digests, nextDigests)
+
+	// outputs
+	digests, nextDigests = nil, nil
+	for _, o := range a.Config.Outputs {
+		digests = append(digests, o.Digest)
+	}
+	for _, o := range c.Outputs {
+		nextDigests = append(nextDigests, o.Digest)
+	}
+	outputTwins, outputsKept := matchDigests(digests, nextDigests)
+
+	// processors
+	digests, nextDigests = nil, nil
+	for _, p := range a.Config.Processors {
+		digests = append(digests, p.Digest)
+	}
+	for _, p := range c.Processors {
+		nextDigests = append(nextDigests, p.Digest)
+	}
+	processorTwins, _ := matchDigests(digests, nextDigests)
+
+	// aggregators
+	digests, nextDigests = nil, nil
+	for _, agg := range a.Config.Aggregators {
+		digests = append(digests, agg.Digest)
+	}
+	for _, agg := range c.Aggregators {
+		nextDigests = append(nextDigests, agg.Digest)
+	}
+	aggregatorTwins, aggregatorsKept := matchDigests(digests, nextDigests)
+
+	// stop the inputs which changed or were removed, the metrics left in
+	// their queues are forwarded to the flusher, which keeps running
+	var inputsStopped int
+	for i, in := range a.Config.Inputs {
+		if inputsKept[i] {
+			continue
+		}
+		t := a.inputs[in]
+		t.stopService()
+		t.drainHalt()
+		delete(a.inputs, in)
+		inputsStopped++
+	}
+
+	// only route metrics to the outputs and aggregators which are kept until
+	// the new ones are ready
+	var keptOutputs []*models.RunningOutput
+	for _, i := range outputTwins {
+		if i >= 0 {
+			keptOutputs = append(keptOutputs, a.Config.Outputs[i])
+		}
+	}
+	var keptAggregators []*models.RunningAggregator
+	for _, i := range aggregatorTwins {
+		if i >= 0 {
+			keptAggregators = append(keptAggregators, a.Config.Aggregators[i])
+		}
+	}
+	processors := make(models.RunningProcessors, len(c.Processors))
+	for i, p := range c.Processors {
+		processors[i] = p
+		if processorTwins[i] >= 0 {
+			processors[i] = a.Config.Processors[processorTwins[i]]
+			continue
+		}
+		a.setStateStore(p.Processor, p.Config.StateNamespace)
+	}
+
+	oldOutputs := a.Config.Outputs
+	oldAggregators := a.Config.Aggregators
+	a.mu.Lock()
+	a.Config.Outputs = keptOutputs
+	a.Config.Aggregators = keptAggregators
+	a.Config.Processors = processors
+	a.mu.Unlock()
+
+	// write what is left in the buffers of the removed outputs
+	var outputsStopped int
+	for i, o := range oldOutputs {
+		if outputsKept[i] {
+			continue
+		}
+		if err := o.Write(); err != nil {
+			log.Printf("E! Error writing to output [%s]: %s\n", o.Name, err)
+		}
+		if err := o.Close(); err != nil {
+			log.Printf("E! Error closing output [%s]: %s\n", o.Name, err)
+		}
+		if p, ok := o.Output.(telegraf.ServiceOutput); ok {
+			p.Stop()
+		}
+		outputsStopped++
+	}
+	for i, agg := range oldAggregators {
+		if aggregatorsKept[i] {
+			continue
+		}
+		a.aggregators[agg].halt()
+		delete(a.aggregators, agg)
+	}
+
+	// connect the new outputs, falling back to a background connection
+	// since the other plugins are running already
+	var errs []string
+	outputs := make([]*models.RunningOutput, 0, len(c.Outputs))
+	var outputsStarted int
+	for i, o := range c.Outputs {
+		if outputTwins[i] >= 0 {
+			outputs = append(outputs, oldOutputs[outputTwins[i]])
+			continue
+		}
+		if err := a.connectOutput(o, true); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		outputs = append(outputs, o)
+		outputsStarted++
+	}
+
+	// start the new inputs and aggregators
+	now := time.Now()
+	aggregators := make([]*models.RunningAggregator, len(c.Aggregators))
+	for i, agg := range c.Aggregators {
+		if aggregatorTwins[i] >= 0 {
+			aggregators[i] = oldAggregators[aggregatorTwins[i]]
+			continue
+		}
+		aggregators[i] = agg
+		a.aggregators[agg] = a.startAggregator(agg, aggC, now)
+	}
+
+	a.mu.Lock()
+	a.Config.Outputs = outputs
+	a.Config.Aggregators = aggregators
+	a.mu.Unlock()
+
+	inputs := make([]*models.RunningInput, 0, len(c.Inputs))
+	var inputsStarted int
+	for i, in := range c.Inputs {
+		if inputTwins[i] >= 0 {
+			inputs = append(inputs, a.Config.Inputs[inputTwins[i]])
+			continue
+		}
+		t, err := a.startInput(in, metricC)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("Service for input %s failed to start: %s",
+				in.Name(), err))
+			continue
+		}
+		a.startGatherer(t)
+		a.inputs[in] = t
+		inputs = append(inputs, in)
+		inputsStarted++
+	}
+	a.Config.Inputs = inputs
+
+	log.Printf("I! Reloaded config: inputs %d stopped %d started, "+
+		"outputs %d stopped %d started\n", inputsStopped, inputsStarted,
+		outputsStopped, outputsStarted)
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
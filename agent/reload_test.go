@@ -0,0 +1,317 @@
+package agent
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/config"
+	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/telegraf/plugins/outputs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countInput adds one metric on every gather
+type countInput struct {
+	gathers int32
+}
+
+func (i *countInput) SampleConfig() string { return "" }
+func (i *countInput) Description() string  { return "" }
+func (i *countInput) Gather(acc telegraf.Accumulator) error {
+	atomic.AddInt32(&i.gathers, 1)
+	acc.AddFields("count", map[string]interface{}{"value": 1}, nil)
+	return nil
+}
+
+// recordOutput keeps the metrics written to it
+type recordOutput struct {
+	sync.Mutex
+	connects int
+	closes   int
+	metrics  []telegraf.Metric
+}
+
+func (o *recordOutput) SampleConfig() string { return "" }
+func (o *recordOutput) Description() string  { return "" }
+func (o *recordOutput) Connect() error {
+	o.Lock()
+	defer o.Unlock()
+	o.connects++
+	return nil
+}
+func (o *recordOutput) Close() error {
+	o.Lock()
+	defer o.Unlock()
+	o.closes++
+	return nil
+}
+func (o *recordOutput) Write(metrics []telegraf.Metric) error {
+	o.Lock()
+	defer o.Unlock()
+	o.metrics = append(o.metrics, metrics...)
+	return nil
+}
+
+func (o *recordOutput) stats() (int, int, int) {
+	o.Lock()
+	defer o.Unlock()
+	return o.connects, o.closes, len(o.metrics)
+}
+
+// burstInput adds a burst of metrics when it stops
+type burstInput struct {
+	acc telegraf.Accumulator
+}
+
+func (i *burstInput) SampleConfig() string { return "" }
+func (i *burstInput) Description() string  { return "" }
+func (i *burstInput) Gather(acc telegraf.Accumulator) error {
+	return nil
+}
+func (i *burstInput) Start(acc telegraf.Accumulator) error {
+	i.acc = acc
+	return nil
+}
+func (i *burstInput) Stop() {
+	for n := 0; n < 50; n++ {
+		i.acc.AddFields("burst", map[string]interface{}{"value": n}, nil)
+	}
+}
+
+// bufferedOutput is a recordOutput configured from a file
+type bufferedOutput struct {
+	URL string `toml:"url"`
+	recordOutput
+}
+
+func init() {
+	outputs.Add("reload_buffered", func() telegraf.Output {
+		return &bufferedOutput{}
+	})
+}
+
+func newReloadConfig() *config.Config {
+	c := config.NewConfig()
+	c.Agent.OmitHostname = true
+	c.Agent.RoundInterval = false
+	c.Agent.Interval = internal.Duration{Duration: 10 * time.Millisecond}
+	c.Agent.FlushInterval = internal.Duration{Duration: 10 * time.Millisecond}
+	return c
+}
+
+func addReloadInput(c *config.Config, name, digest string) *countInput {
+	input := &countInput{}
+	ri := models.NewRunningInput(input, &models.InputConfig{Name: name})
+	ri.Digest = digest
+	c.Inputs = append(c.Inputs, ri)
+	return input
+}
+
+func addReloadOutput(c *config.Config, name, digest string) *recordOutput {
+	output := &recordOutput{}
+	ro := models.NewRunningOutput(name, output, &models.OutputConfig{}, 0, 0)
+	ro.Digest = digest
+	c.Outputs = append(c.Outputs, ro)
+	return output
+}
+
+func TestAgent_Reload(t *testing.T) {
+	c := newReloadConfig()
+	keptInput := addReloadInput(c, "kept", "kept")
+	changedInput := addReloadInput(c, "changed", "changed-1")
+	addReloadOutput(c, "reload_kept", "kept")
+	removedOutput := addReloadOutput(c, "reload_removed", "removed")
+	keptRunningInput := c.Inputs[0]
+	keptRunningOutput := c.Outputs[0]
+
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+	require.NoError(t, a.Connect())
+
+	shutdown := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.Run(shutdown)
+	}()
+	// the flusher starts after 300ms
+	time.Sleep(400 * time.Millisecond)
+
+	next := newReloadConfig()
+	addReloadInput(next, "kept", "kept")
+	newInput := addReloadInput(next, "changed", "changed-2")
+	keptOutput := addReloadOutput(next, "reload_kept", "kept")
+	newOutput := addReloadOutput(next, "reload_new", "new")
+	require.NoError(t, a.Reload(next))
+
+	// the unchanged plugins keep running, the changed ones are replaced
+	require.Len(t, a.Config.Inputs, 2)
+	assert.True(t, a.Config.Inputs[0] == keptRunningInput)
+	assert.True(t, a.Config.Inputs[1] == next.Inputs[1])
+	require.Len(t, a.Config.Outputs, 2)
+	assert.True(t, a.Config.Outputs[0] == keptRunningOutput)
+	assert.True(t, a.Config.Outputs[1] == next.Outputs[1])
+
+	_, closes, _ := removedOutput.stats()
+	assert.Equal(t, 1, closes)
+	connects, _, _ := keptOutput.stats()
+	assert.Equal(t, 0, connects)
+
+	// let a gather started before the input was stopped finish
+	time.Sleep(20 * time.Millisecond)
+	changedGathers := atomic.LoadInt32(&changedInput.gathers)
+	keptGathers := atomic.LoadInt32(&keptInput.gathers)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, changedGathers, atomic.LoadInt32(&changedInput.gathers))
+	assert.True(t, atomic.LoadInt32(&keptInput.gathers) > keptGathers)
+	assert.True(t, atomic.LoadInt32(&newInput.gathers) > 0)
+
+	connects, _, written := newOutput.stats()
+	assert.Equal(t, 1, connects)
+	assert.True(t, written > 0)
+
+	close(shutdown)
+	require.NoError(t, <-done)
+	assert.Equal(t, ErrNotRunning, a.Reload(newReloadConfig()))
+}
+
+func TestAgent_ReloadAgentChanged(t *testing.T) {
+	c := newReloadConfig()
+	addReloadInput(c, "input", "input")
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+
+	shutdown := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.Run(shutdown)
+	}()
+
+	next := newReloadConfig()
+	next.Agent.Interval = internal.Duration{Duration: time.Second}
+	addReloadInput(next, "input", "input")
+	assert.Equal(t, ErrRestartRequired, a.Reload(next))
+
+	next = newReloadConfig()
+	next.Tags["dc"] = "us-east-1"
+	assert.Equal(t, ErrRestartRequired, a.Reload(next))
+
+	close(shutdown)
+	require.NoError(t, <-done)
+}
+
+func TestAgent_ReloadForwardsQueue(t *testing.T) {
+	c := newReloadConfig()
+	ri := models.NewRunningInput(&burstInput{},
+		&models.InputConfig{Name: "burst", QueueSize: 100})
+	ri.Digest = "removed"
+	c.Inputs = append(c.Inputs, ri)
+	output := addReloadOutput(c, "reload_queue", "kept")
+
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+	require.NoError(t, a.Connect())
+
+	shutdown := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.Run(shutdown)
+	}()
+	// the flusher starts after 300ms
+	time.Sleep(400 * time.Millisecond)
+
+	// the metrics queued by the removed input when it stops are not lost
+	next := newReloadConfig()
+	addReloadOutput(next, "reload_queue", "kept")
+	require.NoError(t, a.Reload(next))
+
+	var written int
+	for i := 0; i < 200 && written < 50; i++ {
+		time.Sleep(10 * time.Millisecond)
+		_, _, written = output.stats()
+	}
+	assert.Equal(t, 50, written)
+
+	close(shutdown)
+	require.NoError(t, <-done)
+}
+
+// loadReloadConfig loads the outputs of a configuration given as text, which
+// buffer their metrics in dir.
+func loadReloadConfig(t *testing.T, dir, outputs string) *config.Config {
+	f, err := ioutil.TempFile(dir, "telegraf")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(`
+[agent]
+  interval = "10ms"
+  flush_interval = "10ms"
+  round_interval = false
+  omit_hostname = true
+  metric_buffer_path = "` + filepath.ToSlash(filepath.Join(dir, "buffer")) + `"
+` + outputs)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig(f.Name()))
+	return c
+}
+
+func TestAgent_ReloadInsertsOutputOfSameName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := loadReloadConfig(t, dir, `
+[[outputs.reload_buffered]]
+  url = "b"
+`)
+	addReloadInput(c, "count", "count")
+	kept := c.Outputs[0]
+
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+	require.NoError(t, a.Connect())
+
+	shutdown := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.Run(shutdown)
+	}()
+
+	// the output inserted ahead of the running one gets its own disk buffer,
+	// opening the buffer of the running one would fail
+	next := loadReloadConfig(t, dir, `
+[[outputs.reload_buffered]]
+  url = "a"
+[[outputs.reload_buffered]]
+  url = "b"
+`)
+	addReloadInput(next, "count", "count")
+	require.NoError(t, a.Reload(next))
+	require.Len(t, a.Config.Outputs, 2)
+	assert.True(t, a.Config.Outputs[1] == kept)
+	assert.Equal(t, kept.Config.DiskBufferPath, next.Outputs[1].Config.DiskBufferPath)
+	assert.NotEqual(t, kept.Config.DiskBufferPath, a.Config.Outputs[0].Config.DiskBufferPath)
+
+	// both outputs keep getting metrics
+	inserted := a.Config.Outputs[0].Output.(*bufferedOutput)
+	var written int
+	for i := 0; i < 200 && written == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		_, _, written = inserted.stats()
+	}
+	assert.True(t, written > 0)
+
+	close(shutdown)
+	require.NoError(t, <-done)
+}
@@ -31,6 +31,10 @@ var pprofAddr = flag.String("pprof-addr", "",
 var fQuiet = flag.Bool("quiet", false,
 	"run in quiet mode")
 var fTest = flag.Bool("test", false, "gather metrics, print them out, and exit")
+var fOnce = flag.Bool("once", false,
+	"gather metrics once, write them to the outputs, and exit")
+var fOnceWindow = flag.Duration("once-window", 0,
+	"time the service inputs run for with --once, defaults to the agent interval")
 var fConfig = flag.String("config", "", "configuration file to load")
 var fConfigDirectory = flag.String("config-directory", "",
 	"directory containing additional *.conf files")
@@ -85,6 +89,10 @@ The commands & flags are:
 
   --config <file>     configuration file to load
   --test              gather metrics once, print them to stdout, and exit
+  --once              gather metrics once, write them to the outputs, and exit,
+                      with a non-zero code if any plugin failed
+  --once-window       time the service inputs run for with --once, ie "30s",
+                      defaults to the agent interval
   --config-directory  directory containing additional *.conf files
   --input-filter      filter the input plugins to enable, separator is :
   --output-filter     filter the output plugins to enable, separator is :
@@ -104,6 +112,9 @@ Examples:
   # run a single telegraf collection, outputing metrics to stdout
   telegraf --config telegraf.conf --test
 
+  # run a single telegraf collection, writing metrics to the outputs
+  telegraf --config telegraf.conf --once
+
   # run telegraf with all plugins defined in config file
   telegraf --config telegraf.conf
 
@@ -129,37 +140,11 @@ func reloadLoop(
 		reload <- false
 
 		// If no other options are specified, load the config file and run.
-		c := config.NewConfig()
-		c.OutputFilters = outputFilters
-		c.InputFilters = inputFilters
-		err := c.LoadConfig(*fConfig)
+		c, err := loadConfig(inputFilters, outputFilters)
 		if err != nil {
 			log.Fatal("E! " + err.Error())
 		}
 
-		if *fConfigDirectory != "" {
-			err = c.LoadDirectory(*fConfigDirectory)
-			if err != nil {
-				log.Fatal("E! " + err.Error())
-			}
-		}
-		if !*fTest && len(c.Outputs) == 0 {
-			log.Fatalf("E! Error: no outputs found, did you provide a valid config file?")
-		}
-		if len(c.Inputs) == 0 {
-			log.Fatalf("E! Error: no inputs found, did you provide a valid config file?")
-		}
-
-		if int64(c.Agent.Interval.Duration) <= 0 {
-			log.Fatalf("E! Agent interval must be positive, found %s",
-				c.Agent.Interval.Duration)
-		}
-
-		if int64(c.Agent.FlushInterval.Duration) <= 0 {
-			log.Fatalf("E! Agent flush_interval must be positive; found %s",
-				c.Agent.Interval.Duration)
-		}
-
 		ag, err := agent.NewAgent(c)
 		if err != nil {
 			log.Fatal("E! " + err.Error())
@@ -185,23 +170,41 @@ func reloadLoop(
 			log.Fatal("E! " + err.Error())
 		}
 
+		if *fOnce {
+			err = ag.Once(*fOnceWindow)
+			if err != nil {
+				log.Fatal("E! " + err.Error())
+			}
+			os.Exit(0)
+		}
+
 		shutdown := make(chan struct{})
 		signals := make(chan os.Signal)
 		signal.Notify(signals, os.Interrupt, syscall.SIGHUP)
 		go func() {
-			select {
-			case sig := <-signals:
-				if sig == os.Interrupt {
-					close(shutdown)
-				}
-				if sig == syscall.SIGHUP {
-					log.Printf("I! Reloading Telegraf config\n")
-					<-reload
-					reload <- true
+			defer signal.Stop(signals)
+			for {
+				select {
+				case sig := <-signals:
+					if sig == os.Interrupt {
+						close(shutdown)
+						return
+					}
+					if sig == syscall.SIGHUP {
+						log.Printf("I! Reloading Telegraf config\n")
+						if !reloadConfig(ag, inputFilters, outputFilters) {
+							<-reload
+							reload <- true
+							close(shutdown)
+							return
+						}
+					}
+				case <-stop:
 					close(shutdown)
+					return
+				case <-shutdown:
+					return
 				}
-			case <-stop:
-				close(shutdown)
 			}
 		}()
 
@@ -232,6 +235,63 @@ func reloadLoop(
 	}
 }
 
+// loadConfig loads the config file and the config directory.
+func loadConfig(inputFilters, outputFilters []string) (*config.Config, error) {
+	c := config.NewConfig()
+	c.OutputFilters = outputFilters
+	c.InputFilters = inputFilters
+	err := c.LoadConfig(*fConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if *fConfigDirectory != "" {
+		err = c.LoadDirectory(*fConfigDirectory)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !*fTest && len(c.Outputs) == 0 {
+		return nil, fmt.Errorf("Error: no outputs found, did you provide a valid config file?")
+	}
+	if len(c.Inputs) == 0 {
+		return nil, fmt.Errorf("Error: no inputs found, did you provide a valid config file?")
+	}
+
+	if int64(c.Agent.Interval.Duration) <= 0 {
+		return nil, fmt.Errorf("Agent interval must be positive, found %s",
+			c.Agent.Interval.Duration)
+	}
+
+	if int64(c.Agent.FlushInterval.Duration) <= 0 {
+		return nil, fmt.Errorf("Agent flush_interval must be positive; found %s",
+			c.Agent.Interval.Duration)
+	}
+	return c, nil
+}
+
+// reloadConfig applies the config file to the running agent, restarting only
+// the plugins whose configuration changed. It returns false when the agent
+// has to be restarted to apply it. An invalid config is logged and the
+// running config is kept.
+func reloadConfig(ag *agent.Agent, inputFilters, outputFilters []string) bool {
+	c, err := loadConfig(inputFilters, outputFilters)
+	if err != nil {
+		log.Printf("E! Error loading config, keeping the running config: %s\n", err)
+		return true
+	}
+	switch err := ag.Reload(c); err {
+	case nil:
+		return true
+	case agent.ErrRestartRequired:
+		log.Printf("I! %s, restarting Telegraf\n", err)
+		return false
+	default:
+		log.Printf("E! Error reloading config: %s\n", err)
+		return true
+	}
+}
+
 func usageExit(rc int) {
 	fmt.Println(usage)
 	os.Exit(rc)
@@ -16,6 +16,9 @@ var (
 type Buffer struct {
 	buf chan telegraf.Metric
 
+	// called with the metrics dropped to make room for new ones
+	onDrop func([]telegraf.Metric)
+
 	mu sync.Mutex
 }
 
@@ -28,6 +31,12 @@ func NewBuffer(size int) *Buffer {
 	}
 }
 
+// SetDropHandler sets a function called with the oldest metrics dropped when
+// Add is called on a full buffer.
+func (b *Buffer) SetDropHandler(f func([]telegraf.Metric)) {
+	b.onDrop = f
+}
+
 // IsEmpty returns true if Buffer is empty.
 func (b *Buffer) IsEmpty() bool {
 	return len(b.buf) == 0
@@ -40,6 +49,7 @@ func (b *Buffer) Len() int {
 
 // Add adds metrics to the buffer.
 func (b *Buffer) Add(metrics ...telegraf.Metric) {
+	var dropped []telegraf.Metric
 	for i, _ := range metrics {
 		MetricsWritten.Incr(1)
 		select {
@@ -47,11 +57,17 @@ func (b *Buffer) Add(metrics ...telegraf.Metric) {
 		default:
 			b.mu.Lock()
 			MetricsDropped.Incr(1)
-			<-b.buf
+			m := <-b.buf
 			b.buf <- metrics[i]
 			b.mu.Unlock()
+			if b.onDrop != nil {
+				dropped = append(dropped, m)
+			}
 		}
 	}
+	if len(dropped) > 0 {
+		b.onDrop(dropped)
+	}
 }
 
 // Batch returns a batch of metrics of size batchSize.
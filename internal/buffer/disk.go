@@ -0,0 +1,635 @@
+package buffer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+const (
+	// maximum size of a single segment file, smaller limits use smaller
+	// segments so that dropping the oldest segment never loses too much.
+	maxSegmentSize = 8 * 1024 * 1024
+
+	// record header: payload length and crc32 of the payload.
+	recordHeaderSize = 8
+
+	segmentExt = ".seg"
+	ackFile    = "ack"
+	lockFile   = "lock"
+)
+
+var errCorrupt = errors.New("corrupt record")
+
+// DiskBuffer is a write-ahead queue of metrics stored in segment files inside
+// a directory. Metrics returned by Batch stay on disk until the batch is
+// acknowledged with Accept, so metrics that have not been written to the
+// output survive a restart or crash of the agent and are replayed when the
+// buffer is opened again.
+//
+// The metrics are synced to disk before Batch returns them, so the
+// acknowledged position never gets ahead of the metrics on disk, or on every
+// Add with SetSyncOnAdd.
+type DiskBuffer struct {
+	dir         string
+	limit       int64
+	segmentSize int64
+
+	// lock is held as long as the buffer is open
+	lock *os.File
+
+	mu        sync.Mutex
+	segments  []*segment
+	w         *os.File
+	size      int64
+	pending   []readPos
+	syncOnAdd bool
+	// dirty is true when metrics were written to w since it was synced
+	dirty bool
+
+	// called with the metrics of segments dropped to make room for new ones
+	onDrop func([]telegraf.Metric)
+}
+
+// segment is a single file of the queue. The ack offset and count track the
+// records that have already been written to the output.
+type segment struct {
+	id     uint64
+	size   int64
+	count  int
+	ackOff int64
+	acked  int
+}
+
+// readPos is the position reached in one segment by an outstanding batch.
+type readPos struct {
+	id  uint64
+	off int64
+	n   int
+}
+
+// NewDiskBuffer opens or creates a DiskBuffer in dir. Metrics found in the
+// directory that were not acknowledged are kept and will be returned by Batch.
+//   limit is the maximum number of bytes the buffer will keep on disk. If Add
+//   is called when the buffer is full, the oldest segment will be dropped.
+// The directory is locked until the buffer is closed, so that it is never
+// opened twice.
+func NewDiskBuffer(dir string, limit int64) (*DiskBuffer, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid disk buffer limit %d", limit)
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+
+	segmentSize := limit / 4
+	if segmentSize > maxSegmentSize {
+		segmentSize = maxSegmentSize
+	}
+
+	lock, err := lockDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &DiskBuffer{
+		dir:         dir,
+		limit:       limit,
+		segmentSize: segmentSize,
+		lock:        lock,
+	}
+	if err := b.open(); err != nil {
+		lock.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+func lockPath(dir string) string {
+	return filepath.Join(dir, lockFile)
+}
+
+// open loads the existing segments and the acknowledged position.
+func (b *DiskBuffer) open() error {
+	files, err := ioutil.ReadDir(b.dir)
+	if err != nil {
+		return err
+	}
+
+	var ids []uint64
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	ackID, ackOff, err := b.readAck()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if id < ackID {
+			// fully acknowledged before the last shutdown
+			os.Remove(b.segmentPath(id))
+			continue
+		}
+		s, err := b.scanSegment(id)
+		if err != nil {
+			return err
+		}
+		if id == ackID {
+			if ackOff > s.size {
+				// the acknowledged metrics did not all reach the disk
+				// before a power loss
+				log.Printf("W! Disk buffer %s: ack offset %d is past the end "+
+					"of segment %d, clamping it to %d", b.dir, ackOff, id, s.size)
+				ackOff = s.size
+			}
+			if err := b.skipAcked(s, ackOff); err != nil {
+				return err
+			}
+		}
+		b.segments = append(b.segments, s)
+		b.size += s.size
+	}
+
+	var next uint64
+	if len(b.segments) > 0 {
+		next = b.segments[len(b.segments)-1].id + 1
+	} else if ackID > 0 {
+		next = ackID
+	}
+	return b.newSegment(next)
+}
+
+func (b *DiskBuffer) segmentPath(id uint64) string {
+	return filepath.Join(b.dir, fmt.Sprintf("%020d%s", id, segmentExt))
+}
+
+// scanSegment counts the valid records of a segment file, truncating it
+// after the last complete record if the agent crashed in the middle of a
+// write.
+func (b *DiskBuffer) scanSegment(id uint64) (*segment, error) {
+	path := b.segmentPath(id)
+	f, err := os.OpenFile(path, os.O_RDWR, 0640)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s := &segment{id: id}
+	r := bufio.NewReader(f)
+	for {
+		n, _, err := readRecord(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Printf("W! Disk buffer %s: truncating %s at offset %d: %s",
+				b.dir, path, s.size, err)
+			if err := f.Truncate(s.size); err != nil {
+				return nil, err
+			}
+			break
+		}
+		s.size += n
+		s.count++
+	}
+	return s, nil
+}
+
+// skipAcked marks the records before off as acknowledged.
+func (b *DiskBuffer) skipAcked(s *segment, off int64) error {
+	f, err := os.Open(b.segmentPath(s.id))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for s.ackOff < off && s.ackOff < s.size {
+		n, _, err := readRecord(r)
+		if err != nil {
+			return err
+		}
+		s.ackOff += n
+		s.acked++
+	}
+	return nil
+}
+
+func (b *DiskBuffer) readAck() (uint64, int64, error) {
+	buf, err := ioutil.ReadFile(filepath.Join(b.dir, ackFile))
+	if os.IsNotExist(err) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var id uint64
+	var off int64
+	if _, err := fmt.Sscanf(string(buf), "%d %d", &id, &off); err != nil {
+		return 0, 0, fmt.Errorf("invalid disk buffer ack file in %s: %s", b.dir, err)
+	}
+	return id, off, nil
+}
+
+// writeAck atomically records the position of the oldest unacknowledged
+// record.
+func (b *DiskBuffer) writeAck() error {
+	var id uint64
+	var off int64
+	if len(b.segments) > 0 {
+		id, off = b.segments[0].id, b.segments[0].ackOff
+	}
+
+	path := filepath.Join(b.dir, ackFile)
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(f, "%d %d\n", id, off); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// newSegment closes the current segment and starts writing to a new one.
+func (b *DiskBuffer) newSegment(id uint64) error {
+	if b.w != nil {
+		b.w.Sync()
+		b.w.Close()
+		b.dirty = false
+	}
+	f, err := os.OpenFile(b.segmentPath(id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	b.w = f
+	b.segments = append(b.segments, &segment{id: id})
+	return nil
+}
+
+// sync syncs the metrics written to the current segment to disk.
+func (b *DiskBuffer) sync() error {
+	if !b.dirty {
+		return nil
+	}
+	if err := b.w.Sync(); err != nil {
+		return err
+	}
+	b.dirty = false
+	return nil
+}
+
+// IsEmpty returns true if DiskBuffer is empty.
+func (b *DiskBuffer) IsEmpty() bool {
+	return b.Len() == 0
+}
+
+// Len returns the number of metrics that have not been acknowledged.
+func (b *DiskBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for _, s := range b.segments {
+		n += s.count - s.acked
+	}
+	return n
+}
+
+// Add appends metrics to the end of the buffer.
+func (b *DiskBuffer) Add(metrics ...telegraf.Metric) error {
+	b.mu.Lock()
+	dropped, err := b.add(metrics)
+	onDrop := b.onDrop
+	b.mu.Unlock()
+
+	// the drop handler may be slow, it is called without holding the lock
+	if onDrop != nil && len(dropped) > 0 {
+		onDrop(dropped)
+	}
+	return err
+}
+
+// add appends metrics to the end of the buffer and returns the metrics
+// dropped to make room for them. The records are written at once to every
+// segment they go to.
+func (b *DiskBuffer) add(metrics []telegraf.Metric) ([]telegraf.Metric, error) {
+	var buf []byte
+	var count int
+	write := func() error {
+		if len(buf) == 0 {
+			return nil
+		}
+		cur := b.segments[len(b.segments)-1]
+		if _, err := b.w.Write(buf); err != nil {
+			// do not leave a partial record before the next ones
+			b.w.Truncate(cur.size)
+			return err
+		}
+		cur.size += int64(len(buf))
+		cur.count += count
+		b.size += int64(len(buf))
+		b.dirty = true
+		buf, count = buf[:0], 0
+		return nil
+	}
+
+	for _, m := range metrics {
+		MetricsWritten.Incr(1)
+
+		payload := make([]byte, 1, m.Len()+1)
+		payload[0] = byte(m.Type())
+		payload = append(payload, m.Serialize()...)
+
+		var header [recordHeaderSize]byte
+		binary.BigEndian.PutUint32(header[0:4], uint32(len(payload)))
+		binary.BigEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(payload))
+
+		cur := b.segments[len(b.segments)-1]
+		size := cur.size + int64(len(buf))
+		if size > 0 && size+int64(recordHeaderSize+len(payload)) > b.segmentSize {
+			if err := write(); err != nil {
+				return nil, err
+			}
+			if err := b.newSegment(cur.id + 1); err != nil {
+				return nil, err
+			}
+		}
+		buf = append(buf, header[:]...)
+		buf = append(buf, payload...)
+		count++
+	}
+	if err := write(); err != nil {
+		return nil, err
+	}
+	if b.syncOnAdd {
+		if err := b.sync(); err != nil {
+			return nil, err
+		}
+	}
+
+	var dropped []telegraf.Metric
+	for b.size > b.limit && len(b.segments) > 1 {
+		d, err := b.dropOldest()
+		dropped = append(dropped, d...)
+		if err != nil {
+			return dropped, err
+		}
+	}
+	return dropped, nil
+}
+
+// SetSyncOnAdd makes Add sync the metrics to disk before it returns, instead
+// of Batch syncing them before they are written to the output.
+func (b *DiskBuffer) SetSyncOnAdd(sync bool) {
+	b.mu.Lock()
+	b.syncOnAdd = sync
+	b.mu.Unlock()
+}
+
+// SetDropHandler sets a function called with the unacknowledged metrics of
+// the oldest segment when it is dropped because the buffer is full.
+func (b *DiskBuffer) SetDropHandler(f func([]telegraf.Metric)) {
+	b.mu.Lock()
+	b.onDrop = f
+	b.mu.Unlock()
+}
+
+// dropOldest removes the oldest segment to make room for new metrics, and
+// returns its unacknowledged metrics when there is a drop handler.
+func (b *DiskBuffer) dropOldest() ([]telegraf.Metric, error) {
+	s := b.segments[0]
+	MetricsDropped.Incr(int64(s.count - s.acked))
+	var metrics []telegraf.Metric
+	if b.onDrop != nil && s.acked < s.count {
+		var err error
+		metrics, _, err = b.readSegment(s, s.count-s.acked)
+		if err != nil {
+			log.Printf("E! Disk buffer %s: error reading dropped segment %d: %s",
+				b.dir, s.id, err)
+		}
+	}
+	b.segments = b.segments[1:]
+	b.size -= s.size
+	if err := os.Remove(b.segmentPath(s.id)); err != nil {
+		return metrics, err
+	}
+	return metrics, b.writeAck()
+}
+
+// Batch returns up to batchSize of the oldest metrics without removing them
+// from the buffer. The batch must be acknowledged with Accept once it has been
+// written, or returned with Reject. Batch returns nil while a previous batch
+// is outstanding.
+func (b *DiskBuffer) Batch(batchSize int) []telegraf.Metric {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.pending != nil {
+		return nil
+	}
+	// the metrics are on disk before they can be acknowledged
+	if err := b.sync(); err != nil {
+		log.Printf("E! Disk buffer %s: error syncing segment: %s", b.dir, err)
+		return nil
+	}
+
+	var out []telegraf.Metric
+	for _, s := range b.segments {
+		if len(out) >= batchSize {
+			break
+		}
+		if s.acked == s.count {
+			continue
+		}
+		metrics, pos, err := b.readSegment(s, batchSize-len(out))
+		if err != nil {
+			log.Printf("E! Disk buffer %s: error reading segment %d: %s",
+				b.dir, s.id, err)
+		}
+		if pos.n > 0 {
+			out = append(out, metrics...)
+			b.pending = append(b.pending, pos)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	if len(out) == 0 && b.pending != nil {
+		// nothing but unparseable metrics were read, there is nothing to
+		// write so they can be acknowledged right away.
+		if err := b.accept(); err != nil {
+			log.Printf("E! Disk buffer %s: %s", b.dir, err)
+		}
+	}
+	return out
+}
+
+// readSegment reads up to n unacknowledged records from a segment.
+func (b *DiskBuffer) readSegment(s *segment, n int) ([]telegraf.Metric, readPos, error) {
+	pos := readPos{id: s.id, off: s.ackOff}
+
+	f, err := os.Open(b.segmentPath(s.id))
+	if err != nil {
+		return nil, pos, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(s.ackOff, io.SeekStart); err != nil {
+		return nil, pos, err
+	}
+
+	var out []telegraf.Metric
+	r := bufio.NewReader(f)
+	for pos.n < n && s.acked+pos.n < s.count {
+		size, payload, err := readRecord(r)
+		if err != nil {
+			return out, pos, err
+		}
+		pos.off += size
+		pos.n++
+
+		m, err := decodeMetric(payload)
+		if err != nil {
+			// an unparseable metric can never be written, skip it
+			log.Printf("E! Disk buffer %s: dropping metric: %s", b.dir, err)
+			MetricsDropped.Incr(1)
+			continue
+		}
+		out = append(out, m)
+	}
+	return out, pos, nil
+}
+
+// Accept acknowledges the outstanding batch, removing its metrics from the
+// buffer.
+func (b *DiskBuffer) Accept() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.accept()
+}
+
+func (b *DiskBuffer) accept() error {
+	if b.pending == nil {
+		return nil
+	}
+	for _, pos := range b.pending {
+		for _, s := range b.segments {
+			// the segment may have been dropped while the batch was out
+			if s.id == pos.id && pos.off > s.ackOff {
+				s.ackOff = pos.off
+				s.acked += pos.n
+			}
+		}
+	}
+	b.pending = nil
+
+	for len(b.segments) > 1 && b.segments[0].acked == b.segments[0].count {
+		s := b.segments[0]
+		b.segments = b.segments[1:]
+		b.size -= s.size
+		if err := os.Remove(b.segmentPath(s.id)); err != nil {
+			return err
+		}
+	}
+	return b.writeAck()
+}
+
+// Reject returns the outstanding batch to the buffer, it will be returned
+// again by the next call to Batch.
+func (b *DiskBuffer) Reject() {
+	b.mu.Lock()
+	b.pending = nil
+	b.mu.Unlock()
+}
+
+// Close syncs and closes the segment being written, and unlocks the
+// directory.
+func (b *DiskBuffer) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.w == nil {
+		return nil
+	}
+	b.w.Sync()
+	err := b.w.Close()
+	b.w = nil
+	b.dirty = false
+	b.lock.Close()
+	return err
+}
+
+// readRecord reads one record, returning its size on disk and its payload.
+func readRecord(r *bufio.Reader) (int64, []byte, error) {
+	var header [recordHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return 0, nil, errCorrupt
+		}
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	sum := binary.BigEndian.Uint32(header[4:8])
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, errCorrupt
+	}
+	if length == 0 || crc32.ChecksumIEEE(payload) != sum {
+		return 0, nil, errCorrupt
+	}
+	return int64(recordHeaderSize + len(payload)), payload, nil
+}
+
+// decodeMetric parses a record payload, a value type byte followed by the
+// metric in line protocol.
+func decodeMetric(payload []byte) (telegraf.Metric, error) {
+	metrics, err := metric.Parse(payload[1:])
+	if err != nil {
+		return nil, err
+	}
+	if len(metrics) != 1 {
+		return nil, fmt.Errorf("expected 1 metric, got %d", len(metrics))
+	}
+	m := metrics[0]
+
+	mType := telegraf.ValueType(payload[0])
+	if mType == telegraf.Untyped {
+		return m, nil
+	}
+	return metric.New(m.Name(), m.Tags(), m.Fields(), m.Time(), mType)
+}
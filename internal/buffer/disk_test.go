@@ -0,0 +1,226 @@
+package buffer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDiskBuffer(t *testing.T, limit int64) (*DiskBuffer, string) {
+	dir, err := ioutil.TempDir("", "telegraf-buffer")
+	require.NoError(t, err)
+	b, err := NewDiskBuffer(dir, limit)
+	require.NoError(t, err)
+	return b, dir
+}
+
+func TestDiskBufferBasicFuncs(t *testing.T) {
+	b, dir := newTestDiskBuffer(t, 1024*1024)
+	defer os.RemoveAll(dir)
+	defer b.Close()
+
+	assert.True(t, b.IsEmpty())
+	assert.Zero(t, b.Len())
+
+	require.NoError(t, b.Add(metricList...))
+	assert.False(t, b.IsEmpty())
+	assert.Equal(t, 5, b.Len())
+
+	batch := b.Batch(3)
+	require.Len(t, batch, 3)
+	assert.Equal(t, "mymetric1", batch[0].Name())
+	assert.Equal(t, "mymetric3", batch[2].Name())
+
+	// metrics are only removed once the batch is accepted
+	assert.Equal(t, 5, b.Len())
+	assert.Nil(t, b.Batch(3))
+
+	require.NoError(t, b.Accept())
+	assert.Equal(t, 2, b.Len())
+
+	batch = b.Batch(3)
+	require.Len(t, batch, 2)
+	assert.Equal(t, "mymetric4", batch[0].Name())
+}
+
+func TestDiskBufferReject(t *testing.T) {
+	b, dir := newTestDiskBuffer(t, 1024*1024)
+	defer os.RemoveAll(dir)
+	defer b.Close()
+
+	require.NoError(t, b.Add(metricList...))
+
+	batch := b.Batch(2)
+	require.Len(t, batch, 2)
+	b.Reject()
+	assert.Equal(t, 5, b.Len())
+
+	batch = b.Batch(2)
+	require.Len(t, batch, 2)
+	assert.Equal(t, "mymetric1", batch[0].Name())
+}
+
+func TestDiskBufferReplay(t *testing.T) {
+	b, dir := newTestDiskBuffer(t, 1024*1024)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, b.Add(metricList...))
+	require.Len(t, b.Batch(2), 2)
+	require.NoError(t, b.Accept())
+
+	// an unacknowledged batch is replayed after a restart
+	require.Len(t, b.Batch(2), 2)
+	require.NoError(t, b.Close())
+
+	b, err := NewDiskBuffer(dir, 1024*1024)
+	require.NoError(t, err)
+	defer b.Close()
+
+	assert.Equal(t, 3, b.Len())
+	batch := b.Batch(10)
+	require.Len(t, batch, 3)
+	assert.Equal(t, "mymetric3", batch[0].Name())
+	assert.Equal(t, "mymetric5", batch[2].Name())
+}
+
+func TestDiskBufferLocked(t *testing.T) {
+	b, dir := newTestDiskBuffer(t, 1024*1024)
+	defer os.RemoveAll(dir)
+
+	// the directory of an open buffer can not be opened again
+	_, err := NewDiskBuffer(dir, 1024*1024)
+	assert.Error(t, err)
+
+	require.NoError(t, b.Close())
+	b, err = NewDiskBuffer(dir, 1024*1024)
+	require.NoError(t, err)
+	require.NoError(t, b.Close())
+}
+
+func TestDiskBufferTruncatesPartialRecord(t *testing.T) {
+	b, dir := newTestDiskBuffer(t, 1024*1024)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, b.Add(metricList...))
+	require.NoError(t, b.Close())
+
+	// simulate a crash in the middle of writing a record
+	path := b.segmentPath(0)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0640)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 0, 42, 1, 2})
+	require.NoError(t, err)
+	f.Close()
+
+	b, err = NewDiskBuffer(dir, 1024*1024)
+	require.NoError(t, err)
+	defer b.Close()
+
+	assert.Equal(t, 5, b.Len())
+	require.NoError(t, b.Add(testutil.TestMetric(1, "mymetric6")))
+	assert.Len(t, b.Batch(10), 6)
+}
+
+func TestDiskBufferClampsAck(t *testing.T) {
+	b, dir := newTestDiskBuffer(t, 1024*1024)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, b.Add(metricList...))
+	require.Len(t, b.Batch(10), 5)
+	require.NoError(t, b.Accept())
+	require.NoError(t, b.Close())
+
+	// simulate a power loss which lost the end of the segment but not the
+	// ack file
+	path := b.segmentPath(0)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()/2))
+
+	b, err = NewDiskBuffer(dir, 1024*1024)
+	require.NoError(t, err)
+	defer b.Close()
+
+	assert.Equal(t, 0, b.Len())
+	require.NoError(t, b.Add(testutil.TestMetric(1, "mymetric6")))
+	batch := b.Batch(10)
+	require.Len(t, batch, 1)
+	assert.Equal(t, "mymetric6", batch[0].Name())
+}
+
+func TestDiskBufferDropsOldest(t *testing.T) {
+	MetricsDropped.Set(0)
+	b, dir := newTestDiskBuffer(t, 1024)
+	defer os.RemoveAll(dir)
+	defer b.Close()
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, b.Add(testutil.TestMetric(i, "mymetric")))
+	}
+
+	assert.True(t, MetricsDropped.Get() > 0)
+	assert.Equal(t, 100, b.Len()+int(MetricsDropped.Get()))
+
+	var size int64
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	for _, f := range files {
+		if filepath.Ext(f.Name()) == segmentExt {
+			size += f.Size()
+		}
+	}
+	assert.True(t, size <= 1024)
+
+	// the newest metrics are kept
+	batch := b.Batch(1000)
+	require.Len(t, batch, b.Len())
+	assert.Equal(t, int64(99), batch[len(batch)-1].Fields()["value"])
+}
+
+func TestDiskBufferDropHandler(t *testing.T) {
+	b, dir := newTestDiskBuffer(t, 1024)
+	defer os.RemoveAll(dir)
+	defer b.Close()
+
+	// the handler is called without holding the lock of the buffer
+	var dropped []telegraf.Metric
+	b.SetDropHandler(func(metrics []telegraf.Metric) {
+		b.Len()
+		dropped = append(dropped, metrics...)
+	})
+	for i := 0; i < 100; i++ {
+		require.NoError(t, b.Add(testutil.TestMetric(i, "mymetric")))
+	}
+
+	require.NotEmpty(t, dropped)
+	assert.Equal(t, 100, b.Len()+len(dropped))
+	assert.Equal(t, int64(0), dropped[0].Fields()["value"])
+}
+
+func TestDiskBufferKeepsValueType(t *testing.T) {
+	b, dir := newTestDiskBuffer(t, 1024*1024)
+	defer os.RemoveAll(dir)
+	defer b.Close()
+
+	m, err := metric.New("cpu",
+		map[string]string{"host": "localhost"},
+		map[string]interface{}{"value": 42.0},
+		testutil.TestMetric(1).Time(),
+		telegraf.Counter,
+	)
+	require.NoError(t, err)
+	require.NoError(t, b.Add(m))
+
+	batch := b.Batch(1)
+	require.Len(t, batch, 1)
+	assert.Equal(t, telegraf.Counter, batch[0].Type())
+	assert.Equal(t, m.String(), batch[0].String())
+}
@@ -0,0 +1,9 @@
+package buffer
+
+import "os"
+
+// lockDir does not lock the directory, there is no flock on Solaris, the
+// lock file is only created.
+func lockDir(dir string) (*os.File, error) {
+	return os.OpenFile(lockPath(dir), os.O_CREATE|os.O_RDWR, 0640)
+}
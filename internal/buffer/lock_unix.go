@@ -0,0 +1,23 @@
+// +build !windows,!solaris
+
+package buffer
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockDir takes an exclusive lock on a directory, held until the returned
+// file is closed.
+func lockDir(dir string) (*os.File, error) {
+	f, err := os.OpenFile(lockPath(dir), os.O_CREATE|os.O_RDWR, 0640)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("disk buffer %s is used by another output: %s", dir, err)
+	}
+	return f, nil
+}
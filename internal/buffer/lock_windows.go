@@ -0,0 +1,24 @@
+package buffer
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockDir takes an exclusive lock on a directory, held until the returned
+// file is closed. The lock file is opened without sharing.
+func lockDir(dir string) (*os.File, error) {
+	path := lockPath(dir)
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	h, err := syscall.CreateFile(name,
+		syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil,
+		syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		return nil, fmt.Errorf("disk buffer %s is used by another output: %s", dir, err)
+	}
+	return os.NewFile(uintptr(h), path), nil
+}
@@ -2,8 +2,11 @@ package config
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"math"
@@ -65,6 +68,12 @@ func NewConfig() *Config {
 			Interval:      internal.Duration{Duration: 10 * time.Second},
 			RoundInterval: true,
 			FlushInterval: internal.Duration{Duration: 10 * time.Second},
+
+			MetricBufferDiskLimit: 1024 * 1024 * 1024,
+			MetricBufferDiskSync:  "flush",
+
+			InputQueueSize:      1000,
+			InputOverflowPolicy: models.OverflowBlock,
 		},
 
 		Tags:          make(map[string]string),
@@ -119,6 +128,33 @@ type AgentConfig struct {
 	// not be less than 2 times MetricBatchSize.
 	MetricBufferLimit int
 
+	// MetricBufferPath is the directory in which outputs keep their metric
+	// buffer on disk. Each output uses a sub-directory named after its name
+	// and alias, or the digest of its configuration. When empty, metrics are
+	// buffered in memory.
+	MetricBufferPath string
+
+	// MetricBufferDiskLimit is the maximum number of bytes each output will
+	// keep in its disk buffer. When full, the oldest metrics are dropped.
+	MetricBufferDiskLimit int64
+
+	// MetricBufferDiskSync is when the disk buffers are synced to disk:
+	// "flush" before the metrics are written to the output, or "add" as
+	// soon as the metrics are added.
+	MetricBufferDiskSync string
+
+	// StateDirectory is the directory in which the agent keeps the state of
+	// the plugins across restarts. When empty, the state is kept in memory.
+	StateDirectory string
+
+	// InputQueueSize is the number of metrics of each input buffered between
+	// the input and the processors.
+	InputQueueSize int
+
+	// InputOverflowPolicy is what happens to a new metric when the queue of
+	// its input is full: "block" the input, "drop_newest" or "drop_oldest".
+	InputOverflowPolicy string
+
 	// FlushBufferWhenFull tells Telegraf to flush the metric buffer whenever
 	// it fills up, regardless of FlushInterval. Setting this option to true
 	// does _not_ deactivate FlushInterval.
@@ -216,6 +252,32 @@ var header = `# Telegraf Configuration
   ## This buffer only fills when writes fail to output plugin(s).
   metric_buffer_limit = 10000
 
+  ## Directory in which to keep the output buffers on disk. Metrics are
+  ## removed from the disk buffer only after they have been written to the
+  ## output, and unwritten metrics are replayed after a restart. When set,
+  ## metric_buffer_limit is replaced by metric_buffer_disk_limit.
+  # metric_buffer_path = "/var/lib/telegraf/buffer"
+  ## Maximum size in bytes of the disk buffer of each output. Oldest metrics
+  ## are dropped first when this buffer fills.
+  # metric_buffer_disk_limit = 1073741824
+  ## When the disk buffers are synced to disk: "flush", before the metrics are
+  ## written to the outputs, or "add", as soon as every metric is added, which
+  ## loses no metric on a power loss but is much slower.
+  # metric_buffer_disk_sync = "flush"
+
+  ## Directory in which to keep the state of the plugins, like the offsets of
+  ## the files read by the tail input, across restarts. The state is saved
+  ## every flush_interval and when telegraf stops.
+  # state_directory = "/var/lib/telegraf/state"
+
+  ## Every input buffers up to input_queue_size metrics, waiting to be
+  ## processed. When the queue is full, because the processors and outputs
+  ## can not keep up, input_overflow_policy decides what happens to new
+  ## metrics: "block" the input, "drop_newest" or "drop_oldest" metrics.
+  ## Both can be set per input as queue_size and overflow_policy.
+  # input_queue_size = 1000
+  # input_overflow_policy = "block"
+
   ## Collection jitter is used to jitter the collection by a random amount.
   ## Each plugin will sleep for a random time within jitter before collecting.
   ## This can be used to avoid many plugins querying things like sysfs at the
@@ -590,6 +652,15 @@ func (c *Config) LoadConfig(path string) error {
 			log.Printf("E! Could not parse [agent] config\n")
 			return fmt.Errorf("Error parsing %s, %s", path, err)
 		}
+		if err = checkOverflowPolicy(c.Agent.InputOverflowPolicy); err != nil {
+			return fmt.Errorf("Error parsing %s, %s", path, err)
+		}
+		switch c.Agent.MetricBufferDiskSync {
+		case "flush", "add":
+		default:
+			return fmt.Errorf("Error parsing %s, unknown metric_buffer_disk_sync %q",
+				path, c.Agent.MetricBufferDiskSync)
+		}
 	}
 
 	// Parse all the rest of the plugins:
@@ -711,12 +782,77 @@ func parseFile(fpath string) (*ast.Table, error) {
 	return toml.Parse(contents)
 }
 
+// tableDigest returns a digest of the configuration table of a plugin, it
+// must be taken before the plugin specific fields are removed from the table.
+func tableDigest(name string, tbl *ast.Table) string {
+	h := sha256.New()
+	io.WriteString(h, name+"\n")
+	writeTable(h, tbl)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeTable writes the fields of a table sorted by key.
+func writeTable(w io.Writer, tbl *ast.Table) {
+	keys := make([]string, 0, len(tbl.Fields))
+	for key := range tbl.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch v := tbl.Fields[key].(type) {
+		case *ast.KeyValue:
+			fmt.Fprintf(w, "%s=%s\n", key, valueSource(v.Value))
+		case *ast.Table:
+			fmt.Fprintf(w, "[%s]\n", key)
+			writeTable(w, v)
+			fmt.Fprintf(w, "[/%s]\n", key)
+		case []*ast.Table:
+			for _, t := range v {
+				fmt.Fprintf(w, "[[%s]]\n", key)
+				writeTable(w, t)
+				fmt.Fprintf(w, "[[/%s]]\n", key)
+			}
+		}
+	}
+}
+
+// valueSource returns the source of a value, without the formatting of
+// arrays.
+func valueSource(v ast.Value) string {
+	a, ok := v.(*ast.Array)
+	if !ok {
+		return v.Source()
+	}
+	values := make([]string, len(a.Value))
+	for i, e := range a.Value {
+		values[i] = valueSource(e)
+	}
+	return "[" + strings.Join(values, ",") + "]"
+}
+
 func (c *Config) addAggregator(name string, table *ast.Table) error {
 	creator, ok := aggregators.Aggregators[name]
 	if !ok {
 		return fmt.Errorf("Undefined but requested aggregator: %s", name)
 	}
 	aggregator := creator()
+	digest := tableDigest(name, table)
+	alias, err := pluginAlias(name, table)
+	if err != nil {
+		return err
+	}
+	id, err := pluginID(name, alias, digest, func(id string) bool {
+		for _, a := range c.Aggregators {
+			if a.Config.Name == name && a.ID == id {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return err
+	}
 
 	conf, err := buildAggregator(name, table)
 	if err != nil {
@@ -727,7 +863,12 @@ func (c *Config) addAggregator(name string, table *ast.Table) error {
 		return err
 	}
 
-	c.Aggregators = append(c.Aggregators, models.NewRunningAggregator(aggregator, conf))
+	conf.StateNamespace = "aggregators." + id
+
+	ra := models.NewRunningAggregator(aggregator, conf)
+	ra.Digest = digest
+	ra.ID = id
+	c.Aggregators = append(c.Aggregators, ra)
 	return nil
 }
 
@@ -737,6 +878,22 @@ func (c *Config) addProcessor(name string, table *ast.Table) error {
 		return fmt.Errorf("Undefined but requested processor: %s", name)
 	}
 	processor := creator()
+	digest := tableDigest(name, table)
+	alias, err := pluginAlias(name, table)
+	if err != nil {
+		return err
+	}
+	id, err := pluginID(name, alias, digest, func(id string) bool {
+		for _, p := range c.Processors {
+			if p.Name == name && p.ID == id {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return err
+	}
 
 	processorConfig, err := buildProcessor(name, table)
 	if err != nil {
@@ -747,10 +904,14 @@ func (c *Config) addProcessor(name string, table *ast.Table) error {
 		return err
 	}
 
+	processorConfig.StateNamespace = "processors." + id
+
 	rf := &models.RunningProcessor{
 		Name:      name,
 		Processor: processor,
 		Config:    processorConfig,
+		Digest:    digest,
+		ID:        id,
 	}
 
 	c.Processors = append(c.Processors, rf)
@@ -766,6 +927,35 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 		return fmt.Errorf("Undefined but requested output: %s", name)
 	}
 	output := creator()
+	digest := tableDigest(name, table)
+	alias, err := pluginAlias(name, table)
+	if err != nil {
+		return err
+	}
+	id, err := pluginID(name, alias, digest, func(id string) bool {
+		for _, o := range c.Outputs {
+			if o.Name == name && o.ID == id {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return err
+	}
+
+	var deadLetter models.DeadLetter
+	if node, ok := table.Fields["dead_letter"]; ok {
+		subtbl, ok := node.(*ast.Table)
+		if !ok {
+			return fmt.Errorf("%s: invalid configuration, dead_letter must be a table", name)
+		}
+		deadLetter, err = buildDeadLetter(name, subtbl)
+		if err != nil {
+			return fmt.Errorf("Error building dead-letter sink for output %s, %s", name, err)
+		}
+		delete(table.Fields, "dead_letter")
+	}
 
 	// If the output has a SetSerializer function, then this means it can write
 	// arbitrary types of output, so build the serializer and set it.
@@ -782,6 +972,13 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 	if err != nil {
 		return err
 	}
+	if c.Agent.MetricBufferPath != "" {
+		// opened when the output is connected
+		outputConfig.DiskBufferPath = filepath.Join(c.Agent.MetricBufferPath, id)
+		outputConfig.DiskBufferLimit = c.Agent.MetricBufferDiskLimit
+		outputConfig.DiskBufferSyncOnAdd = c.Agent.MetricBufferDiskSync == "add"
+	}
+	outputConfig.StateNamespace = "outputs." + id
 
 	if err := toml.UnmarshalTable(table, output); err != nil {
 		return err
@@ -789,10 +986,142 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 
 	ro := models.NewRunningOutput(name, output, outputConfig,
 		c.Agent.MetricBatchSize, c.Agent.MetricBufferLimit)
+	ro.Digest = digest
+	ro.ID = id
+
+	if deadLetter != nil {
+		ro.SetDeadLetter(deadLetter)
+	}
+
 	c.Outputs = append(c.Outputs, ro)
 	return nil
 }
 
+// buildDeadLetter builds the dead-letter sink of an output from its
+// dead_letter table, writing either to a file or to a secondary output.
+func buildDeadLetter(name string, tbl *ast.Table) (models.DeadLetter, error) {
+	var path, outputName string
+	maxSize := int64(models.DEFAULT_DEAD_LETTER_MAX_SIZE)
+	maxArchives := models.DEFAULT_DEAD_LETTER_MAX_ARCHIVES
+
+	if node, ok := tbl.Fields["path"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				path = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["output"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				outputName = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["rotation_max_size"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				maxSize = v
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["rotation_max_archives"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				maxArchives = int(v)
+			}
+		}
+	}
+
+	switch {
+	case path != "" && outputName != "":
+		return nil, fmt.Errorf("only one of path and output can be set")
+	case path != "":
+		serializer, err := buildSerializer(name, tbl)
+		if err != nil {
+			return nil, err
+		}
+		return models.NewDeadLetterFile(path, serializer, maxSize, maxArchives)
+	case outputName != "":
+		creator, ok := outputs.Outputs[outputName]
+		if !ok {
+			return nil, fmt.Errorf("Undefined but requested output: %s", outputName)
+		}
+		output := creator()
+
+		// the output is configured by the table named after it
+		subtbl := &ast.Table{Fields: map[string]interface{}{}}
+		if node, ok := tbl.Fields[outputName]; ok {
+			if subtbl, ok = node.(*ast.Table); !ok {
+				return nil, fmt.Errorf("invalid configuration of output %s", outputName)
+			}
+		}
+		if t, ok := output.(serializers.SerializerOutput); ok {
+			serializer, err := buildSerializer(outputName, subtbl)
+			if err != nil {
+				return nil, err
+			}
+			t.SetSerializer(serializer)
+		}
+		if err := toml.UnmarshalTable(subtbl, output); err != nil {
+			return nil, err
+		}
+		return models.NewDeadLetterOutput(outputName, output), nil
+	}
+	return nil, fmt.Errorf("one of path and output must be set")
+}
+
+// pluginAlias returns the alias of a plugin, and removes it from the table.
+func pluginAlias(name string, tbl *ast.Table) (string, error) {
+	node, ok := tbl.Fields["alias"]
+	if !ok {
+		return "", nil
+	}
+	delete(tbl.Fields, "alias")
+	if kv, ok := node.(*ast.KeyValue); ok {
+		if str, ok := kv.Value.(*ast.String); ok && str.Value != "" &&
+			!strings.ContainsAny(str.Value, "/\\") {
+			return str.Value, nil
+		}
+	}
+	return "", fmt.Errorf("%s: alias must be a non empty string without slashes", name)
+}
+
+// pluginID returns the identity of a plugin among the plugins of the same
+// name: the name followed by its alias, or by a digest of its configuration
+// table when it has no alias. Unlike the position of the plugin, it does not
+// change when other plugins are added, removed or moved, so the plugin keeps
+// its disk buffer and its state. Identical plugins without an alias are
+// numbered in the order they appear, taken tells whether an identity is used
+// by a plugin already.
+func pluginID(name, alias, digest string, taken func(string) bool) (string, error) {
+	if alias != "" {
+		id := name + "." + alias
+		if taken(id) {
+			return "", fmt.Errorf("%s: alias %s is used more than once", name, alias)
+		}
+		return id, nil
+	}
+
+	base := name + "." + digest[:12]
+	id := base
+	for n := 1; taken(id); n++ {
+		id = fmt.Sprintf("%s.%d", base, n)
+	}
+	return id, nil
+}
+
 func (c *Config) addInput(name string, table *ast.Table) error {
 	if len(c.InputFilters) > 0 && !sliceContains(name, c.InputFilters) {
 		return nil
@@ -807,6 +1136,22 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 		return fmt.Errorf("Undefined but requested input: %s", name)
 	}
 	input := creator()
+	digest := tableDigest(name, table)
+	alias, err := pluginAlias(name, table)
+	if err != nil {
+		return err
+	}
+	id, err := pluginID(name, alias, digest, func(id string) bool {
+		for _, in := range c.Inputs {
+			if in.Config.Name == name && in.ID == id {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return err
+	}
 
 	// If the input has a SetParser function, then this means it can accept
 	// arbitrary types of input, so build the parser and set it.
@@ -828,7 +1173,11 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 		return err
 	}
 
+	pluginConfig.StateNamespace = "inputs." + id
+
 	rp := models.NewRunningInput(input, pluginConfig)
+	rp.Digest = digest
+	rp.ID = id
 	c.Inputs = append(c.Inputs, rp)
 	return nil
 }
@@ -1159,12 +1508,42 @@ func buildInput(name string, tbl *ast.Table) (*models.InputConfig, error) {
 		}
 	}
 
+	if node, ok := tbl.Fields["queue_size"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				cp.QueueSize = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["overflow_policy"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				cp.OverflowPolicy = str.Value
+			}
+		}
+	}
+	if err := checkOverflowPolicy(cp.OverflowPolicy); err != nil {
+		return nil, fmt.Errorf("input %s: %s", name, err)
+	}
+
+	var err error
+	cp.Cardinality, err = buildCardinality(tbl)
+	if err != nil {
+		return nil, fmt.Errorf("input %s: %s", name, err)
+	}
+
 	delete(tbl.Fields, "name_prefix")
 	delete(tbl.Fields, "name_suffix")
 	delete(tbl.Fields, "name_override")
 	delete(tbl.Fields, "interval")
 	delete(tbl.Fields, "tags")
-	var err error
+	delete(tbl.Fields, "queue_size")
+	delete(tbl.Fields, "overflow_policy")
 	cp.Filter, err = buildFilter(tbl)
 	if err != nil {
 		return cp, err
@@ -1172,6 +1551,77 @@ func buildInput(name string, tbl *ast.Table) (*models.InputConfig, error) {
 	return cp, nil
 }
 
+// checkOverflowPolicy returns an error for an unknown overflow policy, an
+// empty policy uses the agent default.
+func checkOverflowPolicy(policy string) error {
+	switch policy {
+	case "", models.OverflowBlock, models.OverflowDropNewest, models.OverflowDropOldest:
+		return nil
+	default:
+		return fmt.Errorf("unknown overflow_policy %q", policy)
+	}
+}
+
+// buildCardinality builds the cardinality limit of an input or an output.
+func buildCardinality(tbl *ast.Table) (models.CardinalityConfig, error) {
+	var cc models.CardinalityConfig
+	if node, ok := tbl.Fields["cardinality_limit"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return cc, err
+				}
+				cc.Limit = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["cardinality_window"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return cc, err
+				}
+
+				cc.Window = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["cardinality_policy"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				cc.Policy = str.Value
+			}
+		}
+	}
+	switch cc.Policy {
+	case "", models.CardinalityDrop, models.CardinalityStripTags, models.CardinalityLog:
+	default:
+		return cc, fmt.Errorf("unknown cardinality_policy %q", cc.Policy)
+	}
+
+	if node, ok := tbl.Fields["cardinality_strip_tags"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						cc.StripTags = append(cc.StripTags, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	delete(tbl.Fields, "cardinality_limit")
+	delete(tbl.Fields, "cardinality_window")
+	delete(tbl.Fields, "cardinality_policy")
+	delete(tbl.Fields, "cardinality_strip_tags")
+	return cc, nil
+}
+
 // buildParser grabs the necessary entries from the ast.Table for creating
 // a parsers.Parser object, and creates it, which can then be added onto
 // an Input object.
@@ -1225,6 +1675,62 @@ func buildParser(name string, tbl *ast.Table) (parsers.Parser, error) {
 		}
 	}
 
+	if node, ok := tbl.Fields["json_query"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONQuery = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_name_key"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONNameKey = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_time_key"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONTimeKey = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_time_format"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONTimeFormat = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_field_keys"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.JSONFieldKeys = append(c.JSONFieldKeys, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_string_fields"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.JSONStringFields = append(c.JSONStringFields, str.Value)
+					}
+				}
+			}
+		}
+	}
+
 	if node, ok := tbl.Fields["data_type"]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
 			if str, ok := kv.Value.(*ast.String); ok {
@@ -1261,12 +1767,163 @@ func buildParser(name string, tbl *ast.Table) (parsers.Parser, error) {
 		}
 	}
 
+	if node, ok := tbl.Fields["csv_header_row_count"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				c.CSVHeaderRowCount = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_skip_rows"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				c.CSVSkipRows = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_skip_columns"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				c.CSVSkipColumns = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_delimiter"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.CSVDelimiter = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_comment"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.CSVComment = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_trim_space"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.CSVTrimSpace, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Error parsing boolean value for %s: %s", name, err)
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_column_names"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.CSVColumnNames = append(c.CSVColumnNames, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_column_types"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.CSVColumnTypes = append(c.CSVColumnTypes, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_tag_columns"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.CSVTagColumns = append(c.CSVTagColumns, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_measurement_column"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.CSVMeasurementColumn = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_timestamp_column"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.CSVTimestampColumn = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_timestamp_format"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.CSVTimestampFormat = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["nmon_timezone"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.NmonTimezone = str.Value
+			}
+		}
+	}
+
 	c.MetricName = name
 
 	delete(tbl.Fields, "data_format")
 	delete(tbl.Fields, "separator")
 	delete(tbl.Fields, "templates")
 	delete(tbl.Fields, "tag_keys")
+	delete(tbl.Fields, "json_query")
+	delete(tbl.Fields, "json_name_key")
+	delete(tbl.Fields, "json_time_key")
+	delete(tbl.Fields, "json_time_format")
+	delete(tbl.Fields, "json_field_keys")
+	delete(tbl.Fields, "json_string_fields")
+	delete(tbl.Fields, "csv_header_row_count")
+	delete(tbl.Fields, "csv_skip_rows")
+	delete(tbl.Fields, "csv_skip_columns")
+	delete(tbl.Fields, "csv_delimiter")
+	delete(tbl.Fields, "csv_comment")
+	delete(tbl.Fields, "csv_trim_space")
+	delete(tbl.Fields, "csv_column_names")
+	delete(tbl.Fields, "csv_column_types")
+	delete(tbl.Fields, "csv_tag_columns")
+	delete(tbl.Fields, "csv_measurement_column")
+	delete(tbl.Fields, "csv_timestamp_column")
+	delete(tbl.Fields, "csv_timestamp_format")
+	delete(tbl.Fields, "nmon_timezone")
 	delete(tbl.Fields, "data_type")
 	delete(tbl.Fields, "collectd_auth_file")
 	delete(tbl.Fields, "collectd_security_level")
@@ -1325,10 +1982,58 @@ func buildSerializer(name string, tbl *ast.Table) (serializers.Serializer, error
 		}
 	}
 
+	if node, ok := tbl.Fields["prometheus_export_timestamp"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.PrometheusExportTimestamp, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Error parsing boolean value for %s: %s", name, err)
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["carbon2_meta_tags"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.Carbon2MetaTags = append(c.Carbon2MetaTags, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["splunkmetric_hec_routing"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.SplunkMetricHecRouting, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Error parsing boolean value for %s: %s", name, err)
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["line_template"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.LineTemplate = str.Value
+			}
+		}
+	}
+
 	delete(tbl.Fields, "data_format")
 	delete(tbl.Fields, "prefix")
 	delete(tbl.Fields, "template")
 	delete(tbl.Fields, "json_timestamp_units")
+	delete(tbl.Fields, "prometheus_export_timestamp")
+	delete(tbl.Fields, "carbon2_meta_tags")
+	delete(tbl.Fields, "splunkmetric_hec_routing")
+	delete(tbl.Fields, "line_template")
 	return serializers.NewSerializer(c)
 }
 
@@ -1352,5 +2057,93 @@ func buildOutput(name string, tbl *ast.Table) (*models.OutputConfig, error) {
 	if len(oc.Filter.FieldPass) > 0 {
 		oc.Filter.NamePass = oc.Filter.FieldPass
 	}
+
+	if node, ok := tbl.Fields["retry_initial_backoff"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, err
+				}
+
+				oc.Retry.InitialBackoff = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["retry_max_backoff"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, err
+				}
+
+				oc.Retry.MaxBackoff = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["retry_jitter"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, err
+				}
+
+				oc.Retry.Jitter = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["retry_max_attempts"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.MaxAttempts = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["circuit_breaker_timeout"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, err
+				}
+
+				oc.Retry.CircuitBreakerTimeout = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["background_connect"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				oc.BackgroundConnect, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	delete(tbl.Fields, "retry_initial_backoff")
+	delete(tbl.Fields, "retry_max_backoff")
+	delete(tbl.Fields, "retry_jitter")
+	delete(tbl.Fields, "retry_max_attempts")
+	delete(tbl.Fields, "circuit_breaker_timeout")
+	delete(tbl.Fields, "background_connect")
+
+	oc.Cardinality, err = buildCardinality(tbl)
+	if err != nil {
+		return nil, fmt.Errorf("output %s: %s", name, err)
+	}
 	return oc, nil
 }
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -10,8 +12,10 @@ import (
 	"github.com/influxdata/telegraf/plugins/inputs/exec"
 	"github.com/influxdata/telegraf/plugins/inputs/memcached"
 	"github.com/influxdata/telegraf/plugins/inputs/procstat"
+	_ "github.com/influxdata/telegraf/plugins/outputs/file"
 	"github.com/influxdata/telegraf/plugins/parsers"
 
+	"github.com/influxdata/toml"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,9 +50,10 @@ func TestConfig_LoadSingleInputWithEnvVars(t *testing.T) {
 	}
 	assert.NoError(t, filter.Compile())
 	mConfig := &models.InputConfig{
-		Name:     "memcached",
-		Filter:   filter,
-		Interval: 10 * time.Second,
+		Name:           "memcached",
+		Filter:         filter,
+		Interval:       10 * time.Second,
+		StateNamespace: "inputs." + c.Inputs[0].ID,
 	}
 	mConfig.Tags = make(map[string]string)
 
@@ -85,9 +90,10 @@ func TestConfig_LoadSingleInput(t *testing.T) {
 	}
 	assert.NoError(t, filter.Compile())
 	mConfig := &models.InputConfig{
-		Name:     "memcached",
-		Filter:   filter,
-		Interval: 5 * time.Second,
+		Name:           "memcached",
+		Filter:         filter,
+		Interval:       5 * time.Second,
+		StateNamespace: "inputs." + c.Inputs[0].ID,
 	}
 	mConfig.Tags = make(map[string]string)
 
@@ -131,9 +137,10 @@ func TestConfig_LoadDirectory(t *testing.T) {
 	}
 	assert.NoError(t, filter.Compile())
 	mConfig := &models.InputConfig{
-		Name:     "memcached",
-		Filter:   filter,
-		Interval: 5 * time.Second,
+		Name:           "memcached",
+		Filter:         filter,
+		Interval:       5 * time.Second,
+		StateNamespace: "inputs." + c.Inputs[0].ID,
 	}
 	mConfig.Tags = make(map[string]string)
 
@@ -150,6 +157,7 @@ func TestConfig_LoadDirectory(t *testing.T) {
 	eConfig := &models.InputConfig{
 		Name:              "exec",
 		MeasurementSuffix: "_myothercollector",
+		StateNamespace:    "inputs." + c.Inputs[1].ID,
 	}
 	eConfig.Tags = make(map[string]string)
 	assert.Equal(t, ex, c.Inputs[1].Input,
@@ -158,6 +166,10 @@ func TestConfig_LoadDirectory(t *testing.T) {
 		"Merged Testdata did not produce correct exec metadata.")
 
 	memcached.Servers = []string{"192.168.1.1"}
+	// the state namespace of an input is named after its configuration
+	assert.Regexp(t, `^memcached\.[0-9a-f]{12}$`, c.Inputs[2].ID)
+	assert.NotEqual(t, c.Inputs[0].ID, c.Inputs[2].ID)
+	mConfig.StateNamespace = "inputs." + c.Inputs[2].ID
 	assert.Equal(t, memcached, c.Inputs[2].Input,
 		"Testdata did not produce a correct memcached struct.")
 	assert.Equal(t, mConfig, c.Inputs[2].Config,
@@ -166,7 +178,10 @@ func TestConfig_LoadDirectory(t *testing.T) {
 	pstat := inputs.Inputs["procstat"]().(*procstat.Procstat)
 	pstat.PidFile = "/var/run/grafana-server.pid"
 
-	pConfig := &models.InputConfig{Name: "procstat"}
+	pConfig := &models.InputConfig{
+		Name:           "procstat",
+		StateNamespace: "inputs." + c.Inputs[3].ID,
+	}
 	pConfig.Tags = make(map[string]string)
 
 	assert.Equal(t, pstat, c.Inputs[3].Input,
@@ -174,3 +189,126 @@ func TestConfig_LoadDirectory(t *testing.T) {
 	assert.Equal(t, pConfig, c.Inputs[3].Config,
 		"Merged Testdata did not produce correct procstat metadata.")
 }
+
+func TestConfig_TableDigest(t *testing.T) {
+	digest := func(conf string) string {
+		tbl, err := toml.Parse([]byte(conf))
+		assert.NoError(t, err)
+		return tableDigest("memcached", tbl)
+	}
+
+	d := digest(`
+servers = ["localhost"]
+interval = "5s"
+[tagpass]
+  goodtag = ["mytag"]
+`)
+	// ordering of the fields and comments do not matter
+	assert.Equal(t, d, digest(`
+# memcached on localhost
+interval = "5s"
+servers = [ "localhost" ]
+[tagpass]
+  goodtag = ["mytag"]
+`))
+	assert.NotEqual(t, d, digest(`
+servers = ["localhost"]
+interval = "10s"
+[tagpass]
+  goodtag = ["mytag"]
+`))
+	assert.NotEqual(t, d, digest(`
+servers = ["localhost"]
+interval = "5s"
+[tagpass]
+  goodtag = ["othertag"]
+`))
+
+	c := NewConfig()
+	assert.NoError(t, c.LoadConfig("./testdata/single_plugin.toml"))
+	c2 := NewConfig()
+	assert.NoError(t, c2.LoadConfig("./testdata/single_plugin.toml"))
+	assert.NotEmpty(t, c.Inputs[0].Digest)
+	assert.Equal(t, c.Inputs[0].Digest, c2.Inputs[0].Digest)
+}
+
+// loadConfig loads a configuration given as text.
+func loadConfig(t *testing.T, conf string) (*Config, error) {
+	f, err := ioutil.TempFile("", "telegraf")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(conf)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	c := NewConfig()
+	return c, c.LoadConfig(f.Name())
+}
+
+func TestConfig_OutputBufferPath(t *testing.T) {
+	c, err := loadConfig(t, `
+[agent]
+  metric_buffer_path = "/buffer"
+[[outputs.file]]
+  files = ["b"]
+`)
+	assert.NoError(t, err)
+	b := c.Outputs[0].Config.DiskBufferPath
+	assert.Equal(t, "/buffer", filepath.Dir(b))
+	assert.Regexp(t, `^file\.[0-9a-f]{12}$`, filepath.Base(b))
+
+	// the buffer of an output does not depend on its position
+	c, err = loadConfig(t, `
+[agent]
+  metric_buffer_path = "/buffer"
+[[outputs.file]]
+  files = ["a"]
+[[outputs.file]]
+  files = ["b"]
+[[outputs.file]]
+  files = ["b"]
+[[outputs.file]]
+  alias = "c"
+  files = ["c"]
+`)
+	assert.NoError(t, err)
+	assert.NotEqual(t, b, c.Outputs[0].Config.DiskBufferPath)
+	assert.Equal(t, b, c.Outputs[1].Config.DiskBufferPath)
+	assert.Equal(t, b+".1", c.Outputs[2].Config.DiskBufferPath)
+	assert.Equal(t, filepath.Join("/buffer", "file.c"), c.Outputs[3].Config.DiskBufferPath)
+
+	_, err = loadConfig(t, `
+[[outputs.file]]
+  alias = "c"
+  files = ["a"]
+[[outputs.file]]
+  alias = "c"
+  files = ["b"]
+`)
+	assert.Error(t, err)
+}
+
+func TestConfig_StateNamespace(t *testing.T) {
+	c, err := loadConfig(t, `
+[[inputs.memcached]]
+  servers = ["b"]
+`)
+	assert.NoError(t, err)
+	ns := c.Inputs[0].Config.StateNamespace
+	assert.Regexp(t, `^inputs\.memcached\.[0-9a-f]{12}$`, ns)
+
+	// the namespace of an input does not depend on its position
+	c, err = loadConfig(t, `
+[[inputs.memcached]]
+  servers = ["a"]
+[[inputs.memcached]]
+  servers = ["b"]
+[[inputs.memcached]]
+  alias = "c"
+  servers = ["c"]
+`)
+	assert.NoError(t, err)
+	assert.NotEqual(t, ns, c.Inputs[0].Config.StateNamespace)
+	assert.Equal(t, ns, c.Inputs[1].Config.StateNamespace)
+	assert.Equal(t, "inputs.memcached.c", c.Inputs[2].Config.StateNamespace)
+}
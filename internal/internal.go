@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math"
 	"math/big"
 	"os"
 	"os/exec"
@@ -240,3 +241,54 @@ func RandomSleep(max time.Duration, shutdown chan struct{}) {
 		return
 	}
 }
+
+// ParseTimestamp parses a timestamp according to format, which is one of
+// "unix", "unix_ms", "unix_us", "unix_ns" for a number of seconds,
+// milliseconds, microseconds or nanoseconds since the epoch, given as a
+// number or a string, or a Go time layout for a string.
+func ParseTimestamp(v interface{}, format string) (time.Time, error) {
+	var unit time.Duration
+	switch format {
+	case "unix":
+		unit = time.Second
+	case "unix_ms":
+		unit = time.Millisecond
+	case "unix_us":
+		unit = time.Microsecond
+	case "unix_ns":
+		unit = time.Nanosecond
+	default:
+		s, ok := v.(string)
+		if !ok {
+			return time.Time{}, fmt.Errorf("timestamp %v is not a string", v)
+		}
+		t, err := time.Parse(format, s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unable to parse timestamp, %s", err)
+		}
+		return t.UTC(), nil
+	}
+
+	var ts float64
+	switch t := v.(type) {
+	case int64:
+		return time.Unix(0, t*int64(unit)).UTC(), nil
+	case float64:
+		ts = t
+	case string:
+		// integers are parsed as such to keep the precision of nanoseconds
+		if i, err := strconv.ParseInt(t, 10, 64); err == nil {
+			return time.Unix(0, i*int64(unit)).UTC(), nil
+		}
+		f, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unable to parse timestamp, %s", err)
+		}
+		ts = f
+	default:
+		return time.Time{}, fmt.Errorf("timestamp %v is not a number", v)
+	}
+	whole, frac := math.Modf(ts)
+	ns := int64(whole)*int64(unit) + int64(math.Floor(frac*float64(unit)+0.5))
+	return time.Unix(0, ns).UTC(), nil
+}
@@ -0,0 +1,169 @@
+package models
+
+import (
+	"bytes"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// Policies of the cardinality guard, deciding what happens to a metric of a
+// new series once a plugin reached its limit of series.
+const (
+	// CardinalityDrop drops the metric.
+	CardinalityDrop = "drop"
+	// CardinalityStripTags removes the configured tags from the metric, so
+	// it is folded in a series with less tags.
+	CardinalityStripTags = "strip_tags"
+	// CardinalityLog logs the series and lets the metric through.
+	CardinalityLog = "log"
+)
+
+// DEFAULT_CARDINALITY_WINDOW is the default time after which a series
+// without new metrics no longer counts against the limit.
+const DEFAULT_CARDINALITY_WINDOW = time.Hour
+
+// CardinalityConfig limits the number of series of a plugin.
+type CardinalityConfig struct {
+	// Limit is the number of series seen within Window, zero is no limit.
+	Limit  int
+	Window time.Duration
+	// Policy applies to the metrics of the series over the limit, drop by
+	// default.
+	Policy string
+	// StripTags are the tags removed by the strip_tags policy, all the tags
+	// when empty.
+	StripTags []string
+}
+
+// cardinalityGuard tracks the series of a plugin, by their HashID, and
+// applies the policy to the metrics of new series over the limit.
+type cardinalityGuard struct {
+	CardinalityConfig
+	plugin string
+
+	mu        sync.Mutex
+	series    map[uint64]time.Time // last time a series was seen
+	lastSweep time.Time
+	lastWarn  time.Time
+	now       func() time.Time
+
+	Cardinality selfstat.Stat
+	OverLimit   selfstat.Stat
+}
+
+// newCardinalityGuard returns nil when the config has no limit. The stats
+// are registered in the given measurement, ie gather or write.
+func newCardinalityGuard(
+	plugin string,
+	conf CardinalityConfig,
+	measurement string,
+	tags map[string]string,
+) *cardinalityGuard {
+	if conf.Limit <= 0 {
+		return nil
+	}
+	if conf.Window <= 0 {
+		conf.Window = DEFAULT_CARDINALITY_WINDOW
+	}
+	if conf.Policy == "" {
+		conf.Policy = CardinalityDrop
+	}
+	return &cardinalityGuard{
+		CardinalityConfig: conf,
+		plugin:            plugin,
+		series:            make(map[uint64]time.Time),
+		lastSweep:         time.Now(),
+		now:               time.Now,
+		Cardinality:       selfstat.Register(measurement, "cardinality", tags),
+		OverLimit: selfstat.Register(measurement,
+			"metrics_over_cardinality_limit", tags),
+	}
+}
+
+// apply returns the metric to pass on, nil when it is dropped.
+func (g *cardinalityGuard) apply(m telegraf.Metric) telegraf.Metric {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	if now.Sub(g.lastSweep) >= g.Window {
+		g.sweep(now)
+	}
+
+	id := m.HashID()
+	if _, ok := g.series[id]; ok || len(g.series) < g.Limit {
+		g.series[id] = now
+		g.Cardinality.Set(int64(len(g.series)))
+		return m
+	}
+
+	g.OverLimit.Incr(1)
+	if now.Sub(g.lastWarn) >= g.Window {
+		g.lastWarn = now
+		log.Printf("W! [%s] reached its limit of %d series, applying the "+
+			"%s policy to new series like %s", g.plugin, g.Limit, g.Policy,
+			seriesString(m))
+	}
+
+	switch g.Policy {
+	case CardinalityLog:
+		return m
+	case CardinalityStripTags:
+		stripped := g.strip(m)
+		// the stripped series are let through over the limit, they replace
+		// the series of many metrics
+		g.series[stripped.HashID()] = now
+		g.Cardinality.Set(int64(len(g.series)))
+		return stripped
+	default:
+		return nil
+	}
+}
+
+// sweep forgets the series not seen within the window.
+func (g *cardinalityGuard) sweep(now time.Time) {
+	for id, seen := range g.series {
+		if now.Sub(seen) >= g.Window {
+			delete(g.series, id)
+		}
+	}
+	g.lastSweep = now
+	g.Cardinality.Set(int64(len(g.series)))
+}
+
+func (g *cardinalityGuard) strip(m telegraf.Metric) telegraf.Metric {
+	tags := m.Tags()
+	if len(g.StripTags) == 0 {
+		tags = nil
+	} else {
+		for _, k := range g.StripTags {
+			delete(tags, k)
+		}
+	}
+	// error is not possible if creating from another metric, so ignore.
+	stripped, _ := metric.New(m.Name(), tags, m.Fields(), m.Time(), m.Type())
+	return stripped
+}
+
+// seriesString returns the name and tags of the series of a metric.
+func seriesString(m telegraf.Metric) string {
+	tags := m.Tags()
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteString(m.Name())
+	for _, k := range keys {
+		buf.WriteString("," + k + "=" + tags[k])
+	}
+	return buf.String()
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+func newSeriesMetric(t *testing.T, container string) telegraf.Metric {
+	m, err := metric.New("docker",
+		map[string]string{"host": "h1", "container": container},
+		map[string]interface{}{"value": 1.0},
+		time.Now(),
+	)
+	require.NoError(t, err)
+	return m
+}
+
+func TestCardinalityGuardDisabled(t *testing.T) {
+	assert.Nil(t, newCardinalityGuard("inputs.test", CardinalityConfig{}, "gather", nil))
+}
+
+func TestCardinalityDrop(t *testing.T) {
+	g := newCardinalityGuard("inputs.test", CardinalityConfig{Limit: 2},
+		"gather", map[string]string{"input": t.Name()})
+
+	assert.NotNil(t, g.apply(newSeriesMetric(t, "a")))
+	assert.NotNil(t, g.apply(newSeriesMetric(t, "b")))
+	assert.Nil(t, g.apply(newSeriesMetric(t, "c")))
+	// the known series are still let through
+	assert.NotNil(t, g.apply(newSeriesMetric(t, "a")))
+
+	assert.Equal(t, int64(2), g.Cardinality.Get())
+	assert.Equal(t, int64(1), g.OverLimit.Get())
+}
+
+func TestCardinalityStripTags(t *testing.T) {
+	g := newCardinalityGuard("inputs.test", CardinalityConfig{
+		Limit:     1,
+		Policy:    CardinalityStripTags,
+		StripTags: []string{"container"},
+	}, "gather", map[string]string{"input": t.Name()})
+
+	assert.NotNil(t, g.apply(newSeriesMetric(t, "a")))
+	m := g.apply(newSeriesMetric(t, "b"))
+	require.NotNil(t, m)
+	assert.Equal(t, map[string]string{"host": "h1"}, m.Tags())
+	assert.Equal(t, map[string]interface{}{"value": 1.0}, m.Fields())
+
+	m = g.apply(newSeriesMetric(t, "c"))
+	require.NotNil(t, m)
+	assert.Equal(t, map[string]string{"host": "h1"}, m.Tags())
+	assert.Equal(t, int64(2), g.Cardinality.Get())
+}
+
+func TestCardinalityLog(t *testing.T) {
+	g := newCardinalityGuard("inputs.test", CardinalityConfig{
+		Limit:  1,
+		Policy: CardinalityLog,
+	}, "gather", map[string]string{"input": t.Name()})
+
+	assert.NotNil(t, g.apply(newSeriesMetric(t, "a")))
+	assert.NotNil(t, g.apply(newSeriesMetric(t, "b")))
+	assert.Equal(t, int64(1), g.Cardinality.Get())
+	assert.Equal(t, int64(1), g.OverLimit.Get())
+}
+
+// series not seen within the window no longer count against the limit
+func TestCardinalityWindow(t *testing.T) {
+	g := newCardinalityGuard("inputs.test", CardinalityConfig{
+		Limit:  1,
+		Window: time.Minute,
+	}, "gather", map[string]string{"input": t.Name()})
+	now := time.Now()
+	g.now = func() time.Time { return now }
+
+	assert.NotNil(t, g.apply(newSeriesMetric(t, "a")))
+	assert.Nil(t, g.apply(newSeriesMetric(t, "b")))
+
+	now = now.Add(2 * time.Minute)
+	assert.NotNil(t, g.apply(newSeriesMetric(t, "b")))
+	assert.Nil(t, g.apply(newSeriesMetric(t, "a")))
+}
+
+func TestRunningInputCardinality(t *testing.T) {
+	ri := NewRunningInput(&testInput{}, &InputConfig{
+		Name:        "TestRunningInput",
+		Cardinality: CardinalityConfig{Limit: 1},
+	})
+
+	m := ri.MakeMetric("RITest", map[string]interface{}{"value": 1},
+		map[string]string{"id": "1"}, telegraf.Untyped, time.Now())
+	assert.NotNil(t, m)
+	m = ri.MakeMetric("RITest", map[string]interface{}{"value": 1},
+		map[string]string{"id": "2"}, telegraf.Untyped, time.Now())
+	assert.Nil(t, m)
+}
+
+func TestRunningOutputCardinality(t *testing.T) {
+	conf := &OutputConfig{
+		Filter:      Filter{},
+		Cardinality: CardinalityConfig{Limit: 1},
+	}
+	m := &mockOutput{}
+	ro := NewRunningOutput("test", m, conf, 1000, 10000)
+
+	ro.AddMetric(newSeriesMetric(t, "a"))
+	ro.AddMetric(newSeriesMetric(t, "b"))
+	ro.AddMetric(newSeriesMetric(t, "a"))
+	require.NoError(t, ro.Write())
+	assert.Len(t, m.Metrics(), 2)
+}
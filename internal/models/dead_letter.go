@@ -0,0 +1,216 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+const (
+	// Default size at which a dead-letter file is rotated.
+	DEFAULT_DEAD_LETTER_MAX_SIZE = 10 * 1024 * 1024
+
+	// Default number of rotated dead-letter files kept.
+	DEFAULT_DEAD_LETTER_MAX_ARCHIVES = 5
+)
+
+// DeadLetter receives the metrics an output can not deliver, either because
+// they were evicted from a full buffer or permanently rejected by the output.
+type DeadLetter interface {
+	// Add stores the metrics.
+	Add(metrics []telegraf.Metric) error
+	// Close releases the resources of the dead-letter sink.
+	Close() error
+}
+
+// DeadLetterFile writes serialized metrics to a local file, opened on first
+// use. When the file grows beyond maxSize it is renamed to path.1, the
+// previous path.1 to path.2, and so on, keeping at most maxArchives rotated
+// files.
+type DeadLetterFile struct {
+	path        string
+	serializer  serializers.Serializer
+	maxSize     int64
+	maxArchives int
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	closed bool
+}
+
+// NewDeadLetterFile returns a DeadLetterFile writing to path, creating its
+// directory.
+func NewDeadLetterFile(
+	path string,
+	serializer serializers.Serializer,
+	maxSize int64,
+	maxArchives int,
+) (*DeadLetterFile, error) {
+	if maxSize <= 0 {
+		maxSize = DEFAULT_DEAD_LETTER_MAX_SIZE
+	}
+	if maxArchives < 0 {
+		maxArchives = DEFAULT_DEAD_LETTER_MAX_ARCHIVES
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, err
+	}
+
+	return &DeadLetterFile{
+		path:        path,
+		serializer:  serializer,
+		maxSize:     maxSize,
+		maxArchives: maxArchives,
+	}, nil
+}
+
+func (d *DeadLetterFile) open() error {
+	f, err := os.OpenFile(d.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	d.f = f
+	d.size = fi.Size()
+	return nil
+}
+
+// Add serializes the metrics to the file, rotating it when it is full.
+// Metrics which can not be serialized are skipped.
+func (d *DeadLetterFile) Add(metrics []telegraf.Metric) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return fmt.Errorf("dead-letter file %s is closed", d.path)
+	}
+	if d.f == nil {
+		if err := d.open(); err != nil {
+			return err
+		}
+	}
+
+	var serr error
+	for _, m := range metrics {
+		b, err := d.serializer.Serialize(m)
+		if err != nil {
+			serr = err
+			continue
+		}
+		if d.size > 0 && d.size+int64(len(b)) > d.maxSize {
+			if err := d.rotate(); err != nil {
+				return err
+			}
+		}
+		n, err := d.f.Write(b)
+		d.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return serr
+}
+
+// rotate shifts the rotated files and starts a new file.
+func (d *DeadLetterFile) rotate() error {
+	if err := d.f.Close(); err != nil {
+		return err
+	}
+	d.f = nil
+
+	if d.maxArchives == 0 {
+		if err := os.Remove(d.path); err != nil {
+			return err
+		}
+		return d.open()
+	}
+
+	os.Remove(d.archivePath(d.maxArchives))
+	for i := d.maxArchives - 1; i >= 1; i-- {
+		if err := os.Rename(d.archivePath(i), d.archivePath(i+1)); err != nil &&
+			!os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(d.path, d.archivePath(1)); err != nil {
+		return err
+	}
+	return d.open()
+}
+
+func (d *DeadLetterFile) archivePath(i int) string {
+	return fmt.Sprintf("%s.%d", d.path, i)
+}
+
+// Close syncs and closes the file.
+func (d *DeadLetterFile) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	if d.f == nil {
+		return nil
+	}
+	d.f.Sync()
+	err := d.f.Close()
+	d.f = nil
+	return err
+}
+
+// DeadLetterOutput writes the metrics to a secondary output, which is
+// started, when it is a service output, and connected on first use.
+type DeadLetterOutput struct {
+	Name   string
+	Output telegraf.Output
+
+	mu        sync.Mutex
+	started   bool
+	connected bool
+}
+
+// NewDeadLetterOutput returns a DeadLetterOutput writing to output.
+func NewDeadLetterOutput(name string, output telegraf.Output) *DeadLetterOutput {
+	return &DeadLetterOutput{Name: name, Output: output}
+}
+
+// Add writes the metrics to the secondary output.
+func (d *DeadLetterOutput) Add(metrics []telegraf.Metric) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if so, ok := d.Output.(telegraf.ServiceOutput); ok && !d.started {
+		if err := so.Start(); err != nil {
+			return fmt.Errorf("starting dead-letter output %s: %s", d.Name, err)
+		}
+		d.started = true
+	}
+	if !d.connected {
+		if err := d.Output.Connect(); err != nil {
+			return fmt.Errorf("connecting dead-letter output %s: %s", d.Name, err)
+		}
+		d.connected = true
+	}
+	return d.Output.Write(metrics)
+}
+
+// Close closes the secondary output, and stops it when it was started.
+func (d *DeadLetterOutput) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var err error
+	if d.connected {
+		d.connected = false
+		err = d.Output.Close()
+	}
+	if d.started {
+		d.started = false
+		d.Output.(telegraf.ServiceOutput).Stop()
+	}
+	return err
+}
@@ -0,0 +1,188 @@
+package models
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/buffer"
+	"github.com/influxdata/telegraf/plugins/serializers"
+	"github.com/influxdata/telegraf/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockDeadLetter keeps the metrics in memory
+type mockDeadLetter struct {
+	metrics []telegraf.Metric
+}
+
+func (d *mockDeadLetter) Add(metrics []telegraf.Metric) error {
+	d.metrics = append(d.metrics, metrics...)
+	return nil
+}
+
+func (d *mockDeadLetter) Close() error {
+	return nil
+}
+
+func newTestDeadLetterFile(t *testing.T, maxSize int64, maxArchives int) (*DeadLetterFile, string) {
+	dir, err := ioutil.TempDir("", "deadletter")
+	require.NoError(t, err)
+	s, err := serializers.NewInfluxSerializer()
+	require.NoError(t, err)
+	d, err := NewDeadLetterFile(filepath.Join(dir, "out", "dead.influx"), s, maxSize, maxArchives)
+	require.NoError(t, err)
+	return d, dir
+}
+
+func TestDeadLetterFile(t *testing.T) {
+	d, dir := newTestDeadLetterFile(t, 0, 0)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, d.Add(first5))
+	require.NoError(t, d.Close())
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "out", "dead.influx"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 5)
+	assert.True(t, strings.HasPrefix(lines[0], "metric1,"))
+}
+
+func TestDeadLetterFileRotate(t *testing.T) {
+	s, err := serializers.NewInfluxSerializer()
+	require.NoError(t, err)
+	line, err := s.Serialize(first5[0])
+	require.NoError(t, err)
+
+	// room for two metrics per file
+	d, dir := newTestDeadLetterFile(t, int64(2*len(line)), 2)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, d.Add(first5))
+	require.NoError(t, d.Add(next5))
+	require.NoError(t, d.Close())
+
+	path := filepath.Join(dir, "out", "dead.influx")
+	files, err := filepath.Glob(path + "*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{path, path + ".1", path + ".2"}, files)
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	// the longer metric10 line does not fit next to metric9
+	assert.True(t, strings.HasPrefix(string(b), "metric10,"))
+}
+
+// serviceOutput is a mockOutput which has to be started before it is
+// connected
+type serviceOutput struct {
+	mockOutput
+	started bool
+	stopped bool
+}
+
+func (m *serviceOutput) Connect() error {
+	if !m.started {
+		return fmt.Errorf("not started")
+	}
+	return nil
+}
+
+func (m *serviceOutput) Start() error {
+	m.started = true
+	return nil
+}
+
+func (m *serviceOutput) Stop() {
+	m.stopped = true
+}
+
+func TestDeadLetterServiceOutput(t *testing.T) {
+	m := &serviceOutput{}
+	d := NewDeadLetterOutput("test", m)
+
+	require.NoError(t, d.Add(first5))
+	require.NoError(t, d.Add(next5))
+	assert.Len(t, m.Metrics(), 10)
+	require.NoError(t, d.Close())
+	assert.True(t, m.stopped)
+}
+
+func TestRunningOutputDeadLetterBufferFull(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("test_deadletter", m, conf, 5, 5)
+	d := &mockDeadLetter{}
+	ro.SetDeadLetter(d)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	for _, metric := range next5 {
+		ro.AddMetric(metric)
+	}
+
+	// the first batch was evicted by the second one
+	require.Len(t, d.metrics, 5)
+	assert.Equal(t, "metric1", d.metrics[0].Name())
+	assert.Equal(t, int64(5), ro.DeadLettered.Get())
+}
+
+func TestRunningOutputDeadLetterRejected(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{reject: "metric4"}
+	ro := NewRunningOutput("test_deadletter_rejected", m, conf, 1000, 10000)
+	d := &mockDeadLetter{}
+	ro.SetDeadLetter(d)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	require.NoError(t, ro.Write())
+
+	assert.Len(t, m.Metrics(), 4)
+	require.Len(t, d.metrics, 1)
+	assert.Equal(t, "metric4", d.metrics[0].Name())
+}
+
+func TestRunningOutputDeadLetterDiskBuffer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deadletter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	db, err := buffer.NewDiskBuffer(dir, 2048)
+	require.NoError(t, err)
+
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("test_deadletter_disk", m, conf, 1000, 10000)
+	ro.SetDiskBuffer(db)
+	d := &mockDeadLetter{}
+	ro.SetDeadLetter(d)
+
+	for i := 0; i < 100; i++ {
+		ro.AddMetric(testutil.TestMetric(i, "metric"))
+	}
+	require.NoError(t, ro.Close())
+
+	// the oldest segments were dropped to the dead-letter sink
+	require.NotEmpty(t, d.metrics)
+	assert.Equal(t, int64(0), d.metrics[0].Fields()["value"])
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// Default time the circuit breaker stays open before a trial write.
+	DEFAULT_CIRCUIT_BREAKER_TIMEOUT = time.Minute
+
+	// Default wait between background connection attempts when no backoff
+	// is configured.
+	DEFAULT_CONNECT_RETRY = 15 * time.Second
+)
+
+var (
+	// ErrBackoff is returned by a write skipped because the output is
+	// backing off after a failed write.
+	ErrBackoff = errors.New("output is backing off after a failed write")
+
+	// ErrCircuitOpen is returned by a write skipped because the circuit
+	// breaker of the output is open.
+	ErrCircuitOpen = errors.New("circuit breaker of the output is open")
+
+	// ErrNotConnected is returned by a write skipped because the output is
+	// still connecting in the background.
+	ErrNotConnected = errors.New("output is not connected")
+)
+
+// RetryConfig configures how writes to a failing output are retried.
+type RetryConfig struct {
+	// InitialBackoff is the wait after the first failed write, it doubles
+	// with every further consecutive failure. Zero retries on every flush.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between retries, zero means no cap.
+	MaxBackoff time.Duration
+	// Jitter is the maximum random duration added to every wait.
+	Jitter time.Duration
+	// MaxAttempts is the number of consecutive failed writes after which the
+	// circuit breaker opens, zero never opens it.
+	MaxAttempts int
+	// CircuitBreakerTimeout is how long the circuit breaker stays open
+	// before a single trial write is let through.
+	CircuitBreakerTimeout time.Duration
+}
+
+// states of the circuit breaker
+const (
+	circuitClosed = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// retryPolicy tracks the consecutive failures of an output and decides when
+// the next write may be attempted.
+type retryPolicy struct {
+	RetryConfig
+
+	mu       sync.Mutex
+	failures int
+	state    int
+	next     time.Time
+
+	// now returns the current time, replaced in tests
+	now func() time.Time
+}
+
+func newRetryPolicy(conf RetryConfig) *retryPolicy {
+	if conf.MaxAttempts > 0 && conf.CircuitBreakerTimeout == 0 {
+		conf.CircuitBreakerTimeout = DEFAULT_CIRCUIT_BREAKER_TIMEOUT
+	}
+	return &retryPolicy{RetryConfig: conf, now: time.Now}
+}
+
+// allow returns nil if a write may be attempted now, or the reason why it
+// may not. When the circuit breaker timeout has passed a single trial write
+// is allowed.
+func (r *retryPolicy) allow() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch r.state {
+	case circuitHalfOpen:
+		// a trial write is in flight
+		return ErrCircuitOpen
+	case circuitOpen:
+		if r.now().Before(r.next) {
+			return ErrCircuitOpen
+		}
+		r.state = circuitHalfOpen
+		return nil
+	}
+	if r.now().Before(r.next) {
+		return ErrBackoff
+	}
+	return nil
+}
+
+// success resets the policy after a successful write.
+func (r *retryPolicy) success() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = 0
+	r.state = circuitClosed
+	r.next = time.Time{}
+}
+
+// failure records a failed write and returns true if it opened the circuit
+// breaker.
+func (r *retryPolicy) failure() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures++
+	now := r.now()
+	if r.state == circuitHalfOpen ||
+		(r.MaxAttempts > 0 && r.failures >= r.MaxAttempts) {
+		opened := r.state != circuitOpen
+		r.state = circuitOpen
+		r.next = now.Add(r.CircuitBreakerTimeout)
+		return opened
+	}
+	r.next = now.Add(r.backoff(r.failures))
+	return false
+}
+
+// wait returns how long until the next attempt is allowed.
+func (r *retryPolicy) wait() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if d := r.next.Sub(r.now()); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// isOpen returns true if the circuit breaker is open or half-open.
+func (r *retryPolicy) isOpen() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state != circuitClosed
+}
+
+// backoff returns the wait after n consecutive failures.
+func (r *retryPolicy) backoff(n int) time.Duration {
+	d := r.InitialBackoff
+	for i := 1; i < n && d > 0; i++ {
+		if r.MaxBackoff > 0 && d >= r.MaxBackoff {
+			break
+		}
+		d *= 2
+	}
+	if r.MaxBackoff > 0 && d > r.MaxBackoff {
+		d = r.MaxBackoff
+	}
+	if r.Jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(r.Jitter)))
+	}
+	return d
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRetryPolicy(conf RetryConfig, now *time.Time) *retryPolicy {
+	r := newRetryPolicy(conf)
+	r.now = func() time.Time { return *now }
+	return r
+}
+
+func TestRetryPolicyDefault(t *testing.T) {
+	now := time.Now()
+	r := newTestRetryPolicy(RetryConfig{}, &now)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, r.allow())
+		assert.False(t, r.failure())
+	}
+	require.NoError(t, r.allow())
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	r := newRetryPolicy(RetryConfig{
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Second,
+	})
+
+	assert.Equal(t, time.Second, r.backoff(1))
+	assert.Equal(t, 2*time.Second, r.backoff(2))
+	assert.Equal(t, 4*time.Second, r.backoff(3))
+	assert.Equal(t, 5*time.Second, r.backoff(4))
+	assert.Equal(t, 5*time.Second, r.backoff(100))
+
+	r.Jitter = time.Second
+	d := r.backoff(1)
+	assert.True(t, d >= time.Second && d < 2*time.Second)
+}
+
+func TestRetryPolicyWaitsBackoff(t *testing.T) {
+	now := time.Now()
+	r := newTestRetryPolicy(RetryConfig{InitialBackoff: time.Second}, &now)
+
+	r.failure()
+	assert.Equal(t, ErrBackoff, r.allow())
+	assert.Equal(t, time.Second, r.wait())
+
+	now = now.Add(time.Second)
+	require.NoError(t, r.allow())
+	r.failure()
+	assert.Equal(t, 2*time.Second, r.wait())
+
+	r.success()
+	require.NoError(t, r.allow())
+	assert.Equal(t, time.Duration(0), r.wait())
+}
+
+func TestRetryPolicyCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	r := newTestRetryPolicy(RetryConfig{
+		MaxAttempts:           3,
+		CircuitBreakerTimeout: time.Minute,
+	}, &now)
+
+	assert.False(t, r.failure())
+	assert.False(t, r.failure())
+	assert.True(t, r.failure())
+	assert.Equal(t, ErrCircuitOpen, r.allow())
+
+	// a single trial write after the timeout
+	now = now.Add(time.Minute)
+	require.NoError(t, r.allow())
+	assert.Equal(t, ErrCircuitOpen, r.allow())
+
+	// failed trial opens the circuit again
+	assert.True(t, r.failure())
+	assert.Equal(t, ErrCircuitOpen, r.allow())
+
+	now = now.Add(time.Minute)
+	require.NoError(t, r.allow())
+	r.success()
+	require.NoError(t, r.allow())
+	require.NoError(t, r.allow())
+}
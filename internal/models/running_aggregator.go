@@ -11,6 +11,16 @@ type RunningAggregator struct {
 	a      telegraf.Aggregator
 	Config *AggregatorConfig
 
+	// Digest identifies the configuration of the aggregator, an aggregator
+	// with an unchanged digest keeps running when the configuration is
+	// reloaded.
+	Digest string
+
+	// ID identifies the aggregator among the aggregators of the same name,
+	// across reloads and restarts. It names the state namespace of the
+	// aggregator.
+	ID string
+
 	metrics chan telegraf.Metric
 
 	periodStart time.Time
@@ -42,12 +52,21 @@ type AggregatorConfig struct {
 
 	Period time.Duration
 	Delay  time.Duration
+
+	// StateNamespace is the namespace of the state of the aggregator, when it
+	// is a telegraf.StatefulPlugin.
+	StateNamespace string
 }
 
 func (r *RunningAggregator) Name() string {
 	return "aggregators." + r.Config.Name
 }
 
+// Aggregator returns the aggregator plugin.
+func (r *RunningAggregator) Aggregator() telegraf.Aggregator {
+	return r.a
+}
+
 func (r *RunningAggregator) MakeMetric(
 	measurement string,
 	fields map[string]interface{},
@@ -110,6 +129,13 @@ func (r *RunningAggregator) reset() {
 	r.a.Reset()
 }
 
+// Push pushes the aggregates of the current period to acc and resets them,
+// it must not be called while Run is running.
+func (r *RunningAggregator) Push(acc telegraf.Accumulator) {
+	r.push(acc)
+	r.reset()
+}
+
 // Run runs the running aggregator, listens for incoming metrics, and waits
 // for period ticks to tell it when to push and reset the aggregator.
 func (r *RunningAggregator) Run(
@@ -10,28 +10,53 @@ import (
 
 var GlobalMetricsGathered = selfstat.Register("agent", "metrics_gathered", map[string]string{})
 
+// Overflow policies of the input queues, deciding what happens to a new
+// metric when the queue of its input is full.
+const (
+	// OverflowBlock blocks the input until there is room in the queue.
+	OverflowBlock = "block"
+	// OverflowDropNewest drops the new metric.
+	OverflowDropNewest = "drop_newest"
+	// OverflowDropOldest drops the oldest metric in the queue.
+	OverflowDropOldest = "drop_oldest"
+)
+
 type RunningInput struct {
 	Input  telegraf.Input
 	Config *InputConfig
 
+	// Digest identifies the configuration of the input, an input with an
+	// unchanged digest keeps running when the configuration is reloaded.
+	Digest string
+
+	// ID identifies the input among the inputs of the same name, across
+	// reloads and restarts. It names the state namespace of the input.
+	ID string
+
 	trace       bool
 	defaultTags map[string]string
+	cardinality *cardinalityGuard
 
 	MetricsGathered selfstat.Stat
+	MetricsDropped  selfstat.Stat
+	GathersSkipped  selfstat.Stat
+	QueueLength     selfstat.Stat
 }
 
 func NewRunningInput(
 	input telegraf.Input,
 	config *InputConfig,
 ) *RunningInput {
+	tags := map[string]string{"input": config.Name}
 	return &RunningInput{
-		Input:  input,
-		Config: config,
-		MetricsGathered: selfstat.Register(
-			"gather",
-			"metrics_gathered",
-			map[string]string{"input": config.Name},
-		),
+		Input:           input,
+		Config:          config,
+		MetricsGathered: selfstat.Register("gather", "metrics_gathered", tags),
+		MetricsDropped:  selfstat.Register("gather", "metrics_dropped", tags),
+		GathersSkipped:  selfstat.Register("gather", "gathers_skipped", tags),
+		QueueLength:     selfstat.Register("gather", "queue_length", tags),
+		cardinality: newCardinalityGuard("inputs."+config.Name,
+			config.Cardinality, "gather", tags),
 	}
 }
 
@@ -44,6 +69,19 @@ type InputConfig struct {
 	Tags              map[string]string
 	Filter            Filter
 	Interval          time.Duration
+
+	// QueueSize is the number of metrics buffered between the input and
+	// the processors, and OverflowPolicy what happens when they are full.
+	// Zero values use the agent defaults.
+	QueueSize      int
+	OverflowPolicy string
+
+	// Cardinality limits the number of series of the input.
+	Cardinality CardinalityConfig
+
+	// StateNamespace is the namespace of the state of the input, when it is
+	// a telegraf.StatefulPlugin.
+	StateNamespace string
 }
 
 func (r *RunningInput) Name() string {
@@ -73,6 +111,9 @@ func (r *RunningInput) MakeMetric(
 		mType,
 		t,
 	)
+	if m != nil && r.cardinality != nil {
+		m = r.cardinality.apply(m)
+	}
 
 	if r.trace && m != nil {
 		fmt.Print("> " + m.String())
@@ -1,13 +1,16 @@
 package models
 
 import (
+	"fmt"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal/buffer"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -27,15 +30,46 @@ type RunningOutput struct {
 	MetricBufferLimit int
 	MetricBatchSize   int
 
+	// Digest identifies the configuration of the output, an output with an
+	// unchanged digest keeps running, and keeps its buffered metrics, when
+	// the configuration is reloaded.
+	Digest string
+
+	// ID identifies the output among the outputs of the same name, across
+	// reloads and restarts. It names the disk buffer directory and the state
+	// namespace of the output.
+	ID string
+
 	MetricsFiltered selfstat.Stat
 	MetricsWritten  selfstat.Stat
+	MetricsRejected selfstat.Stat
+	DeadLettered    selfstat.Stat
 	BufferSize      selfstat.Stat
 	BufferLimit     selfstat.Stat
 	WriteTime       selfstat.Stat
+	CircuitOpen     selfstat.Stat
 
 	metrics     *buffer.Buffer
 	failMetrics *buffer.Buffer
 
+	// diskBuffer replaces metrics and failMetrics when the output buffers its
+	// metrics on disk.
+	diskBuffer *buffer.DiskBuffer
+	// number of metrics added to diskBuffer since the last batch was written
+	diskAdded int
+
+	// receives the metrics which will never be written, if set
+	deadLetter DeadLetter
+
+	cardinality *cardinalityGuard
+
+	retry *retryPolicy
+	// set to 1 while the output is connecting in the background
+	disconnected int32
+	// closed to stop connecting in the background
+	connectDone chan struct{}
+	connectWG   sync.WaitGroup
+
 	// Guards against concurrent calls to the Output as described in #3009
 	sync.Mutex
 }
@@ -66,6 +100,16 @@ func NewRunningOutput(
 			"metrics_written",
 			map[string]string{"output": name},
 		),
+		MetricsRejected: selfstat.Register(
+			"write",
+			"metrics_rejected",
+			map[string]string{"output": name},
+		),
+		DeadLettered: selfstat.Register(
+			"write",
+			"metrics_dead_lettered",
+			map[string]string{"output": name},
+		),
 		MetricsFiltered: selfstat.Register(
 			"write",
 			"metrics_filtered",
@@ -86,11 +130,70 @@ func NewRunningOutput(
 			"write_time_ns",
 			map[string]string{"output": name},
 		),
+		CircuitOpen: selfstat.Register(
+			"write",
+			"circuit_open",
+			map[string]string{"output": name},
+		),
+		retry: newRetryPolicy(conf.Retry),
+		cardinality: newCardinalityGuard("outputs."+name, conf.Cardinality,
+			"write", map[string]string{"output": name}),
 	}
 	ro.BufferLimit.Incr(int64(ro.MetricBufferLimit))
 	return ro
 }
 
+// SetDiskBuffer makes the output keep its metrics in the given DiskBuffer
+// instead of in memory. Metrics are only removed from the DiskBuffer once
+// they have been written to the output.
+func (ro *RunningOutput) SetDiskBuffer(b *buffer.DiskBuffer) {
+	ro.diskBuffer = b
+	if ro.deadLetter != nil {
+		b.SetDropHandler(ro.addDeadLetter)
+	}
+}
+
+// OpenDiskBuffer opens the disk buffer configured in DiskBufferPath, if any.
+func (ro *RunningOutput) OpenDiskBuffer() error {
+	if ro.Config.DiskBufferPath == "" || ro.diskBuffer != nil {
+		return nil
+	}
+	db, err := buffer.NewDiskBuffer(ro.Config.DiskBufferPath,
+		ro.Config.DiskBufferLimit)
+	if err != nil {
+		return fmt.Errorf("Error opening disk buffer for output %s, %s",
+			ro.Name, err)
+	}
+	db.SetSyncOnAdd(ro.Config.DiskBufferSyncOnAdd)
+	ro.SetDiskBuffer(db)
+	return nil
+}
+
+// SetDeadLetter makes the output hand the metrics dropped from its full
+// buffer, or rejected permanently, to the given DeadLetter.
+func (ro *RunningOutput) SetDeadLetter(d DeadLetter) {
+	ro.deadLetter = d
+	ro.metrics.SetDropHandler(ro.addDeadLetter)
+	ro.failMetrics.SetDropHandler(ro.addDeadLetter)
+	if ro.diskBuffer != nil {
+		ro.diskBuffer.SetDropHandler(ro.addDeadLetter)
+	}
+}
+
+// addDeadLetter hands metrics which will never be written to the
+// dead-letter sink.
+func (ro *RunningOutput) addDeadLetter(metrics []telegraf.Metric) {
+	if ro.deadLetter == nil {
+		return
+	}
+	if err := ro.deadLetter.Add(metrics); err != nil {
+		log.Printf("E! Output [%s] failed to write %d metrics to the "+
+			"dead-letter sink: %s\n", ro.Name, len(metrics), err)
+		return
+	}
+	ro.DeadLettered.Incr(int64(len(metrics)))
+}
+
 // AddMetric adds a metric to the output. This function can also write cached
 // points if FlushBufferWhenFull is true.
 func (ro *RunningOutput) AddMetric(m telegraf.Metric) {
@@ -112,19 +215,73 @@ func (ro *RunningOutput) AddMetric(m telegraf.Metric) {
 		// error is not possible if creating from another metric, so ignore.
 		m, _ = metric.New(name, tags, fields, t)
 	}
+	if ro.cardinality != nil {
+		if m = ro.cardinality.apply(m); m == nil {
+			return
+		}
+	}
+
+	if ro.diskBuffer != nil {
+		ro.addDiskMetric(m)
+		return
+	}
 
 	ro.metrics.Add(m)
 	if ro.metrics.Len() == ro.MetricBatchSize {
 		batch := ro.metrics.Batch(ro.MetricBatchSize)
-		err := ro.write(batch)
+		retry, err := ro.write(batch)
 		if err != nil {
-			ro.failMetrics.Add(batch...)
+			ro.failMetrics.Add(retry...)
+		}
+	}
+}
+
+func (ro *RunningOutput) addDiskMetric(m telegraf.Metric) {
+	if err := ro.diskBuffer.Add(m); err != nil {
+		log.Printf("E! Output [%s] failed to buffer metric on disk: %s",
+			ro.Name, err)
+		return
+	}
+	ro.diskAdded++
+	if ro.diskAdded >= ro.MetricBatchSize {
+		ro.diskAdded = 0
+		ro.writeDiskBatch()
+	}
+}
+
+// writeDiskBatch writes the oldest batch of the disk buffer, acknowledging it
+// only when the output accepted it. It returns the number of metrics written.
+func (ro *RunningOutput) writeDiskBatch() (int, error) {
+	batch := ro.diskBuffer.Batch(ro.MetricBatchSize)
+	if len(batch) == 0 {
+		// either empty, or another batch is being written
+		return 0, nil
+	}
+	retry, err := ro.write(batch)
+	if err != nil && len(retry) == len(batch) {
+		ro.diskBuffer.Reject()
+		return 0, err
+	}
+	if err := ro.diskBuffer.Accept(); err != nil {
+		return len(batch), err
+	}
+	if len(retry) > 0 {
+		// only part of the batch failed, buffer it again
+		if aerr := ro.diskBuffer.Add(retry...); aerr != nil {
+			log.Printf("E! Output [%s] failed to buffer metric on disk: %s",
+				ro.Name, aerr)
 		}
+		return len(batch) - len(retry), err
 	}
+	return len(batch), nil
 }
 
 // Write writes all cached points to this output.
 func (ro *RunningOutput) Write() error {
+	if ro.diskBuffer != nil {
+		return ro.writeDisk()
+	}
+
 	nFails, nMetrics := ro.failMetrics.Len(), ro.metrics.Len()
 	ro.BufferSize.Set(int64(nFails + nMetrics))
 	log.Printf("D! Output [%s] buffer fullness: %d / %d metrics. ",
@@ -145,11 +302,12 @@ func (ro *RunningOutput) Write() error {
 			// If we've already failed previous writes, don't bother trying to
 			// write to this output again. We are not exiting the loop just so
 			// that we can rotate the metrics to preserve order.
+			retry := batch
 			if err == nil {
-				err = ro.write(batch)
+				retry, err = ro.write(batch)
 			}
 			if err != nil {
-				ro.failMetrics.Add(batch...)
+				ro.failMetrics.Add(retry...)
 			}
 		}
 	}
@@ -157,38 +315,213 @@ func (ro *RunningOutput) Write() error {
 	batch := ro.metrics.Batch(ro.MetricBatchSize)
 	// see comment above about not trying to write to an already failed output.
 	// if ro.failMetrics is empty then err will always be nil at this point.
+	retry := batch
 	if err == nil {
-		err = ro.write(batch)
+		retry, err = ro.write(batch)
 	}
 
 	if err != nil {
-		ro.failMetrics.Add(batch...)
+		ro.failMetrics.Add(retry...)
 		return err
 	}
 	return nil
 }
 
-func (ro *RunningOutput) write(metrics []telegraf.Metric) error {
-	nMetrics := len(metrics)
-	if nMetrics == 0 {
-		return nil
+func (ro *RunningOutput) writeDisk() error {
+	nMetrics := ro.diskBuffer.Len()
+	ro.BufferSize.Set(int64(nMetrics))
+	log.Printf("D! Output [%s] disk buffer fullness: %d metrics. ",
+		ro.Name, nMetrics)
+	for {
+		n, err := ro.writeDiskBatch()
+		if err != nil {
+			return err
+		}
+		if n < ro.MetricBatchSize {
+			return nil
+		}
+	}
+}
+
+// ConnectBackground marks the output disconnected and connects it in the
+// background, waiting between attempts as configured by the retry policy.
+// Writes are skipped, and metrics kept buffered, until it is connected.
+func (ro *RunningOutput) ConnectBackground() {
+	atomic.StoreInt32(&ro.disconnected, 1)
+	ro.connectDone = make(chan struct{})
+	ro.connectWG.Add(1)
+	go func() {
+		defer ro.connectWG.Done()
+		for {
+			ro.retry.failure()
+			wait := ro.retry.wait()
+			if wait == 0 {
+				wait = DEFAULT_CONNECT_RETRY
+			}
+			select {
+			case <-ro.connectDone:
+				return
+			case <-time.After(wait):
+			}
+
+			err := ro.Output.Connect()
+			if err == nil {
+				ro.retry.success()
+				atomic.StoreInt32(&ro.disconnected, 0)
+				log.Printf("I! Output [%s] connected\n", ro.Name)
+				return
+			}
+			log.Printf("E! Failed to connect to output %s, retrying in the "+
+				"background, error was '%s'\n", ro.Name, err)
+		}
+	}()
+}
+
+// Connected returns false while the output is connecting in the background.
+func (ro *RunningOutput) Connected() bool {
+	return atomic.LoadInt32(&ro.disconnected) == 0
+}
+
+// Close closes the output, the disk buffer and the dead-letter sink, if any.
+func (ro *RunningOutput) Close() error {
+	if ro.connectDone != nil {
+		close(ro.connectDone)
+		ro.connectWG.Wait()
+		ro.connectDone = nil
+	}
+	err := ro.Output.Close()
+	if ro.diskBuffer != nil {
+		if cerr := ro.diskBuffer.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	if ro.deadLetter != nil {
+		if cerr := ro.deadLetter.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// write writes metrics to the output and returns the metrics which have to
+// be written again.
+func (ro *RunningOutput) write(metrics []telegraf.Metric) ([]telegraf.Metric, error) {
+	if len(metrics) == 0 {
+		return nil, nil
+	}
+	if !ro.Connected() {
+		return metrics, ErrNotConnected
 	}
 	ro.Lock()
 	defer ro.Unlock()
+	if err := ro.retry.allow(); err != nil {
+		return metrics, err
+	}
+	retry, err := ro.writeMetrics(metrics)
+	if err != nil {
+		if ro.retry.failure() {
+			log.Printf("W! Output [%s] failed %d consecutive writes, "+
+				"pausing writes for %s\n", ro.Name, ro.retry.MaxAttempts,
+				ro.retry.CircuitBreakerTimeout)
+			ro.CircuitOpen.Set(1)
+		}
+		return retry, err
+	}
+	if ro.retry.isOpen() {
+		log.Printf("I! Output [%s] recovered, resuming writes\n", ro.Name)
+	}
+	ro.retry.success()
+	ro.CircuitOpen.Set(0)
+	return nil, nil
+}
+
+// writeMetrics calls the output, dropping the metrics it rejects
+// permanently, and returns the metrics failed with a transient error.
+func (ro *RunningOutput) writeMetrics(metrics []telegraf.Metric) ([]telegraf.Metric, error) {
 	start := time.Now()
 	err := ro.Output.Write(metrics)
 	elapsed := time.Since(start)
-	if err == nil {
+	switch e := err.(type) {
+	case nil:
 		log.Printf("D! Output [%s] wrote batch of %d metrics in %s\n",
-			ro.Name, nMetrics, elapsed)
-		ro.MetricsWritten.Incr(int64(nMetrics))
+			ro.Name, len(metrics), elapsed)
+		ro.MetricsWritten.Incr(int64(len(metrics)))
 		ro.WriteTime.Incr(elapsed.Nanoseconds())
+		return nil, nil
+	case *outputs.PermanentError:
+		if len(metrics) == 1 {
+			ro.reject(metrics, e.Err)
+			return nil, nil
+		}
+		// write both halves of the batch to isolate the rejected metrics
+		half := len(metrics) / 2
+		retry, err := ro.writeMetrics(metrics[:half])
+		if err != nil {
+			retry = append(append([]telegraf.Metric{}, retry...), metrics[half:]...)
+			return retry, err
+		}
+		return ro.writeMetrics(metrics[half:])
+	case *outputs.PartialWriteError:
+		rejected := pickMetrics(metrics, e.Rejected)
+		retry := pickMetrics(metrics, e.Retry)
+		ro.MetricsWritten.Incr(int64(len(metrics) - len(rejected) - len(retry)))
+		if len(rejected) > 0 {
+			ro.reject(rejected, e.Err)
+		} else if len(retry) == 0 {
+			log.Printf("W! Output [%s] dropped some metrics of a batch of %d: %s\n",
+				ro.Name, len(metrics), e.Err)
+		}
+		if len(retry) > 0 {
+			return retry, e.Err
+		}
+		return nil, nil
+	default:
+		return metrics, err
 	}
-	return err
 }
 
-// OutputConfig containing name and filter
+// reject drops metrics which the output will never accept.
+func (ro *RunningOutput) reject(metrics []telegraf.Metric, err error) {
+	ro.MetricsRejected.Incr(int64(len(metrics)))
+	log.Printf("E! Output [%s] rejected %d metrics, dropping them: %s\n",
+		ro.Name, len(metrics), err)
+	ro.addDeadLetter(metrics)
+}
+
+// pickMetrics returns the metrics at the given indices, ignoring indices out
+// of range.
+func pickMetrics(metrics []telegraf.Metric, indices []int) []telegraf.Metric {
+	var picked []telegraf.Metric
+	for _, i := range indices {
+		if i >= 0 && i < len(metrics) {
+			picked = append(picked, metrics[i])
+		}
+	}
+	return picked
+}
+
+// OutputConfig containing name, filter and retry policy
 type OutputConfig struct {
 	Name   string
 	Filter Filter
+	Retry  RetryConfig
+
+	// BackgroundConnect connects the output in the background instead of
+	// aborting startup when it cannot connect.
+	BackgroundConnect bool
+
+	// DiskBufferPath is the directory of the disk buffer of the output,
+	// empty when it buffers in memory, and DiskBufferLimit its size in
+	// bytes. DiskBufferSyncOnAdd syncs every metric added to the disk buffer
+	// instead of syncing them before every write.
+	DiskBufferPath      string
+	DiskBufferLimit     int64
+	DiskBufferSyncOnAdd bool
+
+	// Cardinality limits the number of series written to the output.
+	Cardinality CardinalityConfig
+
+	// StateNamespace is the namespace of the state of the output, when it is
+	// a telegraf.StatefulPlugin.
+	StateNamespace string
 }
@@ -2,10 +2,15 @@ package models
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/buffer"
+	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/testutil"
 
 	"github.com/stretchr/testify/assert"
@@ -330,6 +335,130 @@ func TestRunningOutputWriteFail(t *testing.T) {
 	assert.Len(t, m.Metrics(), 10)
 }
 
+// Verify that writes are paused, and metrics kept, while the circuit breaker
+// is open.
+func TestRunningOutputCircuitBreaker(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+		Retry: RetryConfig{
+			MaxAttempts:           2,
+			CircuitBreakerTimeout: time.Hour,
+		},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("test", m, conf, 1000, 10000)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	require.Error(t, ro.Write())
+	require.Error(t, ro.Write())
+	assert.Equal(t, int64(1), ro.CircuitOpen.Get())
+
+	m.failWrite = false
+	assert.Equal(t, ErrCircuitOpen, ro.Write())
+	assert.Len(t, m.Metrics(), 0)
+
+	// let the trial write through
+	ro.retry.now = func() time.Time { return time.Now().Add(time.Hour) }
+	require.NoError(t, ro.Write())
+	assert.Len(t, m.Metrics(), 5)
+	assert.Equal(t, int64(0), ro.CircuitOpen.Get())
+}
+
+// Verify that writes are skipped while the output connects in the background.
+func TestRunningOutputBackgroundConnect(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+		Retry:  RetryConfig{InitialBackoff: time.Millisecond},
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput("test", m, conf, 1000, 10000)
+	ro.ConnectBackground()
+	defer ro.Close()
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	for i := 0; i < 100 && !ro.Connected(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.True(t, ro.Connected())
+	require.NoError(t, ro.Write())
+	assert.Len(t, m.Metrics(), 5)
+}
+
+// Verify that only the metrics rejected permanently are dropped.
+func TestRunningOutputPermanentError(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{reject: "metric3"}
+	ro := NewRunningOutput("test_permanent", m, conf, 1000, 10000)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	for _, metric := range next5 {
+		ro.AddMetric(metric)
+	}
+
+	require.NoError(t, ro.Write())
+	assert.Len(t, m.Metrics(), 9)
+	assert.Equal(t, int64(1), ro.MetricsRejected.Get())
+
+	// nothing is left to retry
+	require.NoError(t, ro.Write())
+	assert.Len(t, m.Metrics(), 9)
+}
+
+// partialOutput writes all but the first two metrics of the first batch,
+// rejecting the first one and failing the second one transiently.
+type partialOutput struct {
+	mockOutput
+	calls int
+}
+
+func (m *partialOutput) Write(metrics []telegraf.Metric) error {
+	m.calls++
+	if m.calls > 1 {
+		return m.mockOutput.Write(metrics)
+	}
+	m.mockOutput.Write(metrics[2:])
+	return &outputs.PartialWriteError{
+		Err:      fmt.Errorf("Failed Write!"),
+		Rejected: []int{0},
+		Retry:    []int{1},
+	}
+}
+
+func TestRunningOutputPartialWrite(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &partialOutput{}
+	ro := NewRunningOutput("test_partial", m, conf, 1000, 10000)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	require.Error(t, ro.Write())
+	assert.Len(t, m.Metrics(), 3)
+	assert.Equal(t, int64(1), ro.MetricsRejected.Get())
+
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 4)
+	assert.Equal(t, "metric2", m.Metrics()[3].Name())
+}
+
 // Verify that the order of points is preserved during a write failure.
 func TestRunningOutputWriteFailOrder(t *testing.T) {
 	conf := &OutputConfig{
@@ -479,6 +608,80 @@ func TestRunningOutputWriteFailOrder3(t *testing.T) {
 	assert.Equal(t, expected, m.Metrics())
 }
 
+func newDiskRunningOutput(t *testing.T, m telegraf.Output, dir string) *RunningOutput {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+	ro := NewRunningOutput("test", m, conf, 4, 12)
+	db, err := buffer.NewDiskBuffer(dir, 1024*1024)
+	require.NoError(t, err)
+	ro.SetDiskBuffer(db)
+	return ro
+}
+
+// Verify that metrics stay in the disk buffer until they are written.
+func TestRunningOutputDiskBufferWriteFail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf-output")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := newDiskRunningOutput(t, m, dir)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	for _, metric := range next5 {
+		ro.AddMetric(metric)
+	}
+	assert.Len(t, m.Metrics(), 0)
+
+	err = ro.Write()
+	require.Error(t, err)
+	assert.Len(t, m.Metrics(), 0)
+
+	m.failWrite = false
+	err = ro.Write()
+	require.NoError(t, err)
+
+	received := m.Metrics()
+	require.Len(t, received, 10)
+	for i, metric := range append(first5, next5...) {
+		assert.Equal(t, metric.String(), received[i].String())
+	}
+	require.NoError(t, ro.Close())
+}
+
+// Verify that unwritten metrics are replayed when the disk buffer is reopened.
+func TestRunningOutputDiskBufferReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf-output")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := newDiskRunningOutput(t, m, dir)
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	require.Error(t, ro.Write())
+	require.NoError(t, ro.Close())
+
+	m = &mockOutput{}
+	ro = newDiskRunningOutput(t, m, dir)
+	require.NoError(t, ro.Write())
+	assert.Len(t, m.Metrics(), 5)
+
+	// written metrics are not replayed again
+	require.NoError(t, ro.Close())
+	m = &mockOutput{}
+	ro = newDiskRunningOutput(t, m, dir)
+	require.NoError(t, ro.Write())
+	assert.Len(t, m.Metrics(), 0)
+	require.NoError(t, ro.Close())
+}
+
 type mockOutput struct {
 	sync.Mutex
 
@@ -486,6 +689,9 @@ type mockOutput struct {
 
 	// if true, mock a write failure
 	failWrite bool
+
+	// if set, permanently reject batches with a metric of this name
+	reject string
 }
 
 func (m *mockOutput) Connect() error {
@@ -510,6 +716,11 @@ func (m *mockOutput) Write(metrics []telegraf.Metric) error {
 	if m.failWrite {
 		return fmt.Errorf("Failed Write!")
 	}
+	for _, metric := range metrics {
+		if metric.Name() == m.reject {
+			return outputs.Permanent(fmt.Errorf("Rejected %s!", m.reject))
+		}
+	}
 
 	if m.metrics == nil {
 		m.metrics = []telegraf.Metric{}
@@ -12,6 +12,15 @@ type RunningProcessor struct {
 	sync.Mutex
 	Processor telegraf.Processor
 	Config    *ProcessorConfig
+
+	// Digest identifies the configuration of the processor, a processor
+	// with an unchanged digest is kept when the configuration is reloaded.
+	Digest string
+
+	// ID identifies the processor among the processors of the same name,
+	// across reloads and restarts. It names the state namespace of the
+	// processor.
+	ID string
 }
 
 type RunningProcessors []*RunningProcessor
@@ -25,6 +34,10 @@ type ProcessorConfig struct {
 	Name   string
 	Order  int64
 	Filter Filter
+
+	// StateNamespace is the namespace of the state of the processor, when it
+	// is a telegraf.StatefulPlugin.
+	StateNamespace string
 }
 
 func (rp *RunningProcessor) Apply(in ...telegraf.Metric) []telegraf.Metric {
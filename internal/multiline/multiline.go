@@ -0,0 +1,158 @@
+// Package multiline joins the lines of multiline events, like the stack
+// traces of a log file, before they are parsed.
+package multiline
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+const (
+	// DEFAULT_TIMEOUT is the default time after which the last event of a
+	// file is flushed when no line follows it.
+	DEFAULT_TIMEOUT = 5 * time.Second
+
+	// DEFAULT_MAX_LINES is the default maximum number of lines of an event,
+	// the lines over it start a new event.
+	DEFAULT_MAX_LINES = 1000
+)
+
+// Config selects the lines continuing an event, a line which does not
+// continue an event starts a new one. A line continues an event when it
+// matches any of the set conditions.
+type Config struct {
+	// Start matches the first line of the events, the lines which do not
+	// match it continue the event.
+	Start string
+	// Continuation matches the lines continuing an event.
+	Continuation string
+	// Indented continues an event with the lines starting with a space or a
+	// tab.
+	Indented bool
+
+	// Timeout flushes the last event of a file when no line follows it.
+	Timeout internal.Duration
+	// MaxLines is the maximum number of lines of an event.
+	MaxLines int
+}
+
+// Matcher is a compiled Config.
+type Matcher struct {
+	start        *regexp.Regexp
+	continuation *regexp.Regexp
+	indented     bool
+	timeout      time.Duration
+	maxLines     int
+}
+
+// NewMatcher compiles the config, it returns nil when the config does not
+// join lines.
+func (c *Config) NewMatcher() (*Matcher, error) {
+	if c == nil || (c.Start == "" && c.Continuation == "" && !c.Indented) {
+		return nil, nil
+	}
+
+	m := &Matcher{
+		indented: c.Indented,
+		timeout:  c.Timeout.Duration,
+		maxLines: c.MaxLines,
+	}
+	if m.timeout <= 0 {
+		m.timeout = DEFAULT_TIMEOUT
+	}
+	if m.maxLines <= 0 {
+		m.maxLines = DEFAULT_MAX_LINES
+	}
+
+	var err error
+	if c.Start != "" {
+		if m.start, err = regexp.Compile(c.Start); err != nil {
+			return nil, fmt.Errorf("invalid multiline start pattern: %s", err)
+		}
+	}
+	if c.Continuation != "" {
+		if m.continuation, err = regexp.Compile(c.Continuation); err != nil {
+			return nil, fmt.Errorf("invalid multiline continuation pattern: %s", err)
+		}
+	}
+	return m, nil
+}
+
+// continues returns true when the line continues the current event.
+func (m *Matcher) continues(line string) bool {
+	if m.indented && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+		return true
+	}
+	if m.continuation != nil && m.continuation.MatchString(line) {
+		return true
+	}
+	return m.start != nil && !m.start.MatchString(line)
+}
+
+// NewBuffer returns a buffer for the lines of a file. The buffer of a nil
+// Matcher is nil, and passes on every line as an event.
+func (m *Matcher) NewBuffer() *Buffer {
+	if m == nil {
+		return nil
+	}
+	timer := time.NewTimer(m.timeout)
+	timer.Stop()
+	return &Buffer{m: m, timer: timer}
+}
+
+// Buffer holds the lines of the event being read from a file. It is not
+// safe for concurrent use.
+type Buffer struct {
+	m     *Matcher
+	lines []string
+	timer *time.Timer
+}
+
+// Add adds a line to the buffer, and returns the previous event when the
+// line starts a new one.
+func (b *Buffer) Add(line string) (string, bool) {
+	if b == nil {
+		return line, true
+	}
+
+	var event string
+	var ok bool
+	if len(b.lines) > 0 && (!b.m.continues(line) || len(b.lines) >= b.m.maxLines) {
+		event, ok = b.Flush()
+	}
+	b.lines = append(b.lines, line)
+
+	if !b.timer.Stop() {
+		select {
+		case <-b.timer.C:
+		default:
+		}
+	}
+	b.timer.Reset(b.m.timeout)
+	return event, ok
+}
+
+// Timeout returns a channel receiving the time once no line followed the
+// buffered event within the timeout, the event should then be flushed.
+func (b *Buffer) Timeout() <-chan time.Time {
+	if b == nil {
+		return nil
+	}
+	return b.timer.C
+}
+
+// Flush returns the buffered event, joining its lines with newlines, and
+// empties the buffer.
+func (b *Buffer) Flush() (string, bool) {
+	if b == nil || len(b.lines) == 0 {
+		return "", false
+	}
+	event := strings.Join(b.lines, "\n")
+	b.lines = b.lines[:0]
+	b.timer.Stop()
+	return event, true
+}
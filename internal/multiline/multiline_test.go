@@ -0,0 +1,130 @@
+package multiline
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const javaLog = `2018-02-06 12:00:01 INFO starting
+2018-02-06 12:00:02 ERROR failed
+java.lang.IllegalStateException: failed
+	at com.example.Main.run(Main.java:42)
+	at com.example.Main.main(Main.java:12)
+2018-02-06 12:00:03 INFO retrying`
+
+// join adds the lines to a buffer and returns the events, the last one
+// flushed.
+func join(t *testing.T, c *Config, lines []string) []string {
+	m, err := c.NewMatcher()
+	require.NoError(t, err)
+	b := m.NewBuffer()
+
+	var events []string
+	for _, line := range lines {
+		if event, ok := b.Add(line); ok {
+			events = append(events, event)
+		}
+	}
+	if event, ok := b.Flush(); ok {
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestJoin(t *testing.T) {
+	expected := []string{
+		"2018-02-06 12:00:01 INFO starting",
+		"2018-02-06 12:00:02 ERROR failed\n" +
+			"java.lang.IllegalStateException: failed\n" +
+			"\tat com.example.Main.run(Main.java:42)\n" +
+			"\tat com.example.Main.main(Main.java:12)",
+		"2018-02-06 12:00:03 INFO retrying",
+	}
+
+	tests := []struct {
+		name   string
+		config *Config
+	}{
+		{
+			name:   "start",
+			config: &Config{Start: `^\d{4}-\d{2}-\d{2} `},
+		},
+		{
+			name: "continuation",
+			config: &Config{
+				Continuation: `^(\s+at |java\.)`,
+			},
+		},
+		{
+			name: "indented and continuation",
+			config: &Config{
+				Continuation: `^java\.`,
+				Indented:     true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, expected, join(t, tt.config, strings.Split(javaLog, "\n")))
+		})
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	var c *Config
+	m, err := c.NewMatcher()
+	require.NoError(t, err)
+	assert.Nil(t, m)
+
+	m, err = (&Config{}).NewMatcher()
+	require.NoError(t, err)
+	assert.Nil(t, m)
+
+	// the nil buffer passes on every line
+	lines := strings.Split(javaLog, "\n")
+	assert.Equal(t, lines, join(t, nil, lines))
+}
+
+func TestInvalidPattern(t *testing.T) {
+	_, err := (&Config{Start: "("}).NewMatcher()
+	assert.Error(t, err)
+	_, err = (&Config{Continuation: "("}).NewMatcher()
+	assert.Error(t, err)
+}
+
+func TestMaxLines(t *testing.T) {
+	c := &Config{Indented: true, MaxLines: 2}
+	lines := []string{"a", " b", " c", " d", "e"}
+	assert.Equal(t, []string{"a\n b", " c\n d", "e"}, join(t, c, lines))
+}
+
+func TestTimeout(t *testing.T) {
+	m, err := (&Config{
+		Indented: true,
+		Timeout:  internal.Duration{Duration: 10 * time.Millisecond},
+	}).NewMatcher()
+	require.NoError(t, err)
+	b := m.NewBuffer()
+
+	_, ok := b.Add("a")
+	assert.False(t, ok)
+	_, ok = b.Add(" b")
+	assert.False(t, ok)
+
+	select {
+	case <-b.Timeout():
+	case <-time.After(time.Second):
+		t.Fatal("the event was not flushed on timeout")
+	}
+	event, ok := b.Flush()
+	assert.True(t, ok)
+	assert.Equal(t, "a\n b", event)
+
+	_, ok = b.Flush()
+	assert.False(t, ok)
+}
@@ -0,0 +1,16 @@
+// +build !windows
+
+package offsets
+
+import (
+	"os"
+	"syscall"
+)
+
+// inode returns the inode of a file.
+func inode(info os.FileInfo) uint64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(st.Ino)
+	}
+	return 0
+}
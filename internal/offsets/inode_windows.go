@@ -0,0 +1,8 @@
+package offsets
+
+import "os"
+
+// inode returns 0, the files are only identified by their path on Windows.
+func inode(info os.FileInfo) uint64 {
+	return 0
+}
@@ -0,0 +1,178 @@
+// Package offsets persists the read offsets of the files tailed by the
+// plugins, so they resume where they stopped after a restart.
+package offsets
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+// STATE_KEY is the key of the offsets in the state store of a plugin.
+const STATE_KEY = "offsets"
+
+// Offset is the read offset of a file, identified by its path and inode.
+type Offset struct {
+	Path   string `json:"path"`
+	Inode  uint64 `json:"inode"`
+	Offset int64  `json:"offset"`
+}
+
+// Store holds the offsets of the files read by a plugin, and saves them to a
+// state file, or to the state store of the plugin.
+type Store struct {
+	path  string
+	state telegraf.StateStore
+
+	mu sync.Mutex
+	// saved are the offsets of the state file, offsets those of the files
+	// read since, which replace them on Save.
+	saved   map[string]Offset
+	offsets map[string]Offset
+}
+
+// Open loads the offsets saved to the state file, which does not need to
+// exist.
+func Open(path string) (*Store, error) {
+	s := newStore()
+	s.path = path
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.load(b); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load loads the offsets saved to the state store of a plugin.
+func Load(state telegraf.StateStore) (*Store, error) {
+	s := newStore()
+	s.state = state
+
+	if b, ok := state.Get(STATE_KEY); ok {
+		if err := s.load(b); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func newStore() *Store {
+	return &Store{
+		saved:   make(map[string]Offset),
+		offsets: make(map[string]Offset),
+	}
+}
+
+// load loads saved offsets.
+func (s *Store) load(b []byte) error {
+	var saved []Offset
+	if err := json.Unmarshal(b, &saved); err != nil {
+		return err
+	}
+	for _, o := range saved {
+		s.saved[o.Path] = o
+	}
+	return nil
+}
+
+// Resume returns the offset to resume reading a file from, false when no
+// offset was saved for it. A file replaced since the offset was saved, ie by
+// a log rotation, is read from the beginning, as is a file truncated below
+// its offset. The offset of a file renamed since, with the same inode, is
+// its offset under its previous path.
+func (s *Store) Resume(path string) (int64, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, false
+	}
+	ino := inode(info)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.saved[path]
+	if ok && o.Inode != ino {
+		return 0, true
+	}
+	if !ok && ino != 0 {
+		for _, saved := range s.saved {
+			if saved.Inode == ino {
+				o, ok = saved, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return 0, false
+	}
+	if o.Offset > info.Size() {
+		return 0, true
+	}
+	return o.Offset, true
+}
+
+// Set sets the read offset of a file.
+func (s *Store) Set(path string, offset int64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offsets[path] = Offset{
+		Path:   path,
+		Inode:  inode(info),
+		Offset: offset,
+	}
+}
+
+// Save writes the offsets set to the state file, or to the state store, the
+// offsets of the files which were not read since they were loaded are
+// dropped.
+func (s *Store) Save() error {
+	s.mu.Lock()
+	offsets := make([]Offset, 0, len(s.offsets))
+	for _, o := range s.offsets {
+		offsets = append(offsets, o)
+	}
+	s.mu.Unlock()
+
+	b, err := json.Marshal(offsets)
+	if err != nil {
+		return err
+	}
+	if s.state != nil {
+		// the state store is saved by the agent
+		s.state.Set(STATE_KEY, b)
+		return nil
+	}
+
+	// write to a temporary file renamed over the state file, so it is never
+	// left half written
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path))
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
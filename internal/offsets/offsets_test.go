@@ -0,0 +1,128 @@
+package offsets
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/influxdata/telegraf/internal/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+// reopen saves the offsets of a store and opens them again.
+func reopen(t *testing.T, s *Store) *Store {
+	require.NoError(t, s.Save())
+	s, err := Open(s.path)
+	require.NoError(t, err)
+	return s
+}
+
+func TestResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "offsets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	log := filepath.Join(dir, "app.log")
+	writeFile(t, log, "line 1\nline 2\n")
+
+	s, err := Open(filepath.Join(dir, "offsets.json"))
+	require.NoError(t, err)
+	_, ok := s.Resume(log)
+	assert.False(t, ok)
+
+	s.Set(log, 7)
+	s = reopen(t, s)
+	offset, ok := s.Resume(log)
+	assert.True(t, ok)
+	assert.Equal(t, int64(7), offset)
+
+	// a truncated file is read from the beginning
+	writeFile(t, log, "l\n")
+	offset, ok = s.Resume(log)
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), offset)
+
+	// the offsets of the files not read since are dropped
+	s = reopen(t, s)
+	_, ok = s.Resume(log)
+	assert.False(t, ok)
+}
+
+func TestResumeRotated(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("files are not identified by their inode on windows")
+	}
+
+	dir, err := ioutil.TempDir("", "offsets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	log := filepath.Join(dir, "app.log")
+	writeFile(t, log, "line 1\nline 2\n")
+
+	s, err := Open(filepath.Join(dir, "offsets.json"))
+	require.NoError(t, err)
+	s.Set(log, 7)
+	s = reopen(t, s)
+
+	// the log is rotated while not read
+	require.NoError(t, os.Rename(log, log+".1"))
+	writeFile(t, log, "line 3\nline 4\nline 5\n")
+
+	// the new file is read from the beginning, the rotated file from its
+	// offset
+	offset, ok := s.Resume(log)
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), offset)
+	offset, ok = s.Resume(log + ".1")
+	assert.True(t, ok)
+	assert.Equal(t, int64(7), offset)
+}
+
+func TestOpenInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "offsets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "offsets.json")
+	writeFile(t, path, "{")
+	_, err = Open(path)
+	assert.Error(t, err)
+}
+
+func TestLoadState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "offsets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	log := filepath.Join(dir, "app.log")
+	writeFile(t, log, "line 1\nline 2\n")
+
+	st, err := state.Open("")
+	require.NoError(t, err)
+	s, err := Load(st.Namespace("inputs.tail"))
+	require.NoError(t, err)
+	_, ok := s.Resume(log)
+	assert.False(t, ok)
+
+	s.Set(log, 7)
+	require.NoError(t, s.Save())
+	assert.Equal(t, []string{STATE_KEY}, st.Namespace("inputs.tail").Keys())
+
+	s, err = Load(st.Namespace("inputs.tail"))
+	require.NoError(t, err)
+	offset, ok := s.Resume(log)
+	assert.True(t, ok)
+	assert.Equal(t, int64(7), offset)
+
+	st.Namespace("inputs.tail").Set(STATE_KEY, []byte("{"))
+	_, err = Load(st.Namespace("inputs.tail"))
+	assert.Error(t, err)
+}
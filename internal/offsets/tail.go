@@ -0,0 +1,38 @@
+// +build !solaris
+
+package offsets
+
+import (
+	"os"
+
+	"github.com/influxdata/tail"
+)
+
+// Location returns where to start tailing a file, and the offset of that
+// location: the saved offset of the file, or else its beginning or its end.
+// The offsets of the lines read from the file are counted from it by Next.
+func (s *Store) Location(path string, fromBeginning bool) (*tail.SeekInfo, int64) {
+	if offset, ok := s.Resume(path); ok {
+		return &tail.SeekInfo{Whence: 0, Offset: offset}, offset
+	}
+	if fromBeginning {
+		return &tail.SeekInfo{Whence: 0, Offset: 0}, 0
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return &tail.SeekInfo{Whence: 2, Offset: 0}, 0
+	}
+	return &tail.SeekInfo{Whence: 0, Offset: info.Size()}, info.Size()
+}
+
+// Next returns the offset of the end of a line received from a tailer, given
+// the offset of the end of the previous line. The offset of the tailer itself
+// can be ahead of the lines received, it is only used once it goes back, when
+// the tailer reopens a rotated or truncated file.
+func Next(tailer *tail.Tail, offset int64, line string) int64 {
+	offset += int64(len(line)) + 1
+	if tell, err := tailer.Tell(); err == nil && tell < offset {
+		return tell
+	}
+	return offset
+}
@@ -0,0 +1,41 @@
+// +build !solaris
+
+package offsets
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "offsets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	log := filepath.Join(dir, "app.log")
+	writeFile(t, log, "line 1\nline 2\n")
+
+	s, err := Open(filepath.Join(dir, "offsets.json"))
+	require.NoError(t, err)
+
+	// the end of the file is an offset from the beginning
+	location, offset := s.Location(log, false)
+	assert.Equal(t, 0, location.Whence)
+	assert.Equal(t, int64(14), location.Offset)
+	assert.Equal(t, int64(14), offset)
+
+	location, offset = s.Location(log, true)
+	assert.Equal(t, int64(0), location.Offset)
+	assert.Equal(t, int64(0), offset)
+
+	s.Set(log, 7)
+	s = reopen(t, s)
+	location, offset = s.Location(log, false)
+	assert.Equal(t, int64(7), location.Offset)
+	assert.Equal(t, int64(7), offset)
+}
@@ -0,0 +1,54 @@
+package prompb
+
+import (
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	req := &WriteRequest{
+		Timeseries: []*TimeSeries{
+			{
+				Labels: []*Label{
+					{Name: MetricNameLabel, Value: "up"},
+					{Name: "job", Value: "node"},
+				},
+				Samples: []*Sample{
+					{Value: 1, Timestamp: 1538733600000},
+					{Value: 0.5, Timestamp: 1538733610000},
+				},
+			},
+		},
+	}
+
+	body, err := Encode(req)
+	require.NoError(t, err)
+
+	decoded, err := Decode(body)
+	require.NoError(t, err)
+	require.Len(t, decoded.Timeseries, 1)
+	assert.Equal(t, req.Timeseries[0].Labels, decoded.Timeseries[0].Labels)
+	assert.Equal(t, req.Timeseries[0].Samples, decoded.Timeseries[0].Samples)
+
+	_, err = Decode([]byte("not snappy"))
+	assert.Error(t, err)
+}
+
+// the encoding of a request built with the Prometheus prompb package
+func TestDecodeWireFormat(t *testing.T) {
+	// timeseries { labels { name: "__name__" value: "up" } samples { value: 1 timestamp: 1000 } }
+	data := []byte{
+		0x0a, 0x1e,
+		0x0a, 0x0e, 0x0a, 0x08, '_', '_', 'n', 'a', 'm', 'e', '_', '_', 0x12, 0x02, 'u', 'p',
+		0x12, 0x0c, 0x09, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f, 0x10, 0xe8, 0x07,
+	}
+	req, err := Decode(snappy.Encode(nil, data))
+	require.NoError(t, err)
+	require.Len(t, req.Timeseries, 1)
+	assert.Equal(t, "up", req.Timeseries[0].Labels[0].Value)
+	assert.Equal(t, 1.0, req.Timeseries[0].Samples[0].Value)
+	assert.Equal(t, int64(1000), req.Timeseries[0].Samples[0].Timestamp)
+}
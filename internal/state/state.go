@@ -0,0 +1,154 @@
+// Package state keeps the state of the plugins in a single file of the state
+// directory, one namespace of keys per plugin.
+package state
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+// STATE_FILE is the name of the state file in the state directory.
+const STATE_FILE = "state.json"
+
+// Store holds the state of every plugin, by namespace. A Store without a
+// directory keeps the state in memory, it survives config reloads but not
+// restarts.
+type Store struct {
+	path string
+
+	mu         sync.Mutex
+	namespaces map[string]map[string][]byte
+	dirty      bool
+}
+
+// Open loads the state file of a directory, which does not need to exist.
+// An empty directory returns a Store kept in memory.
+func Open(dir string) (*Store, error) {
+	s := &Store{
+		namespaces: make(map[string]map[string][]byte),
+	}
+	if dir == "" {
+		return s, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	s.path = filepath.Join(dir, STATE_FILE)
+
+	b, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &s.namespaces); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Namespace returns the store of a plugin. The stores of the same namespace
+// share their keys, so a plugin recreated by a config reload finds the state
+// of the plugin it replaces.
+func (s *Store) Namespace(name string) telegraf.StateStore {
+	return &namespace{store: s, name: name}
+}
+
+// Save writes the state to the state file when it changed since the last
+// Save.
+func (s *Store) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path == "" || !s.dirty {
+		return nil
+	}
+	b, err := json.Marshal(s.namespaces)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file renamed over the state file, so it is never
+	// left half written
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), STATE_FILE)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// namespace is the StateStore of a plugin.
+type namespace struct {
+	store *Store
+	name  string
+}
+
+func (n *namespace) Get(key string) ([]byte, bool) {
+	n.store.mu.Lock()
+	defer n.store.mu.Unlock()
+	v, ok := n.store.namespaces[n.name][key]
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), v...), true
+}
+
+func (n *namespace) Set(key string, value []byte) {
+	n.store.mu.Lock()
+	defer n.store.mu.Unlock()
+	keys, ok := n.store.namespaces[n.name]
+	if !ok {
+		keys = make(map[string][]byte)
+		n.store.namespaces[n.name] = keys
+	}
+	keys[key] = append([]byte(nil), value...)
+	n.store.dirty = true
+}
+
+func (n *namespace) Delete(key string) {
+	n.store.mu.Lock()
+	defer n.store.mu.Unlock()
+	keys, ok := n.store.namespaces[n.name]
+	if !ok {
+		return
+	}
+	if _, ok := keys[key]; !ok {
+		return
+	}
+	delete(keys, key)
+	if len(keys) == 0 {
+		delete(n.store.namespaces, n.name)
+	}
+	n.store.dirty = true
+}
+
+func (n *namespace) Keys() []string {
+	n.store.mu.Lock()
+	defer n.store.mu.Unlock()
+	keys := make([]string, 0, len(n.store.namespaces[n.name]))
+	for key := range n.store.namespaces[n.name] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
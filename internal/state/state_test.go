@@ -0,0 +1,92 @@
+package state
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespaces(t *testing.T) {
+	s, err := Open("")
+	require.NoError(t, err)
+
+	tail := s.Namespace("inputs.tail")
+	tail.Set("offsets", []byte("1"))
+	tail.Set("b", []byte("2"))
+	statsd := s.Namespace("inputs.statsd")
+	statsd.Set("offsets", []byte("3"))
+
+	v, ok := tail.Get("offsets")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), v)
+	assert.Equal(t, []string{"b", "offsets"}, tail.Keys())
+
+	// a store of the same namespace shares the keys
+	v, ok = s.Namespace("inputs.tail").Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("2"), v)
+
+	tail.Delete("offsets")
+	_, ok = tail.Get("offsets")
+	assert.False(t, ok)
+	v, ok = statsd.Get("offsets")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("3"), v)
+
+	assert.Empty(t, s.Namespace("inputs.exec").Keys())
+	// without a directory there is nothing to save
+	assert.NoError(t, s.Save())
+}
+
+func TestSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	dir = filepath.Join(dir, "telegraf")
+
+	s, err := Open(dir)
+	require.NoError(t, err)
+	assert.Empty(t, s.Namespace("inputs.tail").Keys())
+
+	s.Namespace("inputs.tail").Set("offsets", []byte(`[{"path":"a"}]`))
+	s.Namespace("aggregators.minmax").Set("window", []byte{0, 1, 2})
+	require.NoError(t, s.Save())
+
+	s, err = Open(dir)
+	require.NoError(t, err)
+	v, ok := s.Namespace("inputs.tail").Get("offsets")
+	assert.True(t, ok)
+	assert.Equal(t, []byte(`[{"path":"a"}]`), v)
+	v, ok = s.Namespace("aggregators.minmax").Get("window")
+	assert.True(t, ok)
+	assert.Equal(t, []byte{0, 1, 2}, v)
+
+	// the state file is only written when the state changed
+	path := filepath.Join(dir, STATE_FILE)
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, s.Save())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	s.Namespace("inputs.tail").Delete("offsets")
+	require.NoError(t, s.Save())
+	s, err = Open(dir)
+	require.NoError(t, err)
+	assert.Empty(t, s.Namespace("inputs.tail").Keys())
+	assert.Equal(t, []string{"window"}, s.Namespace("aggregators.minmax").Keys())
+}
+
+func TestOpenInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, STATE_FILE)
+	require.NoError(t, ioutil.WriteFile(path, []byte("not json"), 0644))
+	_, err = Open(dir)
+	assert.Error(t, err)
+}
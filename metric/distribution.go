@@ -0,0 +1,134 @@
+package metric
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Histogram and summary metrics, of the telegraf.Histogram and
+// telegraf.Summary types, hold the buckets of the histogram, as cumulative
+// counts, or the quantiles of the summary in fields named after their bound,
+// ie "0.5" or "+Inf", next to the "sum" and "count" fields.
+const (
+	SumField   = "sum"
+	CountField = "count"
+
+	// BucketTag and QuantileTag hold the bound of the flat metrics of a
+	// histogram or summary, see Flatten.
+	BucketTag   = "le"
+	QuantileTag = "quantile"
+
+	// BucketField and QuantileField hold the value of the flat metrics of a
+	// histogram or summary.
+	BucketField   = "bucket"
+	QuantileField = "value"
+)
+
+// Bucket is a bucket of a histogram, Value counting the values lower than or
+// equal to Bound, or a quantile of a summary.
+type Bucket struct {
+	Bound float64
+	Value float64
+}
+
+// BoundField returns the field holding the bucket or quantile of a bound.
+func BoundField(bound float64) string {
+	switch {
+	case math.IsInf(bound, 1):
+		return "+Inf"
+	case math.IsInf(bound, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(bound, 'g', -1, 64)
+}
+
+// IsDistribution returns true for histogram and summary metrics.
+func IsDistribution(m telegraf.Metric) bool {
+	return m.Type() == telegraf.Histogram || m.Type() == telegraf.Summary
+}
+
+// Buckets returns the buckets or quantiles of a histogram or summary metric,
+// sorted by bound, and its sum and count. Fields which are neither are
+// ignored.
+func Buckets(m telegraf.Metric) (buckets []Bucket, sum float64, count float64) {
+	for k, v := range m.Fields() {
+		var value float64
+		switch v := v.(type) {
+		case float64:
+			value = v
+		case int64:
+			value = float64(v)
+		default:
+			continue
+		}
+
+		switch k {
+		case SumField:
+			sum = value
+		case CountField:
+			count = value
+		default:
+			bound, err := strconv.ParseFloat(k, 64)
+			if err != nil {
+				continue
+			}
+			buckets = append(buckets, Bucket{Bound: bound, Value: value})
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Bound < buckets[j].Bound
+	})
+	return buckets, sum, count
+}
+
+// Flatten returns the metrics with the histograms and summaries replaced by
+// flat metrics, for the backends without a type for them: a metric with the
+// sum and count fields, and a metric per bucket or quantile with its bound in
+// the le or quantile tag and its value in the bucket or value field.
+func Flatten(metrics []telegraf.Metric) []telegraf.Metric {
+	var flat []telegraf.Metric
+	for i, m := range metrics {
+		if !IsDistribution(m) {
+			if flat != nil {
+				flat = append(flat, m)
+			}
+			continue
+		}
+		if flat == nil {
+			flat = append(make([]telegraf.Metric, 0, len(metrics)), metrics[:i]...)
+		}
+		flat = append(flat, flatten(m)...)
+	}
+	if flat == nil {
+		return metrics
+	}
+	return flat
+}
+
+func flatten(m telegraf.Metric) []telegraf.Metric {
+	tag, field := BucketTag, BucketField
+	if m.Type() == telegraf.Summary {
+		tag, field = QuantileTag, QuantileField
+	}
+
+	buckets, sum, count := Buckets(m)
+	metrics := make([]telegraf.Metric, 0, len(buckets)+1)
+	totals, err := New(m.Name(), m.Tags(),
+		map[string]interface{}{SumField: sum, CountField: count}, m.Time())
+	if err == nil {
+		metrics = append(metrics, totals)
+	}
+	for _, b := range buckets {
+		tags := m.Tags()
+		tags[tag] = BoundField(b.Bound)
+		bucket, err := New(m.Name(), tags,
+			map[string]interface{}{field: b.Value}, m.Time())
+		if err == nil {
+			metrics = append(metrics, bucket)
+		}
+	}
+	return metrics
+}
@@ -0,0 +1,78 @@
+package metric
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+)
+
+func newHistogram(t *testing.T, typ telegraf.ValueType) telegraf.Metric {
+	m, err := New("http_request_duration_seconds",
+		map[string]string{"path": "/"},
+		map[string]interface{}{
+			"0.5":   float64(10),
+			"+Inf":  float64(12),
+			"0.05":  float64(4),
+			"sum":   float64(3.5),
+			"count": float64(12),
+		},
+		time.Unix(0, 0),
+		typ,
+	)
+	require.NoError(t, err)
+	return m
+}
+
+func TestBoundField(t *testing.T) {
+	assert.Equal(t, "0.5", BoundField(0.5))
+	assert.Equal(t, "1e+06", BoundField(1000000))
+	assert.Equal(t, "+Inf", BoundField(math.Inf(1)))
+}
+
+func TestBuckets(t *testing.T) {
+	buckets, sum, count := Buckets(newHistogram(t, telegraf.Histogram))
+	assert.Equal(t, []Bucket{
+		{Bound: 0.05, Value: 4},
+		{Bound: 0.5, Value: 10},
+		{Bound: math.Inf(1), Value: 12},
+	}, buckets)
+	assert.Equal(t, 3.5, sum)
+	assert.Equal(t, float64(12), count)
+}
+
+func TestFlatten(t *testing.T) {
+	gauge, err := New("cpu", nil, map[string]interface{}{"usage": 1.0}, time.Unix(0, 0))
+	require.NoError(t, err)
+
+	metrics := Flatten([]telegraf.Metric{gauge, newHistogram(t, telegraf.Histogram)})
+	require.Len(t, metrics, 5)
+	assert.Equal(t, gauge, metrics[0])
+	assert.Equal(t, map[string]string{"path": "/"}, metrics[1].Tags())
+	assert.Equal(t, map[string]interface{}{"sum": 3.5, "count": float64(12)},
+		metrics[1].Fields())
+	for i, bound := range []string{"0.05", "0.5", "+Inf"} {
+		m := metrics[i+2]
+		assert.Equal(t, "http_request_duration_seconds", m.Name())
+		assert.Equal(t, map[string]string{"path": "/", "le": bound}, m.Tags())
+		assert.Equal(t, telegraf.Untyped, m.Type())
+	}
+	assert.Equal(t, map[string]interface{}{"bucket": float64(10)}, metrics[3].Fields())
+
+	summary := Flatten([]telegraf.Metric{newHistogram(t, telegraf.Summary)})
+	require.Len(t, summary, 4)
+	assert.Equal(t, map[string]string{"path": "/", "quantile": "0.05"}, summary[1].Tags())
+	assert.Equal(t, map[string]interface{}{"value": float64(4)}, summary[1].Fields())
+}
+
+// the metrics are returned as is without histograms or summaries
+func TestFlattenNothing(t *testing.T) {
+	gauge, err := New("cpu", nil, map[string]interface{}{"usage": 1.0}, time.Unix(0, 0))
+	require.NoError(t, err)
+	metrics := []telegraf.Metric{gauge}
+	assert.Equal(t, metrics, Flatten(metrics))
+}
@@ -1,8 +1,10 @@
 package basicstats
 
 import (
+	"encoding/json"
 	"log"
 	"math"
+	"strconv"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/aggregators"
@@ -13,6 +15,9 @@ type BasicStats struct {
 
 	cache       map[uint64]aggregate
 	statsConfig *configuredStats
+
+	// state keeps the aggregates of the period across restarts
+	state telegraf.StateStore
 }
 
 type configuredStats struct {
@@ -44,6 +49,21 @@ type basicstats struct {
 	M2    float64 //intermedia value for variance/stdev
 }
 
+// saved is an aggregate as saved to the state store.
+type saved struct {
+	Name   string                `json:"name"`
+	Tags   map[string]string     `json:"tags"`
+	Fields map[string]savedstats `json:"fields"`
+}
+
+type savedstats struct {
+	Count float64 `json:"count"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Mean  float64 `json:"mean"`
+	M2    float64 `json:"m2"`
+}
+
 var sampleConfig = `
   ## General Aggregator Arguments:
   ## The period on which to flush & clear the aggregator.
@@ -124,6 +144,7 @@ func (m *BasicStats) Add(in telegraf.Metric) {
 			}
 		}
 	}
+	m.save(id)
 }
 
 func (m *BasicStats) Push(acc telegraf.Accumulator) {
@@ -226,6 +247,73 @@ func getConfiguredStats(m *BasicStats) *configuredStats {
 
 func (m *BasicStats) Reset() {
 	m.cache = make(map[uint64]aggregate)
+	if m.state != nil {
+		for _, key := range m.state.Keys() {
+			m.state.Delete(key)
+		}
+	}
+}
+
+// SetStateStore sets the store the aggregates of the period are kept in,
+// and restores those saved before a restart, which are then pushed with
+// the first period.
+func (m *BasicStats) SetStateStore(store telegraf.StateStore) {
+	m.state = store
+	for _, key := range store.Keys() {
+		id, err := strconv.ParseUint(key, 10, 64)
+		if err != nil {
+			continue
+		}
+		b, _ := store.Get(key)
+		var s saved
+		if err := json.Unmarshal(b, &s); err != nil {
+			log.Printf("E! Error loading the basicstats of %s from the state, %s", key, err)
+			continue
+		}
+		a := aggregate{
+			name:   s.Name,
+			tags:   s.Tags,
+			fields: make(map[string]basicstats),
+		}
+		for k, v := range s.Fields {
+			a.fields[k] = basicstats{
+				count: v.Count,
+				min:   v.Min,
+				max:   v.Max,
+				mean:  v.Mean,
+				M2:    v.M2,
+			}
+		}
+		m.cache[id] = a
+	}
+}
+
+// save saves the aggregate of a metric to the state store.
+func (m *BasicStats) save(id uint64) {
+	if m.state == nil {
+		return
+	}
+	a := m.cache[id]
+	s := saved{
+		Name:   a.name,
+		Tags:   a.tags,
+		Fields: make(map[string]savedstats),
+	}
+	for k, v := range a.fields {
+		s.Fields[k] = savedstats{
+			Count: v.count,
+			Min:   v.min,
+			Max:   v.max,
+			Mean:  v.mean,
+			M2:    v.M2,
+		}
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		log.Printf("E! Error saving the basicstats to the state, %s", err)
+		return
+	}
+	m.state.Set(strconv.FormatUint(id, 10), b)
 }
 
 func convert(in interface{}) (float64, bool) {
@@ -5,8 +5,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf/internal/state"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
 )
 
 var m1, _ = metric.New("m1",
@@ -87,6 +89,65 @@ func TestBasicStatsWithPeriod(t *testing.T) {
 	acc.AssertContainsTaggedFields(t, "m1", expectedFields, expectedTags)
 }
 
+// Test the aggregates of the period getting restored after a restart.
+func TestBasicStatsRestart(t *testing.T) {
+	st, err := state.Open("")
+	require.NoError(t, err)
+
+	minmax := NewBasicStats()
+	minmax.SetStateStore(st.Namespace("aggregators.basicstats"))
+	minmax.Add(m1)
+
+	acc := testutil.Accumulator{}
+	minmax = NewBasicStats()
+	minmax.SetStateStore(st.Namespace("aggregators.basicstats"))
+	minmax.Add(m2)
+	minmax.Push(&acc)
+
+	expectedFields := map[string]interface{}{
+		"a_count": float64(2), //a
+		"a_max":   float64(1),
+		"a_min":   float64(1),
+		"a_mean":  float64(1),
+		"a_stdev": float64(0),
+		"a_s2":    float64(0),
+		"b_count": float64(2), //b
+		"b_max":   float64(3),
+		"b_min":   float64(1),
+		"b_mean":  float64(2),
+		"b_s2":    float64(2),
+		"b_stdev": math.Sqrt(2),
+		"c_count": float64(2), //c
+		"c_max":   float64(4),
+		"c_min":   float64(2),
+		"c_mean":  float64(3),
+		"c_s2":    float64(2),
+		"c_stdev": math.Sqrt(2),
+		"d_count": float64(2), //d
+		"d_max":   float64(6),
+		"d_min":   float64(2),
+		"d_mean":  float64(4),
+		"d_s2":    float64(8),
+		"d_stdev": math.Sqrt(8),
+		"e_count": float64(1), //e
+		"e_max":   float64(200),
+		"e_min":   float64(200),
+		"e_mean":  float64(200),
+	}
+	expectedTags := map[string]string{
+		"foo": "bar",
+	}
+	acc.AssertContainsTaggedFields(t, "m1", expectedFields, expectedTags)
+
+	// the aggregates of a period are forgotten once it is pushed
+	minmax.Reset()
+	acc = testutil.Accumulator{}
+	minmax = NewBasicStats()
+	minmax.SetStateStore(st.Namespace("aggregators.basicstats"))
+	minmax.Push(&acc)
+	require.Len(t, acc.Metrics, 0)
+}
+
 // Test two metrics getting added with a push/reset in between (simulates
 // getting added in different periods.)
 func TestBasicStatsDifferentPeriods(t *testing.T) {
@@ -0,0 +1,254 @@
+package derivative
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+type Derivative struct {
+	Suffix        string            `toml:"suffix"`
+	Unit          internal.Duration `toml:"unit"`
+	CounterResets bool              `toml:"counter_resets"`
+
+	cache map[uint64]aggregate
+
+	// state keeps the last values of the fields across restarts
+	state telegraf.StateStore
+}
+
+func NewDerivative() *Derivative {
+	d := &Derivative{
+		Suffix:        "_rate",
+		Unit:          internal.Duration{Duration: time.Second},
+		CounterResets: true,
+	}
+	d.Reset()
+	return d
+}
+
+type aggregate struct {
+	fields map[string]*series
+	name   string
+	tags   map[string]string
+}
+
+// series is the change of a field over the period.
+type series struct {
+	first    sample
+	last     sample
+	increase float64 // sum of the changes, without the counter resets
+	updated  bool    // a value was added in the period
+}
+
+type sample struct {
+	value float64
+	time  time.Time
+}
+
+// saved is an aggregate as saved to the state store, with the last value
+// of each field.
+type saved struct {
+	Name   string                 `json:"name"`
+	Tags   map[string]string      `json:"tags"`
+	Fields map[string]savedsample `json:"fields"`
+}
+
+type savedsample struct {
+	Value float64   `json:"value"`
+	Time  time.Time `json:"time"`
+}
+
+var sampleConfig = `
+  ## General Aggregator Arguments:
+  ## The period on which to flush & clear the aggregator.
+  period = "30s"
+  ## If true, the original metric will be dropped by the
+  ## aggregator and will not get sent to the output plugins.
+  drop_original = false
+
+  ## Suffix of the rate fields, ie ifHCInOctets_rate.
+  # suffix = "_rate"
+
+  ## The rates are per unit of time.
+  # unit = "1s"
+
+  ## Treat a decrease of a value as a reset of the counter to zero, so the
+  ## rate is never negative. Disable it for the derivative of gauges.
+  # counter_resets = true
+`
+
+func (d *Derivative) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *Derivative) Description() string {
+	return "Compute the rate of change of each metric passing through."
+}
+
+func (d *Derivative) Add(in telegraf.Metric) {
+	id := in.HashID()
+	a, ok := d.cache[id]
+	if !ok {
+		// hit an uncached metric, create caches for first time:
+		a = aggregate{
+			name:   in.Name(),
+			tags:   in.Tags(),
+			fields: make(map[string]*series),
+		}
+		d.cache[id] = a
+	}
+
+	t := in.Time()
+	for k, v := range in.Fields() {
+		fv, ok := convert(v)
+		if !ok {
+			continue
+		}
+		s, ok := a.fields[k]
+		if !ok {
+			a.fields[k] = &series{
+				first:   sample{value: fv, time: t},
+				last:    sample{value: fv, time: t},
+				updated: true,
+			}
+			continue
+		}
+		if !t.After(s.last.time) {
+			// out of order or duplicate
+			continue
+		}
+		delta := fv - s.last.value
+		if delta < 0 && d.CounterResets {
+			// the counter restarted from zero
+			delta = fv
+		}
+		s.increase += delta
+		s.last = sample{value: fv, time: t}
+		s.updated = true
+	}
+}
+
+func (d *Derivative) Push(acc telegraf.Accumulator) {
+	unit := d.Unit.Duration
+	if unit <= 0 {
+		unit = time.Second
+	}
+	for _, aggregate := range d.cache {
+		fields := map[string]interface{}{}
+		for k, s := range aggregate.fields {
+			elapsed := s.last.time.Sub(s.first.time)
+			if elapsed <= 0 {
+				continue
+			}
+			fields[k+d.Suffix] = s.increase * float64(unit) / float64(elapsed)
+		}
+		if len(fields) > 0 {
+			acc.AddFields(aggregate.name, fields, aggregate.tags)
+		}
+	}
+}
+
+// Reset starts the next period from the last value of each field, so that
+// a rate is known after every period even with a single value per period.
+// The fields without values in the period are forgotten.
+func (d *Derivative) Reset() {
+	cache := make(map[uint64]aggregate)
+	for id, a := range d.cache {
+		fields := make(map[string]*series)
+		for k, s := range a.fields {
+			if !s.updated {
+				continue
+			}
+			fields[k] = &series{first: s.last, last: s.last}
+		}
+		if len(fields) > 0 {
+			a.fields = fields
+			cache[id] = a
+		}
+	}
+	d.cache = cache
+	d.save()
+}
+
+// SetStateStore sets the store the last values of the fields are kept in,
+// so the first period after a restart has a rate over the restart.
+func (d *Derivative) SetStateStore(store telegraf.StateStore) {
+	d.state = store
+	for _, key := range store.Keys() {
+		id, err := strconv.ParseUint(key, 10, 64)
+		if err != nil {
+			continue
+		}
+		b, _ := store.Get(key)
+		var s saved
+		if err := json.Unmarshal(b, &s); err != nil {
+			log.Printf("E! Error loading the derivative of %s from the state, %s", key, err)
+			continue
+		}
+		a := aggregate{
+			name:   s.Name,
+			tags:   s.Tags,
+			fields: make(map[string]*series),
+		}
+		for k, v := range s.Fields {
+			last := sample{value: v.Value, time: v.Time}
+			a.fields[k] = &series{first: last, last: last}
+		}
+		d.cache[id] = a
+	}
+}
+
+// save saves the last values of the fields to the state store, replacing
+// those of the previous period.
+func (d *Derivative) save() {
+	if d.state == nil {
+		return
+	}
+	keys := make(map[string]bool)
+	for id, a := range d.cache {
+		s := saved{
+			Name:   a.name,
+			Tags:   a.tags,
+			Fields: make(map[string]savedsample),
+		}
+		for k, v := range a.fields {
+			s.Fields[k] = savedsample{Value: v.last.value, Time: v.last.time}
+		}
+		b, err := json.Marshal(s)
+		if err != nil {
+			log.Printf("E! Error saving the derivative to the state, %s", err)
+			continue
+		}
+		key := strconv.FormatUint(id, 10)
+		d.state.Set(key, b)
+		keys[key] = true
+	}
+	for _, key := range d.state.Keys() {
+		if !keys[key] {
+			d.state.Delete(key)
+		}
+	}
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("derivative", func() telegraf.Aggregator {
+		return NewDerivative()
+	})
+}
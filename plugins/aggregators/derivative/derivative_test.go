@@ -0,0 +1,126 @@
+package derivative
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/state"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var start = time.Unix(1500000000, 0)
+
+func newMetric(t *testing.T, offset time.Duration, fields map[string]interface{}) telegraf.Metric {
+	m, err := metric.New("interface",
+		map[string]string{"ifName": "en0"},
+		fields,
+		start.Add(offset),
+	)
+	require.NoError(t, err)
+	return m
+}
+
+func TestRate(t *testing.T) {
+	acc := testutil.Accumulator{}
+	d := NewDerivative()
+
+	d.Add(newMetric(t, 0, map[string]interface{}{"in": int64(1000), "state": "up"}))
+	d.Add(newMetric(t, 10*time.Second, map[string]interface{}{"in": int64(1500)}))
+	d.Add(newMetric(t, 20*time.Second, map[string]interface{}{"in": int64(3000)}))
+	d.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]string{"ifName": "en0"}, acc.Metrics[0].Tags)
+	assert.Equal(t, map[string]interface{}{"in_rate": float64(100)}, acc.Metrics[0].Fields)
+}
+
+func TestCounterReset(t *testing.T) {
+	acc := testutil.Accumulator{}
+	d := NewDerivative()
+
+	d.Add(newMetric(t, 0, map[string]interface{}{"in": int64(1000)}))
+	d.Add(newMetric(t, 10*time.Second, map[string]interface{}{"in": int64(2000)}))
+	// the device restarted
+	d.Add(newMetric(t, 20*time.Second, map[string]interface{}{"in": int64(500)}))
+	d.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]interface{}{"in_rate": float64(75)}, acc.Metrics[0].Fields)
+}
+
+func TestGaugeDerivative(t *testing.T) {
+	acc := testutil.Accumulator{}
+	d := NewDerivative()
+	d.CounterResets = false
+	d.Suffix = "_per_minute"
+	d.Unit.Duration = time.Minute
+
+	d.Add(newMetric(t, 0, map[string]interface{}{"temp": float64(30)}))
+	d.Add(newMetric(t, 30*time.Second, map[string]interface{}{"temp": float64(29)}))
+	d.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]interface{}{"temp_per_minute": float64(-2)}, acc.Metrics[0].Fields)
+}
+
+// the last value of a period is the start of the next one
+func TestRateAcrossPeriods(t *testing.T) {
+	acc := testutil.Accumulator{}
+	d := NewDerivative()
+
+	d.Add(newMetric(t, 0, map[string]interface{}{"in": int64(0)}))
+	d.Push(&acc)
+	d.Reset()
+	assert.Len(t, acc.Metrics, 0)
+
+	d.Add(newMetric(t, 10*time.Second, map[string]interface{}{"in": int64(50)}))
+	d.Push(&acc)
+	d.Reset()
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]interface{}{"in_rate": float64(5)}, acc.Metrics[0].Fields)
+
+	// a period without values forgets the field
+	d.Push(&acc)
+	d.Reset()
+	assert.Len(t, acc.Metrics, 1)
+	assert.Len(t, d.cache, 0)
+}
+
+func TestOutOfOrder(t *testing.T) {
+	acc := testutil.Accumulator{}
+	d := NewDerivative()
+
+	d.Add(newMetric(t, 10*time.Second, map[string]interface{}{"in": int64(100)}))
+	d.Add(newMetric(t, 0, map[string]interface{}{"in": int64(0)}))
+	d.Add(newMetric(t, 20*time.Second, map[string]interface{}{"in": int64(200)}))
+	d.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]interface{}{"in_rate": float64(10)}, acc.Metrics[0].Fields)
+}
+
+// the last value before a restart is the start of the next period
+func TestRateAcrossRestart(t *testing.T) {
+	st, err := state.Open("")
+	require.NoError(t, err)
+
+	acc := testutil.Accumulator{}
+	d := NewDerivative()
+	d.SetStateStore(st.Namespace("aggregators.derivative"))
+	d.Add(newMetric(t, 0, map[string]interface{}{"in": int64(0)}))
+	d.Push(&acc)
+	d.Reset()
+
+	d = NewDerivative()
+	d.SetStateStore(st.Namespace("aggregators.derivative"))
+	d.Add(newMetric(t, 10*time.Second, map[string]interface{}{"in": int64(50)}))
+	d.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]interface{}{"in_rate": float64(5)}, acc.Metrics[0].Fields)
+}
@@ -0,0 +1,133 @@
+package percentile
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+var defaultPercentiles = []float64{50, 90, 95, 99}
+
+const defaultCompression = 100
+
+type Percentile struct {
+	Percentiles []float64 `toml:"percentiles"`
+	Compression float64   `toml:"compression"`
+
+	cache map[uint64]aggregate
+}
+
+func NewPercentile() *Percentile {
+	p := &Percentile{
+		Percentiles: defaultPercentiles,
+		Compression: defaultCompression,
+	}
+	p.Reset()
+	return p
+}
+
+type aggregate struct {
+	fields map[string]*digest
+	name   string
+	tags   map[string]string
+}
+
+var sampleConfig = `
+  ## General Aggregator Arguments:
+  ## The period on which to flush & clear the aggregator.
+  period = "30s"
+  ## If true, the original metric will be dropped by the
+  ## aggregator and will not get sent to the output plugins.
+  drop_original = false
+
+  ## Percentiles to push as fields, ie field_p95 for 95 or field_p99_9 for
+  ## 99.9
+  # percentiles = [50.0, 90.0, 95.0, 99.0]
+
+  ## Accuracy of the percentiles, a higher compression is more accurate but
+  ## keeps more data per field.
+  # compression = 100.0
+`
+
+func (p *Percentile) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Percentile) Description() string {
+	return "Keep the percentiles of each metric passing through."
+}
+
+func (p *Percentile) Add(in telegraf.Metric) {
+	id := in.HashID()
+	a, ok := p.cache[id]
+	if !ok {
+		// hit an uncached metric, create caches for first time:
+		a = aggregate{
+			name:   in.Name(),
+			tags:   in.Tags(),
+			fields: make(map[string]*digest),
+		}
+		p.cache[id] = a
+	}
+	for k, v := range in.Fields() {
+		fv, ok := convert(v)
+		if !ok {
+			continue
+		}
+		d, ok := a.fields[k]
+		if !ok {
+			compression := p.Compression
+			if compression <= 0 {
+				compression = defaultCompression
+			}
+			d = newDigest(compression)
+			a.fields[k] = d
+		}
+		d.Add(fv)
+	}
+}
+
+func (p *Percentile) Push(acc telegraf.Accumulator) {
+	for _, aggregate := range p.cache {
+		fields := map[string]interface{}{}
+		for k, d := range aggregate.fields {
+			for _, percentile := range p.Percentiles {
+				if percentile < 0 || percentile > 100 {
+					continue
+				}
+				fields[k+"_p"+suffix(percentile)] = d.Quantile(percentile / 100)
+			}
+		}
+		if len(fields) > 0 {
+			acc.AddFields(aggregate.name, fields, aggregate.tags)
+		}
+	}
+}
+
+func (p *Percentile) Reset() {
+	p.cache = make(map[uint64]aggregate)
+}
+
+// suffix returns the field suffix of a percentile, ie 99_9 for 99.9.
+func suffix(percentile float64) string {
+	return strings.Replace(strconv.FormatFloat(percentile, 'f', -1, 64), ".", "_", -1)
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("percentile", func() telegraf.Aggregator {
+		return NewPercentile()
+	})
+}
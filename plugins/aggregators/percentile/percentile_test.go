@@ -0,0 +1,119 @@
+package percentile
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestPercentile(t *testing.T) {
+	acc := testutil.Accumulator{}
+	p := NewPercentile()
+	p.Percentiles = []float64{0, 50, 99.9, 100}
+
+	for i := 1; i <= 1001; i++ {
+		m, err := metric.New("m1",
+			map[string]string{"foo": "bar"},
+			map[string]interface{}{
+				"a":        int64(i),
+				"b":        float64(-i),
+				"ignoreme": "string",
+			},
+			time.Now(),
+		)
+		require.NoError(t, err)
+		p.Add(m)
+	}
+	p.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	fields := acc.Metrics[0].Fields
+	assert.Equal(t, map[string]string{"foo": "bar"}, acc.Metrics[0].Tags)
+	assert.Len(t, fields, 8)
+	assert.Equal(t, float64(1), fields["a_p0"])
+	assert.Equal(t, float64(1001), fields["a_p100"])
+	assert.InDelta(t, 501, fields["a_p50"], 5)
+	assert.InDelta(t, 1000, fields["a_p99_9"], 1)
+	assert.InDelta(t, -501, fields["b_p50"], 5)
+	assert.Equal(t, float64(-1001), fields["b_p0"])
+}
+
+func TestPercentileReset(t *testing.T) {
+	acc := testutil.Accumulator{}
+	p := NewPercentile()
+
+	m, err := metric.New("m1", nil, map[string]interface{}{"a": float64(1)}, time.Now())
+	require.NoError(t, err)
+	p.Add(m)
+	p.Reset()
+	p.Push(&acc)
+	assert.Len(t, acc.Metrics, 0)
+
+	p.Add(m)
+	p.Push(&acc)
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]interface{}{
+		"a_p50": float64(1),
+		"a_p90": float64(1),
+		"a_p95": float64(1),
+		"a_p99": float64(1),
+	}, acc.Metrics[0].Fields)
+}
+
+// the error of the estimates is small compared to the exact percentiles
+func TestDigestAccuracy(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	d := newDigest(100)
+	values := make([]float64, 100000)
+	for i := range values {
+		values[i] = r.ExpFloat64()
+		d.Add(values[i])
+	}
+	sort.Float64s(values)
+
+	assert.True(t, len(d.centroids) < 1000, "%d centroids", len(d.centroids))
+	for _, q := range []float64{0.01, 0.1, 0.5, 0.9, 0.99, 0.999} {
+		exact := values[int(q*float64(len(values)))]
+		assert.InEpsilon(t, exact, d.Quantile(q), 0.02, "quantile %v", q)
+	}
+}
+
+func TestDigestMerge(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	all := newDigest(100)
+	d1, d2 := newDigest(100), newDigest(100)
+	for i := 0; i < 10000; i++ {
+		v := r.NormFloat64()
+		all.Add(v)
+		if i%2 == 0 {
+			d1.Add(v)
+		} else {
+			d2.Add(v)
+		}
+	}
+	d1.Merge(d2)
+
+	assert.Equal(t, all.count, d1.count)
+	assert.Equal(t, all.Quantile(0), d1.Quantile(0))
+	assert.Equal(t, all.Quantile(1), d1.Quantile(1))
+	for _, q := range []float64{0.05, 0.5, 0.95} {
+		assert.InDelta(t, all.Quantile(q), d1.Quantile(q), 0.02, "quantile %v", q)
+	}
+}
+
+func TestDigestEmpty(t *testing.T) {
+	assert.True(t, math.IsNaN(newDigest(100).Quantile(0.5)))
+}
+
+func TestSuffix(t *testing.T) {
+	assert.Equal(t, "95", suffix(95))
+	assert.Equal(t, "99_9", suffix(99.9))
+}
@@ -0,0 +1,139 @@
+package percentile
+
+import (
+	"math"
+	"sort"
+)
+
+// digest is a merging t-digest, a sketch of the distribution of the values
+// added to it with a bounded size. The centroids are small near the
+// extremes, so the high and low percentiles are accurate even when
+// millions of values are added. Two digests can be merged, the result is
+// about as accurate as if all the values were added to a single digest.
+type digest struct {
+	compression float64
+
+	centroids []centroid // sorted by mean
+	buffer    []centroid // not yet merged in the centroids
+	count     float64
+	min       float64
+	max       float64
+}
+
+type centroid struct {
+	mean  float64
+	count float64
+}
+
+func newDigest(compression float64) *digest {
+	return &digest{
+		compression: compression,
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}
+}
+
+// Add adds a single value.
+func (d *digest) Add(x float64) {
+	d.add(centroid{mean: x, count: 1})
+}
+
+// Merge adds the values of another digest.
+func (d *digest) Merge(other *digest) {
+	for _, c := range other.centroids {
+		d.add(c)
+	}
+	for _, c := range other.buffer {
+		d.add(c)
+	}
+}
+
+func (d *digest) add(c centroid) {
+	if math.IsNaN(c.mean) || c.count <= 0 {
+		return
+	}
+	d.buffer = append(d.buffer, c)
+	d.count += c.count
+	if c.mean < d.min {
+		d.min = c.mean
+	}
+	if c.mean > d.max {
+		d.max = c.mean
+	}
+	if len(d.buffer) >= int(5*d.compression) {
+		d.compress()
+	}
+}
+
+// compress merges the buffered values in the centroids. Neighbouring
+// centroids are merged as long as they hold less than 4*q*(1-q)/compression
+// of the values, q being their quantile.
+func (d *digest) compress() {
+	if len(d.buffer) == 0 {
+		return
+	}
+	all := append(d.centroids, d.buffer...)
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	merged := make([]centroid, 0, len(d.centroids)+1)
+	merged = append(merged, all[0])
+	var before float64 // values in the centroids before the last one
+	for _, c := range all[1:] {
+		last := &merged[len(merged)-1]
+		size := last.count + c.count
+		q0 := before / d.count
+		q2 := (before + size) / d.count
+		if size <= d.count*math.Min(d.maxSize(q0), d.maxSize(q2)) {
+			last.mean += (c.mean - last.mean) * c.count / size
+			last.count = size
+			continue
+		}
+		before += last.count
+		merged = append(merged, c)
+	}
+	d.centroids = merged
+	d.buffer = nil
+}
+
+func (d *digest) maxSize(q float64) float64 {
+	return 4 * q * (1 - q) / d.compression
+}
+
+// Quantile returns an estimate of the value below which a fraction q of the
+// values are, NaN when no value was added.
+func (d *digest) Quantile(q float64) float64 {
+	d.compress()
+	if len(d.centroids) == 0 {
+		return math.NaN()
+	}
+	if q <= 0 {
+		return d.min
+	}
+	if q >= 1 {
+		return d.max
+	}
+	if len(d.centroids) == 1 {
+		return d.centroids[0].mean
+	}
+
+	// the values of a centroid are spread around its mean, which is at the
+	// middle of its rank range
+	index := q * d.count
+	first := d.centroids[0]
+	if index < first.count/2 {
+		return d.min + (first.mean-d.min)*index/(first.count/2)
+	}
+	var rank float64
+	for i := 0; i < len(d.centroids)-1; i++ {
+		c, next := d.centroids[i], d.centroids[i+1]
+		lo := rank + c.count/2
+		hi := rank + c.count + next.count/2
+		if index < hi {
+			return c.mean + (next.mean-c.mean)*(index-lo)/(hi-lo)
+		}
+		rank += c.count
+	}
+	last := d.centroids[len(d.centroids)-1]
+	lo := d.count - last.count/2
+	return last.mean + (d.max-last.mean)*(index-lo)/(last.count/2)
+}
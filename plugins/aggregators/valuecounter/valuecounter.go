@@ -0,0 +1,184 @@
+package valuecounter
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+// otherValue is the value counting the values over the max_values limit.
+const otherValue = "other"
+
+type ValueCounter struct {
+	Fields    []string `toml:"fields"`
+	Tags      []string `toml:"tags"`
+	TopK      int      `toml:"top_k"`
+	MaxValues int      `toml:"max_values"`
+
+	cache map[uint64]aggregate
+}
+
+func NewValueCounter() *ValueCounter {
+	vc := &ValueCounter{
+		MaxValues: 1000,
+	}
+	vc.Reset()
+	return vc
+}
+
+type aggregate struct {
+	name   string
+	tags   map[string]string
+	counts map[string]map[string]int64 // count per value of each field or tag
+}
+
+var sampleConfig = `
+  ## General Aggregator Arguments:
+  ## The period on which to flush & clear the aggregator.
+  period = "30s"
+  ## If true, the original metric will be dropped by the
+  ## aggregator and will not get sent to the output plugins.
+  drop_original = false
+
+  ## The fields and tags whose values are counted, ie a status field or tag
+  ## gives the status_200 and status_404 fields.
+  fields = ["status"]
+  # tags = []
+
+  ## Only push the counts of the k most frequent values of each field or tag,
+  ## 0 pushes all of them.
+  # top_k = 0
+
+  ## Maximum number of distinct values counted for a field or tag per period,
+  ## the following values are counted as <field>_other.
+  # max_values = 1000
+`
+
+func (vc *ValueCounter) SampleConfig() string {
+	return sampleConfig
+}
+
+func (vc *ValueCounter) Description() string {
+	return "Count the occurrences of the values of fields and tags."
+}
+
+func (vc *ValueCounter) Add(in telegraf.Metric) {
+	tags := in.Tags()
+	counted := make(map[string]string)
+	for _, k := range vc.Tags {
+		if v, ok := tags[k]; ok {
+			counted[k] = v
+		}
+	}
+	fields := in.Fields()
+	for _, k := range vc.Fields {
+		if v, ok := fields[k]; ok {
+			counted[k] = fmt.Sprint(v)
+		}
+	}
+	if len(counted) == 0 {
+		return
+	}
+
+	// the counted tags are not part of the series of the counts
+	seriesTags := make(map[string]string, len(tags))
+	for k, v := range tags {
+		if !vc.isCountedTag(k) {
+			seriesTags[k] = v
+		}
+	}
+
+	id := hashID(in.Name(), seriesTags)
+	a, ok := vc.cache[id]
+	if !ok {
+		// hit an uncached metric, create caches for first time:
+		a = aggregate{
+			name:   in.Name(),
+			tags:   seriesTags,
+			counts: make(map[string]map[string]int64),
+		}
+		vc.cache[id] = a
+	}
+
+	for k, v := range counted {
+		counts, ok := a.counts[k]
+		if !ok {
+			counts = make(map[string]int64)
+			a.counts[k] = counts
+		}
+		if _, ok := counts[v]; !ok && vc.MaxValues > 0 && len(counts) >= vc.MaxValues {
+			v = otherValue
+		}
+		counts[v]++
+	}
+}
+
+func (vc *ValueCounter) Push(acc telegraf.Accumulator) {
+	for _, aggregate := range vc.cache {
+		fields := map[string]interface{}{}
+		for k, counts := range aggregate.counts {
+			for _, v := range vc.top(counts) {
+				fields[k+"_"+v] = counts[v]
+			}
+		}
+		acc.AddFields(aggregate.name, fields, aggregate.tags)
+	}
+}
+
+func (vc *ValueCounter) Reset() {
+	vc.cache = make(map[uint64]aggregate)
+}
+
+func (vc *ValueCounter) isCountedTag(key string) bool {
+	for _, k := range vc.Tags {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// top returns the top_k most frequent values, all of them if top_k is not
+// set. Values with the same count are in alphabetical order.
+func (vc *ValueCounter) top(counts map[string]int64) []string {
+	values := make([]string, 0, len(counts))
+	for v := range counts {
+		values = append(values, v)
+	}
+	if vc.TopK <= 0 || vc.TopK >= len(values) {
+		return values
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if counts[values[i]] != counts[values[j]] {
+			return counts[values[i]] > counts[values[j]]
+		}
+		return values[i] < values[j]
+	})
+	return values[:vc.TopK]
+}
+
+// hashID identifies a series like telegraf.Metric.HashID does.
+func hashID(name string, tags map[string]string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+
+	tmp := make([]string, 0, len(tags))
+	for k, v := range tags {
+		tmp = append(tmp, k+v)
+	}
+	sort.Strings(tmp)
+
+	for _, s := range tmp {
+		h.Write([]byte(s))
+	}
+	return h.Sum64()
+}
+
+func init() {
+	aggregators.Add("valuecounter", func() telegraf.Aggregator {
+		return NewValueCounter()
+	})
+}
@@ -0,0 +1,112 @@
+package valuecounter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newMetric(t *testing.T, tags map[string]string, fields map[string]interface{}) telegraf.Metric {
+	m, err := metric.New("http", tags, fields, time.Now())
+	require.NoError(t, err)
+	return m
+}
+
+func TestCountFieldValues(t *testing.T) {
+	acc := testutil.Accumulator{}
+	vc := NewValueCounter()
+	vc.Fields = []string{"status"}
+
+	for _, status := range []int64{200, 200, 404, 200, 500} {
+		vc.Add(newMetric(t, map[string]string{"host": "web01"},
+			map[string]interface{}{"status": status, "bytes": int64(10)}))
+	}
+	// metrics without the field are not counted
+	vc.Add(newMetric(t, map[string]string{"host": "web01"},
+		map[string]interface{}{"bytes": int64(10)}))
+	vc.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]string{"host": "web01"}, acc.Metrics[0].Tags)
+	assert.Equal(t, map[string]interface{}{
+		"status_200": int64(3),
+		"status_404": int64(1),
+		"status_500": int64(1),
+	}, acc.Metrics[0].Fields)
+}
+
+func TestCountTagValues(t *testing.T) {
+	acc := testutil.Accumulator{}
+	vc := NewValueCounter()
+	vc.Tags = []string{"level"}
+
+	vc.Add(newMetric(t, map[string]string{"app": "api", "level": "error"},
+		map[string]interface{}{"message": "timeout"}))
+	vc.Add(newMetric(t, map[string]string{"app": "api", "level": "warn"},
+		map[string]interface{}{"message": "slow"}))
+	vc.Add(newMetric(t, map[string]string{"app": "api", "level": "error"},
+		map[string]interface{}{"message": "refused"}))
+	vc.Push(&acc)
+
+	// the counted tag is not a tag of the counts
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]string{"app": "api"}, acc.Metrics[0].Tags)
+	assert.Equal(t, map[string]interface{}{
+		"level_error": int64(2),
+		"level_warn":  int64(1),
+	}, acc.Metrics[0].Fields)
+}
+
+func TestTopK(t *testing.T) {
+	acc := testutil.Accumulator{}
+	vc := NewValueCounter()
+	vc.Fields = []string{"error"}
+	vc.TopK = 2
+
+	for _, e := range []string{"a", "b", "c", "c", "b", "c", "d"} {
+		vc.Add(newMetric(t, nil, map[string]interface{}{"error": e}))
+	}
+	vc.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]interface{}{
+		"error_c": int64(3),
+		"error_b": int64(2),
+	}, acc.Metrics[0].Fields)
+}
+
+func TestMaxValues(t *testing.T) {
+	acc := testutil.Accumulator{}
+	vc := NewValueCounter()
+	vc.Fields = []string{"path"}
+	vc.MaxValues = 2
+
+	for _, p := range []string{"/a", "/b", "/c", "/a", "/d"} {
+		vc.Add(newMetric(t, nil, map[string]interface{}{"path": p}))
+	}
+	vc.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]interface{}{
+		"path_/a":    int64(2),
+		"path_/b":    int64(1),
+		"path_other": int64(2),
+	}, acc.Metrics[0].Fields)
+}
+
+func TestReset(t *testing.T) {
+	acc := testutil.Accumulator{}
+	vc := NewValueCounter()
+	vc.Fields = []string{"status"}
+
+	vc.Add(newMetric(t, nil, map[string]interface{}{"status": "ok"}))
+	vc.Reset()
+	vc.Push(&acc)
+	assert.Len(t, acc.Metrics, 0)
+}
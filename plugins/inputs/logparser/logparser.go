@@ -13,6 +13,8 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal/globpath"
+	"github.com/influxdata/telegraf/internal/multiline"
+	"github.com/influxdata/telegraf/internal/offsets"
 	"github.com/influxdata/telegraf/plugins/inputs"
 
 	// Parsers
@@ -32,6 +34,8 @@ type LogParser interface {
 type logEntry struct {
 	path string
 	line string
+	// offset is that of the end of the line
+	offset int64
 }
 
 // LogParserPlugin is the primary struct to implement the interface for logparser plugin
@@ -39,13 +43,23 @@ type LogParserPlugin struct {
 	Files         []string
 	FromBeginning bool
 	WatchMethod   string
+	Multiline     *multiline.Config `toml:"multiline"`
+	OffsetsFile   string
 
 	tailers map[string]*tail.Tail
 	lines   chan logEntry
+	// done is closed once the parser parsed the last lines
 	done    chan struct{}
 	wg      sync.WaitGroup
 	acc     telegraf.Accumulator
 	parsers []LogParser
+	matcher *multiline.Matcher
+	offsets *offsets.Store
+	state   telegraf.StateStore
+
+	// parsed are the offsets of the end of the last lines parsed
+	mu     sync.Mutex
+	parsed map[string]int64
 
 	sync.Mutex
 
@@ -66,9 +80,33 @@ const sampleConfig = `
   ## be read from the beginning.
   from_beginning = false
 
+  ## File to save the offsets of the files to, so they are read from where
+  ## they were left after a restart, instead of from the beginning or the
+  ## end. The offsets are saved every interval and when telegraf stops.
+  ## When not set, the offsets are kept in the state_directory of the agent.
+  # offsets_file = "/var/lib/telegraf/logparser_offsets.json"
+
   ## Method used to watch for file updates.  Can be either "inotify" or "poll".
   # watch_method = "inotify"
 
+  ## Join the lines of multiline events, like stack traces, with newlines
+  ## before parsing them. A line continues the current event when it matches
+  ## any of the set conditions, and starts a new event otherwise. Use the (?s)
+  ## flag in the patterns for . to match the newlines.
+  # [inputs.logparser.multiline]
+  #   ## Pattern of the first line of the events, the other lines continue
+  #   ## the current event.
+  #   start = '^\d{4}-\d{2}-\d{2}'
+  #   ## Pattern of the lines continuing the current event.
+  #   continuation = '^\s+at '
+  #   ## Continue the current event with the lines starting with a space or
+  #   ## a tab.
+  #   indented = false
+  #   ## Time after which the last event is parsed when no line follows it.
+  #   timeout = "5s"
+  #   ## Maximum number of lines of an event.
+  #   max_lines = 1000
+
   ## Parse logstash-style "grok" patterns:
   ##   Telegraf built-in parsing patterns: https://goo.gl/dkay10
   [inputs.logparser.grok]
@@ -112,11 +150,23 @@ func (l *LogParserPlugin) Description() string {
 	return "Stream and parse log file(s)."
 }
 
+func (l *LogParserPlugin) SetStateStore(store telegraf.StateStore) {
+	l.state = store
+}
+
 // Gather is the primary function to collect the metrics for the plugin
 func (l *LogParserPlugin) Gather(acc telegraf.Accumulator) error {
 	l.Lock()
 	defer l.Unlock()
 
+	if l.offsets != nil {
+		l.setOffsets()
+		if err := l.offsets.Save(); err != nil {
+			acc.AddError(fmt.Errorf("E! Error saving the offsets to %s, %s",
+				l.OffsetsFile, err))
+		}
+	}
+
 	// always start from the beginning of files that appear while we're running
 	return l.tailNewfiles(true)
 }
@@ -130,6 +180,7 @@ func (l *LogParserPlugin) Start(acc telegraf.Accumulator) error {
 	l.lines = make(chan logEntry, 1000)
 	l.done = make(chan struct{})
 	l.tailers = make(map[string]*tail.Tail)
+	l.parsed = make(map[string]int64)
 
 	// Looks for fields which implement LogParser interface
 	l.parsers = []LogParser{}
@@ -160,7 +211,25 @@ func (l *LogParserPlugin) Start(acc telegraf.Accumulator) error {
 		}
 	}
 
-	l.wg.Add(1)
+	var err error
+	l.matcher, err = l.Multiline.NewMatcher()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case l.OffsetsFile != "":
+		l.offsets, err = offsets.Open(l.OffsetsFile)
+		if err != nil {
+			return fmt.Errorf("E! Error loading the offsets of %s, %s", l.OffsetsFile, err)
+		}
+	case l.state != nil:
+		l.offsets, err = offsets.Load(l.state)
+		if err != nil {
+			return fmt.Errorf("E! Error loading the offsets from the state, %s", err)
+		}
+	}
+
 	go l.parser()
 
 	return l.tailNewfiles(l.FromBeginning)
@@ -195,11 +264,17 @@ func (l *LogParserPlugin) tailNewfiles(fromBeginning bool) error {
 				continue
 			}
 
+			location, offset := &seek, int64(0)
+			if l.offsets != nil {
+				location, offset = l.offsets.Location(file, fromBeginning)
+				l.setParsed(file, offset)
+			}
+
 			tailer, err := tail.TailFile(file,
 				tail.Config{
 					ReOpen:    true,
 					Follow:    true,
-					Location:  &seek,
+					Location:  location,
 					MustExist: true,
 					Poll:      poll,
 					Logger:    tail.DiscardingLogger,
@@ -211,7 +286,7 @@ func (l *LogParserPlugin) tailNewfiles(fromBeginning bool) error {
 
 			// create a goroutine for each "tailer"
 			l.wg.Add(1)
-			go l.receiver(tailer)
+			go l.receiver(tailer, offset)
 			l.tailers[file] = tailer
 		}
 	}
@@ -220,51 +295,75 @@ func (l *LogParserPlugin) tailNewfiles(fromBeginning bool) error {
 }
 
 // receiver is launched as a goroutine to continuously watch a tailed logfile
-// for changes and send any log lines down the l.lines channel.
-func (l *LogParserPlugin) receiver(tailer *tail.Tail) {
+// for changes and send any log lines down the l.lines channel. The offset is
+// that of the end of the lines received, from the start offset.
+func (l *LogParserPlugin) receiver(tailer *tail.Tail, offset int64) {
 	defer l.wg.Done()
 
-	var line *tail.Line
-	for line = range tailer.Lines {
+	// the lines of multiline events are buffered until the next event starts
+	buf := l.matcher.NewBuffer()
+	for {
+		select {
+		case line, ok := <-tailer.Lines:
+			if !ok {
+				if event, ok := buf.Flush(); ok {
+					l.send(tailer, event, offset)
+				}
+				return
+			}
 
-		if line.Err != nil {
-			log.Printf("E! Error tailing file %s, Error: %s\n",
-				tailer.Filename, line.Err)
-			continue
-		}
+			if line.Err != nil {
+				log.Printf("E! Error tailing file %s, Error: %s\n",
+					tailer.Filename, line.Err)
+				continue
+			}
 
-		// Fix up files with Windows line endings.
-		text := strings.TrimRight(line.Text, "\r")
+			// the event returned by Add ends before the line, unless the
+			// lines are not joined
+			end := offset
+			if l.offsets != nil {
+				offset = offsets.Next(tailer, offset, line.Text)
+			}
+			if l.matcher == nil {
+				end = offset
+			}
 
-		entry := logEntry{
-			path: tailer.Filename,
-			line: text,
-		}
+			// Fix up files with Windows line endings.
+			text := strings.TrimRight(line.Text, "\r")
 
-		select {
-		case <-l.done:
-		case l.lines <- entry:
+			if event, ok := buf.Add(text); ok {
+				l.send(tailer, event, end)
+			}
+		case <-buf.Timeout():
+			if event, ok := buf.Flush(); ok {
+				l.send(tailer, event, offset)
+			}
 		}
 	}
 }
 
+// send sends a line, or a multiline event, ending at offset down the l.lines
+// channel.
+func (l *LogParserPlugin) send(tailer *tail.Tail, text string, offset int64) {
+	l.lines <- logEntry{
+		path:   tailer.Filename,
+		line:   text,
+		offset: offset,
+	}
+}
+
 // parser is launched as a goroutine to watch the l.lines channel.
 // when a line is available, parser parses it and adds the metric(s) to the
 // accumulator.
 func (l *LogParserPlugin) parser() {
-	defer l.wg.Done()
+	defer close(l.done)
 
 	var m telegraf.Metric
 	var err error
-	var entry logEntry
-	for {
-		select {
-		case <-l.done:
-			return
-		case entry = <-l.lines:
-			if entry.line == "" || entry.line == "\n" {
-				continue
-			}
+	for entry := range l.lines {
+		if entry.line == "" || entry.line == "\n" {
+			l.setParsed(entry.path, entry.offset)
+			continue
 		}
 		for _, parser := range l.parsers {
 			m, err = parser.ParseLine(entry.line)
@@ -278,9 +377,20 @@ func (l *LogParserPlugin) parser() {
 				log.Println("E! Error parsing log line: " + err.Error())
 			}
 		}
+		l.setParsed(entry.path, entry.offset)
 	}
 }
 
+// setParsed sets the offset of the end of the last line parsed from a file.
+func (l *LogParserPlugin) setParsed(file string, offset int64) {
+	if l.offsets == nil {
+		return
+	}
+	l.mu.Lock()
+	l.parsed[file] = offset
+	l.mu.Unlock()
+}
+
 // Stop will end the metrics collection process on file tailers
 func (l *LogParserPlugin) Stop() {
 	l.Lock()
@@ -293,8 +403,31 @@ func (l *LogParserPlugin) Stop() {
 		}
 		t.Cleanup()
 	}
-	close(l.done)
 	l.wg.Wait()
+	close(l.lines)
+	<-l.done
+
+	// the offsets are taken once the parser parsed the lines read
+	l.setOffsets()
+	if l.offsets != nil {
+		if err := l.offsets.Save(); err != nil {
+			log.Printf("E! Error saving the offsets to %s, %s\n", l.OffsetsFile, err)
+		}
+	}
+}
+
+// setOffsets sets the offsets of the tailed files to the end of the lines
+// parsed, the lines still queued or buffered are read again after a restart.
+// Assumes l's lock is held!
+func (l *LogParserPlugin) setOffsets() {
+	if l.offsets == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for file, offset := range l.parsed {
+		l.offsets.Set(file, offset)
+	}
 }
 
 func init() {
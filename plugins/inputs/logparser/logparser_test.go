@@ -6,7 +6,11 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/multiline"
+	"github.com/influxdata/telegraf/internal/offsets"
 	"github.com/influxdata/telegraf/testutil"
 
 	"github.com/influxdata/telegraf/plugins/inputs/logparser/grok"
@@ -162,6 +166,136 @@ func TestGrokParseLogFilesOneBad(t *testing.T) {
 		})
 }
 
+func TestGrokParseLogFilesMultiline(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	assert.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	_, err = tmpfile.WriteString(`2018-02-06 12:00:01 INFO starting
+2018-02-06 12:00:02 ERROR failed
+java.lang.IllegalStateException: failed
+	at com.example.Main.run(Main.java:42)
+2018-02-06 12:00:03 INFO retrying
+`)
+	assert.NoError(t, err)
+	tmpfile.Close()
+
+	p := &grok.Parser{
+		Patterns: []string{`%{NOTSPACE:date} %{NOTSPACE:time} %{WORD:level:tag} (?s)%{GREEDYDATA:message}`},
+	}
+
+	logparser := &LogParserPlugin{
+		FromBeginning: true,
+		Files:         []string{tmpfile.Name()},
+		GrokParser:    p,
+		Multiline: &multiline.Config{
+			Start:   `^\d{4}-\d{2}-\d{2} `,
+			Timeout: internal.Duration{Duration: 100 * time.Millisecond},
+		},
+	}
+
+	acc := testutil.Accumulator{}
+	assert.NoError(t, logparser.Start(&acc))
+
+	// the last event is parsed on timeout
+	acc.Wait(3)
+	logparser.Stop()
+
+	messages := make(map[string]string)
+	for _, m := range acc.Metrics {
+		messages[m.Fields["time"].(string)] = m.Fields["message"].(string)
+	}
+	assert.Equal(t, map[string]string{
+		"12:00:01": "starting",
+		"12:00:02": "failed\n" +
+			"java.lang.IllegalStateException: failed\n" +
+			"\tat com.example.Main.run(Main.java:42)",
+		"12:00:03": "retrying",
+	}, messages)
+}
+
+func TestGrokParseLogFilesOffsets(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	assert.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	defer tmpfile.Close()
+	_, err = tmpfile.WriteString("first 1\n")
+	assert.NoError(t, err)
+
+	offsetsFile := tmpfile.Name() + ".offsets"
+	defer os.Remove(offsetsFile)
+
+	newLogParser := func() *LogParserPlugin {
+		return &LogParserPlugin{
+			Files: []string{tmpfile.Name()},
+			GrokParser: &grok.Parser{
+				Patterns: []string{"%{WORD:word} %{NUMBER:value:int}"},
+			},
+			OffsetsFile: offsetsFile,
+		}
+	}
+
+	logparser := newLogParser()
+	logparser.FromBeginning = true
+	acc := testutil.Accumulator{}
+	assert.NoError(t, logparser.Start(&acc))
+	acc.Wait(1)
+	logparser.Stop()
+
+	// the line written while stopped is read from the saved offset
+	_, err = tmpfile.WriteString("second 2\n")
+	assert.NoError(t, err)
+
+	logparser = newLogParser()
+	acc = testutil.Accumulator{}
+	assert.NoError(t, logparser.Start(&acc))
+	acc.Wait(1)
+	logparser.Stop()
+
+	assert.Len(t, acc.Metrics, 1)
+	acc.AssertContainsFields(t, "logparser_grok",
+		map[string]interface{}{
+			"word":  "second",
+			"value": int64(2),
+		})
+}
+
+func TestGrokParseLogFilesOffsetsBuffered(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	assert.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	defer tmpfile.Close()
+	_, err = tmpfile.WriteString("first 1\nsecond 2\n")
+	assert.NoError(t, err)
+
+	offsetsFile := tmpfile.Name() + ".offsets"
+	defer os.Remove(offsetsFile)
+
+	logparser := &LogParserPlugin{
+		Files:         []string{tmpfile.Name()},
+		FromBeginning: true,
+		GrokParser: &grok.Parser{
+			Patterns: []string{"%{WORD:word} %{NUMBER:value:int}"},
+		},
+		OffsetsFile: offsetsFile,
+		Multiline: &multiline.Config{
+			Indented: true,
+			Timeout:  internal.Duration{Duration: time.Hour},
+		},
+	}
+	acc := testutil.Accumulator{}
+	assert.NoError(t, logparser.Start(&acc))
+	defer logparser.Stop()
+	acc.Wait(1)
+	assert.NoError(t, acc.GatherError(logparser.Gather))
+
+	// the saved offset is not ahead of the buffered event
+	saved, err := offsets.Open(offsetsFile)
+	assert.NoError(t, err)
+	offset, ok := saved.Resume(tmpfile.Name())
+	assert.True(t, ok)
+	assert.Equal(t, int64(len("first 1\n")), offset)
+}
+
 func getCurrentDir() string {
 	_, filename, _, _ := runtime.Caller(1)
 	return strings.Replace(filename, "logparser_test.go", "", 1)
@@ -1,9 +1,13 @@
 package nmon
 
 import (
+	"time"
+
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	nmonparser "github.com/influxdata/telegraf/plugins/parsers/nmon"
 )
 
 func init() {
@@ -13,6 +17,11 @@ func init() {
 //
 var simpleConfig = `
 	# mode: http/socket/proxy, default value: http
+	#   http:   reporters POST nmon snapshots to http://<listen>/metrics
+	#   socket: reporters stream nmon lines over a raw tcp connection, the
+	#           first line is the baseinfo line and every snapshot starts
+	#           with its ZZZZ line
+	#   proxy:  like http, and every report is forwarded to upstream
 	mode = "http"
 	
 	# ip address and port will be listened, default value: 0.0.0.0:12345
@@ -20,23 +29,38 @@ var simpleConfig = `
 	
 	# data client report is increment part or full, values can be increment
 	# or full, default value: increment
-	report_mode = increment
+	report_mode = "increment"
 	
-	# format , default value: nmon
+	# format, only nmon is supported, its options, ie nmon_timezone, are
+	# described in docs/DATA_FORMATS_INPUT.md
 	data_format = "nmon"
 	
-	# http mode, options you could set.
+	# http and proxy mode, options you could set.
 	# max body size, unit: byte, default value: 100*1024*1024
 	max_body_size = 100000
+
+	# socket mode, options you could set.
+	# close reporter connections idle for longer than read_timeout,
+	# default value: 5m
+	# read_timeout = "5m"
+
+	# proxy mode, options you must set.
+	# nmon endpoint every received report is forwarded to
+	# upstream = "http://10.0.0.1:12345/metrics"
+	# timeout of a forward request, default value: 10s
+	# upstream_timeout = "10s"
 `
 
 // configuration for nmon server
 type config struct {
-	Mode        string `toml:"mode"`
-	Listen      string `toml:"listen"`
-	ReportMode  string `toml:"report_mode"`
-	DataFormat  string `toml:"data_format"`
-	MaxBodySize int64  `toml:"max_body_size"`
+	Mode            string
+	Listen          string
+	ReportMode      string
+	Parser          *nmonparser.Parser
+	MaxBodySize     int64
+	ReadTimeout     time.Duration
+	Upstream        string
+	UpstreamTimeout time.Duration
 }
 
 //
@@ -44,17 +68,21 @@ type NmonServer struct {
 	Mode        string `toml:"mode"`
 	Listen      string `toml:"listen"`
 	ReportMode  string `toml:"report_mode"`
-	DataFormat  string `toml:"data_format"`
 	MaxBodySize int64  `toml:"max_body_size"`
 
-	srv server
+	ReadTimeout     internal.Duration `toml:"read_timeout"`
+	Upstream        string            `toml:"upstream"`
+	UpstreamTimeout internal.Duration `toml:"upstream_timeout"`
+
+	parser *nmonparser.Parser
+	srv    server
 }
 
 func newNmonServer() telegraf.Input {
 	return &NmonServer{
-		Mode:       httpMode,
-		Listen:     "0.0.0.0:12345",
-		DataFormat: "nmon",
+		Mode:   httpMode,
+		Listen: "0.0.0.0:12345",
+		parser: &nmonparser.Parser{},
 	}
 }
 
@@ -73,8 +101,12 @@ func (p *NmonServer) Gather(_ telegraf.Accumulator) error {
 	return nil
 }
 
-//
-func (p *NmonServer) SetParser(_ parsers.Parser) {
+// SetParser keeps the parser of the nmon data format, the reports can not
+// be parsed with the other data formats.
+func (p *NmonServer) SetParser(parser parsers.Parser) {
+	if parser, ok := parser.(*nmonparser.Parser); ok {
+		p.parser = parser
+	}
 }
 
 //
@@ -83,8 +115,11 @@ func (p *NmonServer) Start(acc telegraf.Accumulator) error {
 	cfg.Listen = p.Listen
 	cfg.Mode = p.Mode
 	cfg.ReportMode = p.ReportMode
-	cfg.DataFormat = p.DataFormat
+	cfg.Parser = p.parser
 	cfg.MaxBodySize = p.MaxBodySize
+	cfg.ReadTimeout = p.ReadTimeout.Duration
+	cfg.Upstream = p.Upstream
+	cfg.UpstreamTimeout = p.UpstreamTimeout.Duration
 	p.srv = newServer(cfg)
 	p.srv.SetAccumulator(acc)
 	return p.srv.Start()
@@ -1,26 +1,106 @@
 package nmon
 
 import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
 	"github.com/influxdata/telegraf"
 )
 
-//
+const (
+	// DEFAULT_UPSTREAM_TIMEOUT is the default timeout of a request to the
+	// upstream nmon endpoint.
+	DEFAULT_UPSTREAM_TIMEOUT = 10 * time.Second
+
+	// number of received reports waiting to be forwarded upstream, further
+	// reports are dropped while the upstream endpoint is slow or unreachable.
+	forwardQueueSize = 100
+)
+
+// proxyServer receives nmon data like the restful api server, and forwards
+// every received report unchanged to an upstream nmon endpoint, for example
+// the restful api of another nmon input.
 type proxyServer struct {
-	cfgs config
-	acc  telegraf.Accumulator
+	*restfulApiServer
+
+	client *http.Client
+	queue  chan []byte
+	wg     sync.WaitGroup
 }
 
-//
-func (p *proxyServer) Start(cfg config) error {
-	return nil
+// create a new proxy server
+func newProxyServer(cfg config) *proxyServer {
+	if cfg.UpstreamTimeout == 0 {
+		cfg.UpstreamTimeout = DEFAULT_UPSTREAM_TIMEOUT
+	}
+	p := &proxyServer{
+		restfulApiServer: newRestfulApiServer(cfg),
+		client:           &http.Client{Timeout: cfg.UpstreamTimeout},
+		queue:            make(chan []byte, forwardQueueSize),
+	}
+	p.restfulApiServer.forward = p.enqueue
+	return p
 }
 
-//
+// start the http server and the forwarder
+func (p *proxyServer) Start() error {
+	if p.cfgs.Upstream == "" {
+		return fmt.Errorf("nmon proxy mode requires an upstream endpoint")
+	}
+
+	p.wg.Add(1)
+	go p.forwarder()
+	return p.restfulApiServer.Start()
+}
+
+// stop the http server, and forward the reports already received
 func (p *proxyServer) Stop() error {
-	return nil
+	err := p.restfulApiServer.Stop()
+	close(p.queue)
+	p.wg.Wait()
+	return err
 }
 
 //
 func (p *proxyServer) SetAccumulator(acc telegraf.Accumulator) {
-	p.acc = acc
+	p.restfulApiServer.SetAccumulator(acc)
+}
+
+// enqueue queues a received report for forwarding
+func (p *proxyServer) enqueue(body []byte) {
+	select {
+	case p.queue <- body:
+	default:
+		log.Printf("W! nmon proxy: forward queue to %s is full, dropping report",
+			p.cfgs.Upstream)
+	}
+}
+
+// forwarder sends the queued reports to the upstream endpoint
+func (p *proxyServer) forwarder() {
+	defer p.wg.Done()
+	for body := range p.queue {
+		if err := p.send(body); err != nil {
+			p.acc.AddError(fmt.Errorf("nmon proxy: forwarding to %s failed: %s",
+				p.cfgs.Upstream, err))
+		}
+	}
+}
+
+//
+func (p *proxyServer) send(body []byte) error {
+	resp, err := p.client.Post(p.cfgs.Upstream, "text/plain", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("upstream returned status %s", resp.Status)
+	}
+	return nil
 }
@@ -1,12 +1,14 @@
 package nmon
 
 import (
+	"context"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"strings"
 
 	"github.com/influxdata/telegraf"
+	nmonparser "github.com/influxdata/telegraf/plugins/parsers/nmon"
 )
 
 // endpoints
@@ -35,10 +37,16 @@ type restfulApiServer struct {
 	mux  *http.ServeMux
 	srv  *http.Server
 	acc  telegraf.Accumulator
+
+	// forward, when set, receives the body of every report
+	forward func([]byte)
 }
 
 // create a new restful api server
 func newRestfulApiServer(cfg config) *restfulApiServer {
+	if cfg.Parser == nil {
+		cfg.Parser = &nmonparser.Parser{}
+	}
 	return &restfulApiServer{
 		cfgs: cfg,
 		mux:  http.NewServeMux(),
@@ -55,7 +63,7 @@ func (p *restfulApiServer) Start() error {
 	p.mux.Handle(endpoint, p)
 	p.srv.Handler = p.mux
 	go func(acc telegraf.Accumulator) {
-		if err := p.srv.ListenAndServe(); err != nil {
+		if err := p.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			acc.AddError(err)
 		}
 	}(p.acc)
@@ -64,7 +72,7 @@ func (p *restfulApiServer) Start() error {
 
 // stop the restful api server
 func (p *restfulApiServer) Stop() error {
-	return p.srv.Shutdown(nil)
+	return p.srv.Shutdown(context.Background())
 }
 
 //
@@ -86,7 +94,11 @@ func (p *restfulApiServer) ServeHTTP(rw http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	err = processData(p.acc, strings.Split(req.RemoteAddr, ":")[0], p.cfgs.DataFormat, body)
+	if p.forward != nil {
+		p.forward(body)
+	}
+
+	err = processData(p.acc, p.cfgs.Parser, strings.Split(req.RemoteAddr, ":")[0], body)
 	if err != nil {
 		log.Println(err)
 		rw.Write(processBodyError)
@@ -26,7 +26,9 @@ func newServer(cfg config) server {
 	var srv server
 	switch strings.ToLower(cfg.Mode) {
 	case socketMode:
+		srv = newSocketServer(cfg)
 	case proxyMode:
+		srv = newProxyServer(cfg)
 	default:
 		srv = newRestfulApiServer(cfg)
 	}
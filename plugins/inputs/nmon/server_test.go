@@ -0,0 +1,124 @@
+package nmon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testBaseInfo = "NET=NET,Network I/O lpar1,en0-read-KB/s,en0-write-KB/s"
+	testReport   = testBaseInfo + `
+ZZZZ,T0001,10:00:00,01-JAN-2018
+CPU_ALL,T0001,10.1,2.0,0.5,87.4,,4
+`
+)
+
+func TestSocketServerSnapshots(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	srv := newSocketServer(config{Listen: "127.0.0.1:0"})
+	srv.SetAccumulator(acc)
+	require.NoError(t, srv.Start())
+	defer srv.Stop()
+
+	conn, err := net.Dial("tcp", srv.listener.Addr().String())
+	require.NoError(t, err)
+
+	fmt.Fprintln(conn, testBaseInfo)
+	fmt.Fprintln(conn, "ZZZZ,T0001,10:00:00,01-JAN-2018")
+	fmt.Fprintln(conn, "CPU_ALL,T0001,10.1,2.0,0.5,87.4,,4")
+	// the first snapshot is processed when the second one starts
+	fmt.Fprintln(conn, "ZZZZ,T0002,10:00:10,01-JAN-2018")
+	acc.Wait(1)
+	assert.Equal(t, uint64(1), acc.NMetrics())
+	assert.True(t, acc.HasPoint("nmon_CPU_ALL",
+		map[string]string{"ip": "127.0.0.1", "object": "CPU_ALL"}, "user", 10.1))
+
+	// and the last one when the connection is closed
+	fmt.Fprintln(conn, "CPU_ALL,T0002,20.2,2.0,0.5,77.3,,4")
+	conn.Close()
+	acc.Wait(2)
+	assert.True(t, acc.HasPoint("nmon_CPU_ALL",
+		map[string]string{"ip": "127.0.0.1", "object": "CPU_ALL"}, "user", 20.2))
+}
+
+func TestSocketServerStopClosesConnections(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	srv := newSocketServer(config{Listen: "127.0.0.1:0"})
+	srv.SetAccumulator(acc)
+	require.NoError(t, srv.Start())
+
+	conn, err := net.Dial("tcp", srv.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	fmt.Fprintln(conn, testBaseInfo)
+
+	// wait for the connection to be accepted before stopping
+	for i := 0; i < 100; i++ {
+		srv.mu.Lock()
+		n := len(srv.conns)
+		srv.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// stop must not wait for the idle reporter
+	done := make(chan error)
+	go func() { done <- srv.Stop() }()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("stop blocked on an open connection")
+	}
+	assert.Empty(t, acc.Errors)
+}
+
+func TestProxyServerForwards(t *testing.T) {
+	received := make(chan string, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received <- string(body)
+	}))
+	defer upstream.Close()
+
+	acc := &testutil.Accumulator{}
+	srv := newProxyServer(config{Listen: "127.0.0.1:0", Upstream: upstream.URL})
+	srv.SetAccumulator(acc)
+	require.NoError(t, srv.Start())
+
+	req := httptest.NewRequest("POST", endpoint, strings.NewReader(testReport))
+	req.RemoteAddr = "10.0.0.1:4567"
+	rw := httptest.NewRecorder()
+	srv.ServeHTTP(rw, req)
+	assert.Equal(t, string(okStatus), rw.Body.String())
+
+	select {
+	case body := <-received:
+		assert.Equal(t, testReport, body)
+	case <-time.After(5 * time.Second):
+		t.Fatal("report was not forwarded upstream")
+	}
+	require.NoError(t, srv.Stop())
+
+	assert.True(t, acc.HasPoint("nmon_CPU_ALL",
+		map[string]string{"ip": "10.0.0.1", "object": "CPU_ALL"}, "user", 10.1))
+}
+
+func TestProxyServerRequiresUpstream(t *testing.T) {
+	srv := newProxyServer(config{Listen: "127.0.0.1:0"})
+	srv.SetAccumulator(&testutil.Accumulator{})
+	assert.Error(t, srv.Start())
+}
@@ -1,26 +1,182 @@
 package nmon
 
 import (
+	"bufio"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/influxdata/telegraf"
+	nmonparser "github.com/influxdata/telegraf/plugins/parsers/nmon"
+)
+
+const (
+	// DEFAULT_READ_TIMEOUT is the default time a reporter connection may stay
+	// idle before it is closed.
+	DEFAULT_READ_TIMEOUT = 5 * time.Minute
+
+	// maximum length of a single nmon line, the baseinfo line of hosts with
+	// many disks can be very long.
+	maxLineSize = 1024 * 1024
 )
 
+// socketServer accepts nmon data streamed over raw tcp connections.
 //
+// Every connection starts with the baseinfo line, followed by the nmon
+// lines of one or more snapshots. Each snapshot begins with its ZZZZ line
+// and is processed when the next snapshot begins or the connection is
+// closed. A new baseinfo line replaces the previous one for the following
+// snapshots.
 type socketServer struct {
-	cfgs config
-	acc  telegraf.Accumulator
+	cfgs     config
+	acc      telegraf.Accumulator
+	listener net.Listener
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
 }
 
-//
-func (p *socketServer) Start(cfg config) error {
+// create a new raw socket server
+func newSocketServer(cfg config) *socketServer {
+	if cfg.Parser == nil {
+		cfg.Parser = &nmonparser.Parser{}
+	}
+	return &socketServer{
+		cfgs:  cfg,
+		conns: make(map[net.Conn]struct{}),
+	}
+}
+
+// start listening for reporter connections
+func (p *socketServer) Start() error {
+	if p.cfgs.ReadTimeout == 0 {
+		p.cfgs.ReadTimeout = DEFAULT_READ_TIMEOUT
+	}
+
+	listener, err := net.Listen("tcp", p.cfgs.Listen)
+	if err != nil {
+		return err
+	}
+	p.listener = listener
+	log.Printf("I! Started nmon socket server on %s", listener.Addr())
+
+	p.wg.Add(1)
+	go p.accept()
 	return nil
 }
 
-//
+// stop listening and close all reporter connections
 func (p *socketServer) Stop() error {
-	return nil
+	if p.listener == nil {
+		return nil
+	}
+	err := p.listener.Close()
+
+	p.mu.Lock()
+	for conn := range p.conns {
+		conn.Close()
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+	return err
 }
 
 //
 func (p *socketServer) SetAccumulator(acc telegraf.Accumulator) {
 	p.acc = acc
 }
+
+// accept reporter connections until the listener is closed
+func (p *socketServer) accept() {
+	defer p.wg.Done()
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			if !isClosedError(err) {
+				p.acc.AddError(err)
+			}
+			return
+		}
+
+		p.mu.Lock()
+		p.conns[conn] = struct{}{}
+		p.mu.Unlock()
+
+		p.wg.Add(1)
+		go p.handle(conn)
+	}
+}
+
+// handle reads the nmon lines of one reporter connection
+func (p *socketServer) handle(conn net.Conn) {
+	defer func() {
+		p.mu.Lock()
+		delete(p.conns, conn)
+		p.mu.Unlock()
+		conn.Close()
+		p.wg.Done()
+	}()
+
+	src, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+
+	var (
+		info     string
+		snapshot []string
+	)
+	flush := func() {
+		if len(snapshot) == 0 {
+			return
+		}
+		if info == "" {
+			log.Printf("E! nmon socket: dropping snapshot from %s, no baseinfo line received", src)
+		} else {
+			data := info + lineSep + strings.Join(snapshot, lineSep)
+			if err := processData(p.acc, p.cfgs.Parser, src, []byte(data)); err != nil {
+				p.acc.AddError(err)
+			}
+		}
+		snapshot = snapshot[:0]
+	}
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for {
+		conn.SetReadDeadline(time.Now().Add(p.cfgs.ReadTimeout))
+		if !scanner.Scan() {
+			break
+		}
+
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case strings.TrimSpace(line) == "":
+			continue
+		case strings.HasPrefix(line, "ZZZZ"):
+			flush()
+			snapshot = append(snapshot, line)
+		case strings.Contains(line, infoSep) || info == "" && len(snapshot) == 0:
+			flush()
+			info = line
+		case len(snapshot) == 0:
+			log.Printf("D! nmon socket: skipping line outside of a snapshot from %s", src)
+		default:
+			snapshot = append(snapshot, line)
+		}
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil && !isClosedError(err) {
+		if nerr, ok := err.(net.Error); !ok || !nerr.Timeout() {
+			log.Printf("E! nmon socket: error reading from %s: %s", src, err)
+		}
+	}
+}
+
+// isClosedError returns true for the error returned when using a connection
+// or listener that was closed by Stop.
+func isClosedError(err error) bool {
+	return strings.HasSuffix(err.Error(), ": use of closed network connection")
+}
@@ -0,0 +1,71 @@
+package nmon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// dirReceiver picks up nmon tarballs dropped into a local directory, for
+// example by rsync or on a nfs mount.
+type dirReceiver struct {
+	*baseReceiver
+
+	dirPath string
+}
+
+//
+func newDirReceiver(cfg receiverConfig) *dirReceiver {
+	return &dirReceiver{
+		baseReceiver: newBaseReceiver(cfg),
+		dirPath:      cfg.LocalDirPath,
+	}
+}
+
+//
+func (p *dirReceiver) Start() error {
+	if err := p.start(); err != nil {
+		return err
+	}
+
+	p.poll(p.getFileList)
+	return nil
+}
+
+// stop dirReceiver
+func (p *dirReceiver) Stop() error {
+	return p.stop()
+}
+
+//
+func (p *dirReceiver) getFileList() {
+	p.sync(p)
+}
+
+// list the tarballs in the directory
+func (p *dirReceiver) list() ([]fileEntry, error) {
+	lst, err := ioutil.ReadDir(p.dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("list dir %s failed. error: %s", p.dirPath, err)
+	}
+
+	entries := make([]fileEntry, 0, len(lst))
+	for _, v := range lst {
+		if !v.Mode().IsRegular() || !isTarball(v.Name()) {
+			continue
+		}
+		entries = append(entries, fileEntry{Name: v.Name(), Size: v.Size(), Time: v.ModTime()})
+	}
+	return entries, nil
+}
+
+// read a tarball from the directory
+func (p *dirReceiver) read(name string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(p.dirPath, name))
+}
+
+// delete a tarball from the directory
+func (p *dirReceiver) remove(name string) error {
+	return os.Remove(filepath.Join(p.dirPath, name))
+}
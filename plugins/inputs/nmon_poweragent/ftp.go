@@ -0,0 +1,100 @@
+package nmon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+
+	"github.com/jlaffaye/ftp"
+)
+
+// ftpReceiver pulls nmon tarballs from a ftp server
+type ftpReceiver struct {
+	*baseReceiver
+
+	client   *ftp.ServerConn
+	addr     string
+	username string
+	password string
+	dirPath  string
+}
+
+//
+func newftpReceiver(cfg receiverConfig) *ftpReceiver {
+	return &ftpReceiver{
+		baseReceiver: newBaseReceiver(cfg),
+		addr:         cfg.FtpServer,
+		username:     cfg.FtpUsername,
+		password:     cfg.FtpPassword,
+		dirPath:      cfg.FtpDirPath,
+	}
+}
+
+//
+func (p *ftpReceiver) Start() error {
+	if err := p.start(); err != nil {
+		return err
+	}
+
+	p.poll(p.getFileList)
+	return nil
+}
+
+// stop ftpReceiver
+func (p *ftpReceiver) Stop() error {
+	return p.stop()
+}
+
+//
+func (p *ftpReceiver) getFileList() {
+	var err error
+
+	// connect to ftp
+	p.client, err = ftp.Connect(p.addr)
+	if err != nil {
+		log.Printf("connect to ftp failed. error: %s", err)
+		return
+	}
+	defer p.client.Quit()
+
+	err = p.client.Login(p.username, p.password)
+	if err != nil {
+		log.Printf("login to ftp failed. error: %s", err)
+		return
+	}
+
+	p.sync(p)
+}
+
+// list the tarballs on the ftp server
+func (p *ftpReceiver) list() ([]fileEntry, error) {
+	lst, err := p.client.List(p.dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("list dir %s failed. error: %s", p.dirPath, err)
+	}
+
+	entries := make([]fileEntry, 0, len(lst))
+	for _, v := range lst {
+		if v.Type != ftp.EntryTypeFile {
+			continue
+		}
+		entries = append(entries, fileEntry{Name: v.Name, Size: int64(v.Size), Time: v.Time})
+	}
+	return entries, nil
+}
+
+// read a tarball from the ftp server
+func (p *ftpReceiver) read(name string) ([]byte, error) {
+	resp, err := p.client.Retr(filepath.Join(p.dirPath, name))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Close()
+	return ioutil.ReadAll(resp)
+}
+
+// delete tarball from ftp server
+func (p *ftpReceiver) remove(name string) error {
+	return p.client.Delete(filepath.Join(p.dirPath, name))
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	nmonparser "github.com/influxdata/telegraf/plugins/parsers/nmon"
 )
 
 // when this plugins be loaded, package init method will register this plugin to
@@ -40,14 +41,42 @@ func init() {
 	inputs.Add("nmon_poweragent", newNmonServer)
 }
 
+// DEFAULT_PULL_PERIOD is the default period in seconds the ftp, sftp and dir
+// receivers look for new tarballs.
+const DEFAULT_PULL_PERIOD = 60
+
 var simpleConfig = `
 	##################################
 	#     receiver configuration     #
 	##################################	
-	# ip address and port will be listened, default value: 0.0.0.0:12345
-	# ftp debug use it.
-	http_listen = "0.0.0.0:12345"
-	
+	# where the nmon tarballs are received from, one of:
+	#   ftp  - pull the tarballs from a ftp server
+	#   sftp - pull the tarballs from a sftp server, using key authentication
+	#   dir  - pick up the tarballs dropped into a local directory
+	#   http - accept the tarballs uploaded to http://<http_listen>/upload
+	# default value: ftp
+	receiver = "ftp"
+
+	# ip address and port will be listened, not listened when empty.
+	# used for debug, and for uploads by the http receiver, which has to
+	# listen on an address reachable by the partitions.
+	http_listen = "127.0.0.1:12345"
+
+	# credentials of the http server, required by the http receiver
+	# basic_username = "nmon"
+	# basic_password = "secret"
+
+	# certificate and key of the http server, to serve https
+	# tls_cert = "/etc/telegraf/cert.pem"
+	# tls_key = "/etc/telegraf/key.pem"
+
+	# maximum size of an uploaded tarball, unit: byte
+	# max_upload_size = 33554432
+
+	# pull nmon perfdata files period of the ftp, sftp and dir receivers,
+	# unit: second
+	pull_period = 60
+
 	# ftp  username and password 
 	ftp_username = "admin"
 	ftp_password = "admin"
@@ -55,59 +84,115 @@ var simpleConfig = `
 	# the ftp server which will connect to
 	ftp_server = "10.10.10.10:21"
 	ftp_dirpath = "/upload"
-	
-	# pull nmon perfdata files period, unit: second
-	ftp_pullperiod = 60
+
+	# the sftp server which will connect to, and the private key file used to
+	# log in to it
+	# sftp_server = "10.10.10.10:22"
+	# sftp_username = "nmon"
+	# sftp_private_key = "/etc/telegraf/nmon_id_rsa"
+	# sftp_dirpath = "/upload"
+
+	# the host key of the sftp server is verified against either its public
+	# key in authorized_keys format, or a known_hosts file, one of them must
+	# be set
+	# sftp_host_key = "ssh-rsa AAAA..."
+	# sftp_known_hosts = "/etc/telegraf/known_hosts"
+	# skip the verification of the host key, insecure
+	# sftp_insecure_ignore_host_key = false
+
+	# the local directory the tarballs are dropped into
+	# local_dirpath = "/var/spool/nmon"
 	
 	
 	##################################
 	#    processer configuration     #
 	##################################
 	
-	# format , default value: influx
-	data_format = "influx"
+	# format, only nmon is supported, its options, ie nmon_timezone, are
+	# described in docs/DATA_FORMATS_INPUT.md. the snapshots are in the
+	# +08:00 time zone by default.
+	data_format = "nmon"
 	
 	# the data channal size which used to send data by receiver to processer
 	data_chansize = 2000
 	
 	# how many data processers will be start to process data
 	data_threads = 100
+
+	# the db file keeping the state of every received tarball, a tarball is
+	# processed only once, also when telegraf is restarted or crashes.
+	# default value: nmon_poweragent.db
+	db_file = "nmon_poweragent.db"
 `
 
-// http receiver configuration
+// receiver configuration
 type receiverConfig struct {
-	FtpUsername   string
-	FtpPassword   string
-	FtpServer     string
-	FtpDirPath    string
-	FtpPullPeriod int
-	HttpListen    string
-	WriteDataChan chan<- []byte
-	DB            *bolt.DB
+	Receiver       string
+	FtpUsername    string
+	FtpPassword    string
+	FtpServer      string
+	FtpDirPath     string
+	SftpUsername   string
+	SftpPrivateKey string
+	SftpHostKey    string
+	SftpKnownHosts string
+	SftpServer     string
+	SftpDirPath    string
+	LocalDirPath   string
+	PullPeriod     int
+	HttpListen     string
+	BasicUsername  string
+	BasicPassword  string
+	TlsCert        string
+	TlsKey         string
+	MaxUploadSize  int64
+	WriteDataChan  chan<- *report
+	DB             *bolt.DB
+
+	SftpInsecureIgnoreHostKey bool
 }
 
 // NmonServer implement the plugins interface
 type NmonServer struct {
+	Receiver   string `toml:"receiver"`
+	PullPeriod int    `toml:"pull_period"`
+
 	FtpUsername   string `toml:"ftp_username"`
 	FtpPassword   string `toml:"ftp_password"`
 	FtpServer     string `toml:"ftp_server"`
 	FtpDirPath    string `toml:"ftp_dirpath"`
-	FtpPullPeriod int    `toml:"ftp_pullperiod"`
+	FtpPullPeriod int    `toml:"ftp_pullperiod"` // deprecated, use pull_period
+
+	SftpUsername   string `toml:"sftp_username"`
+	SftpPrivateKey string `toml:"sftp_private_key"`
+	SftpHostKey    string `toml:"sftp_host_key"`
+	SftpKnownHosts string `toml:"sftp_known_hosts"`
+	SftpServer     string `toml:"sftp_server"`
+	SftpDirPath    string `toml:"sftp_dirpath"`
 
-	HttpListen string `toml:"http_listen"`
+	SftpInsecureIgnoreHostKey bool `toml:"sftp_insecure_ignore_host_key"`
+
+	LocalDirPath string `toml:"local_dirpath"`
+
+	HttpListen    string `toml:"http_listen"`
+	BasicUsername string `toml:"basic_username"`
+	BasicPassword string `toml:"basic_password"`
+	TlsCert       string `toml:"tls_cert"`
+	TlsKey        string `toml:"tls_key"`
+	MaxUploadSize int64  `toml:"max_upload_size"`
 
 	// common options
-	DataFormat   string `toml:"data_format"`
-	DataChanSize int    `toml:"data_chansize"`
-	DataThreads  int    `toml:"data_threads"`
+	DataChanSize int `toml:"data_chansize"`
+	DataThreads  int `toml:"data_threads"`
 
-	receiver   *ftpReceiver // nmon perf data receive server
+	receiver   receiver // nmon perf data receive server
 	processers []*processer
+	parser     *nmonparser.Parser // shared by the processers
 
 	DBFile string `toml:"db_file"`
 	db     *bolt.DB
 
-	dataChan chan []byte
+	dataChan chan *report
 }
 
 // newNmonServer create a new NmonServer and return it
@@ -116,6 +201,8 @@ func newNmonServer() telegraf.Input {
 		DataChanSize: 2000,
 		DataThreads:  10,
 		DBFile:       "nmon_poweragent.db",
+		// the time zone the reporters have always been assumed to run in
+		parser: &nmonparser.Parser{Location: time.FixedZone("", 8*60*60)},
 	}
 }
 
@@ -135,12 +222,16 @@ func (p *NmonServer) Gather(_ telegraf.Accumulator) error {
 }
 
 // implement ServiceInput interface
-func (p *NmonServer) SetParser(_ parsers.Parser) {
+// SetParser keeps the parser of the nmon data format, the reports can not
+// be parsed with the other data formats.
+func (p *NmonServer) SetParser(parser parsers.Parser) {
+	if parser, ok := parser.(*nmonparser.Parser); ok {
+		p.parser = parser
+	}
 }
 
 // implement ServiceInput interface
-// nmon plugin does not support running http/ftp mode together currently.
-// if want, need make the configration support.
+// nmon plugin runs the one receiver selected by the receiver option.
 func (p *NmonServer) Start(acc telegraf.Accumulator) error {
 
 	var err error
@@ -153,10 +244,10 @@ func (p *NmonServer) Start(acc telegraf.Accumulator) error {
 		return fmt.Errorf("open db %s failed. error: %s", p.DBFile, err)
 	}
 
-	p.dataChan = make(chan []byte, p.DataChanSize)
+	p.dataChan = make(chan *report, p.DataChanSize)
 	p.processers = make([]*processer, 0)
 	for i := 1; i <= p.DataThreads; i++ {
-		ps := newProcesser(i, p.dataChan, acc)
+		ps := newProcesser(i, p.dataChan, acc, p.parser)
 		err := ps.Start()
 		if err != nil {
 			log.Println(err)
@@ -165,30 +256,72 @@ func (p *NmonServer) Start(acc telegraf.Accumulator) error {
 		p.processers = append(p.processers, ps)
 	}
 
-	p.receiver = newftpReceiver(p.ReceiverConfig())
+	p.receiver, err = newReceiver(p.ReceiverConfig())
+	if err != nil {
+		p.stopProcessers()
+		p.db.Close()
+		return err
+	}
 	return p.receiver.Start()
 
 }
 
 // implement ServiceInput interface
 func (p *NmonServer) Stop() {
+	if p.receiver != nil {
+		if err := p.receiver.Stop(); err != nil {
+			log.Printf("stop receiver failed. error: %s\n", err)
+		}
+	}
+	p.stopProcessers()
+	if p.db != nil {
+		if err := p.db.Close(); err != nil {
+			log.Printf("close db failed. error: %s\n", err)
+		}
+	}
+}
+
+//
+func (p *NmonServer) stopProcessers() {
 	for _, ps := range p.processers {
 		ps.Stop()
 	}
-	p.receiver.Stop()
 }
 
 // Config method return a config struct. it will be used to run a
 // server implementation
 func (p *NmonServer) ReceiverConfig() receiverConfig {
+	period := p.PullPeriod
+	if period <= 0 {
+		period = p.FtpPullPeriod
+	}
+	if period <= 0 {
+		period = DEFAULT_PULL_PERIOD
+	}
+
 	return receiverConfig{
-		FtpUsername:   p.FtpUsername,
-		FtpPassword:   p.FtpPassword,
-		FtpServer:     p.FtpServer,
-		FtpDirPath:    p.FtpDirPath,
-		FtpPullPeriod: p.FtpPullPeriod,
-		HttpListen:    p.HttpListen,
-		WriteDataChan: p.dataChan,
-		DB:            p.db,
+		Receiver:       p.Receiver,
+		FtpUsername:    p.FtpUsername,
+		FtpPassword:    p.FtpPassword,
+		FtpServer:      p.FtpServer,
+		FtpDirPath:     p.FtpDirPath,
+		SftpUsername:   p.SftpUsername,
+		SftpPrivateKey: p.SftpPrivateKey,
+		SftpHostKey:    p.SftpHostKey,
+		SftpKnownHosts: p.SftpKnownHosts,
+		SftpServer:     p.SftpServer,
+		SftpDirPath:    p.SftpDirPath,
+		LocalDirPath:   p.LocalDirPath,
+		PullPeriod:     period,
+		HttpListen:     p.HttpListen,
+		BasicUsername:  p.BasicUsername,
+		BasicPassword:  p.BasicPassword,
+		TlsCert:        p.TlsCert,
+		TlsKey:         p.TlsKey,
+		MaxUploadSize:  p.MaxUploadSize,
+		WriteDataChan:  p.dataChan,
+		DB:             p.db,
+
+		SftpInsecureIgnoreHostKey: p.SftpInsecureIgnoreHostKey,
 	}
 }
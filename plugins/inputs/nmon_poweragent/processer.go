@@ -5,15 +5,17 @@ import (
 	"log"
 
 	"github.com/influxdata/telegraf"
+	nmonparser "github.com/influxdata/telegraf/plugins/parsers/nmon"
 )
 
 // processer parse data dan send metrics to sender
 type processer struct {
 	id       int                // id identity a processer to make diffrent with others processers
-	dataChan <-chan []byte      // dataChan used by receiver to send data
+	dataChan <-chan *report     // dataChan used by receiver to send data
 	cancel   context.CancelFunc // cancel stop all the processer's jobs before it's stop
 
-	acc telegraf.Accumulator
+	acc    telegraf.Accumulator
+	parser *nmonparser.Parser
 }
 
 // start a processer with cancel
@@ -24,11 +26,9 @@ func (p *processer) Start() error {
 		log.Printf("starting porcesser %d\n", p.id)
 		for {
 			select {
-			case data := <-p.dataChan:
-				err := p.parseNmonData(data)
-				if err != nil {
-					log.Printf("parse nmon data failed. %s\n", err)
-				}
+			case r := <-p.dataChan:
+				// the receiver logs the error and acknowledges the report
+				r.done <- p.parseNmonData(r.data)
 				break
 			case <-ctx.Done():
 				return
@@ -46,24 +46,26 @@ func (p *processer) Stop() error {
 }
 
 func (p *processer) parseNmonData(data []byte) error {
-	parser := new(nmonParser)
-	err := parser.Parse(data)
+	metrics, err := p.parser.Parse(data)
 	if err != nil {
 		return err
 	}
-	parser.SetVersion("1.0")
 
-	for _, v := range parser.Metrics() {
-		p.acc.AddGauge(v.Measurement(), v.Fields(), v.Tags(), v.Time())
+	start := startTime(data, p.parser.Location)
+	for _, m := range metrics {
+		for _, r := range records(m) {
+			p.acc.AddGauge("nmon_"+r.deviceType, r.fields, r.tags(m, start), m.Time())
+		}
 	}
 	return nil
 }
 
 //
-func newProcesser(id int, ch <-chan []byte, acc telegraf.Accumulator) *processer {
+func newProcesser(id int, ch <-chan *report, acc telegraf.Accumulator, parser *nmonparser.Parser) *processer {
 	return &processer{
 		id:       id,
 		dataChan: ch,
 		acc:      acc,
+		parser:   parser,
 	}
 }
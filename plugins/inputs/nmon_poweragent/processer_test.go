@@ -0,0 +1,350 @@
+package nmon
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/outputs/poweragent"
+	nmonparser "github.com/influxdata/telegraf/plugins/parsers/nmon"
+	"github.com/influxdata/telegraf/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testReport = `AAA,progname,topas_nmon
+AAA,host,rcvioc03
+AAA,LPARNumberName,3,rc_06B86A1_VIOC3
+ZZZZ,T0001,15:59:34,24-JAN-2018
+CPU01,T0001,2.1,2.1,0.0,95.7
+`
+
+// the poweragent output sends the object of the nmon metrics as their a_id
+func TestProcesserPoweragentOutput(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	ps := newProcesser(1, nil, acc, &nmonparser.Parser{Location: time.UTC})
+	require.NoError(t, ps.parseNmonData([]byte(testReport)))
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, "CPU01", acc.Metrics[0].Tags["a_id"])
+	assert.Equal(t, "cpu", acc.Metrics[0].Tags["device_type"])
+
+	var metrics []telegraf.Metric
+	for _, m := range acc.Metrics {
+		mm, err := metric.New(m.Measurement, m.Tags, m.Fields, m.Time)
+		require.NoError(t, err)
+		metrics = append(metrics, mm)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	sw := &poweragent.SocketWriter{Address: "tcp://" + ln.Addr().String()}
+	sw.SetSerializer(nil)
+	require.NoError(t, sw.Connect())
+	defer sw.Close()
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, sw.Write(metrics))
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	require.NoError(t, err)
+
+	var record struct {
+		DeviceType string `json:"device_type"`
+		L          string `json:"l"`
+		C          []struct {
+			AID   string      `json:"a_id"`
+			Tag   string      `json:"tag"`
+			Value interface{} `json:"value"`
+		} `json:"c"`
+	}
+	require.NoError(t, json.Unmarshal(line, &record))
+	assert.Equal(t, "cpu", record.DeviceType)
+	assert.Equal(t, "rc_06B86A1_VIOC3", record.L)
+	require.Len(t, record.C, 4)
+	for _, c := range record.C {
+		assert.Equal(t, "CPU01", c.AID)
+	}
+}
+
+// the counters of the former nmon_poweragent parser for testdata/rcvioc03.nmon,
+// its integer values were int64 and are now float64, they serialize alike.
+var formerCounters = []struct {
+	deviceType string
+	instance   string
+	counter    string
+	value      float64
+}{
+	{"cpu", "CPU01", "idle", 95.7},
+	{"cpu", "CPU01", "sys", 2.1},
+	{"cpu", "CPU01", "user", 2.1},
+	{"cpu", "CPU01", "wait", 0},
+	{"cpu", "CPU02", "idle", 99.8},
+	{"cpu", "CPU02", "sys", 0},
+	{"cpu", "CPU02", "user", 0.2},
+	{"cpu", "CPU02", "wait", 0},
+	{"cpu", "CPU03", "idle", 100},
+	{"cpu", "CPU03", "sys", 0},
+	{"cpu", "CPU03", "user", 0},
+	{"cpu", "CPU03", "wait", 0},
+	{"cpu", "CPU04", "idle", 100},
+	{"cpu", "CPU04", "sys", 0},
+	{"cpu", "CPU04", "user", 0},
+	{"cpu", "CPU04", "wait", 0},
+	{"cpu", "CPU_ALL", "idle", 95.3},
+	{"cpu", "CPU_ALL", "physicalCPUS", 4},
+	{"cpu", "CPU_ALL", "sys", 1.5},
+	{"cpu", "CPU_ALL", "user", 2.9},
+	{"cpu", "CPU_ALL", "wait", 0.3},
+	{"cpu", "PCPU01", "idle", 0},
+	{"cpu", "PCPU01", "sys", 0.01},
+	{"cpu", "PCPU01", "user", 0.03},
+	{"cpu", "PCPU01", "wait", 0},
+	{"cpu", "PCPU02", "idle", 0},
+	{"cpu", "PCPU02", "sys", 0},
+	{"cpu", "PCPU02", "user", 0},
+	{"cpu", "PCPU02", "wait", 0},
+	{"cpu", "PCPU03", "idle", 0},
+	{"cpu", "PCPU03", "sys", 0},
+	{"cpu", "PCPU03", "user", 0},
+	{"cpu", "PCPU03", "wait", 0},
+	{"cpu", "PCPU04", "idle", 0},
+	{"cpu", "PCPU04", "sys", 0},
+	{"cpu", "PCPU04", "user", 0},
+	{"cpu", "PCPU04", "wait", 0},
+	{"cpu", "PCPU_ALL", "entitledCapacity", 1},
+	{"cpu", "PCPU_ALL", "idle", 0},
+	{"cpu", "PCPU_ALL", "sys", 0.01},
+	{"cpu", "PCPU_ALL", "user", 0.03},
+	{"cpu", "PCPU_ALL", "wait", 0},
+	{"cpu", "SCPU01", "idle", 0},
+	{"cpu", "SCPU01", "sys", 0.01},
+	{"cpu", "SCPU01", "user", 0.03},
+	{"cpu", "SCPU01", "wait", 0},
+	{"cpu", "SCPU02", "idle", 0},
+	{"cpu", "SCPU02", "sys", 0},
+	{"cpu", "SCPU02", "user", 0},
+	{"cpu", "SCPU02", "wait", 0},
+	{"cpu", "SCPU03", "idle", 0},
+	{"cpu", "SCPU03", "sys", 0},
+	{"cpu", "SCPU03", "user", 0},
+	{"cpu", "SCPU03", "wait", 0},
+	{"cpu", "SCPU04", "idle", 0},
+	{"cpu", "SCPU04", "sys", 0},
+	{"cpu", "SCPU04", "user", 0},
+	{"cpu", "SCPU04", "wait", 0},
+	{"cpu", "SCPU_ALL", "idle", 0},
+	{"cpu", "SCPU_ALL", "sys", 0.01},
+	{"cpu", "SCPU_ALL", "user", 0.03},
+	{"cpu", "SCPU_ALL", "wait", 0},
+	{"disk", "cd0", "avgrio", 0},
+	{"disk", "cd0", "avgwio", 0},
+	{"disk", "cd0", "bsize", 0},
+	{"disk", "cd0", "busy", 0},
+	{"disk", "cd0", "read", 0},
+	{"disk", "cd0", "rio", 0},
+	{"disk", "cd0", "rxfer", 0},
+	{"disk", "cd0", "wio", 0},
+	{"disk", "cd0", "write", 0},
+	{"disk", "cd0", "xfer", 0},
+	{"disk", "hdisk0", "avgrio", 87.7},
+	{"disk", "hdisk0", "avgwio", 4.4},
+	{"disk", "hdisk0", "bsize", 12.5},
+	{"disk", "hdisk0", "busy", 1.3},
+	{"disk", "hdisk0", "read", 17.5},
+	{"disk", "hdisk0", "rio", 0.2},
+	{"disk", "hdisk0", "rxfer", 0.2},
+	{"disk", "hdisk0", "wio", 1.8},
+	{"disk", "hdisk0", "write", 8.1},
+	{"disk", "hdisk0", "xfer", 2},
+	{"disk", "hdisk1", "avgrio", 87.7},
+	{"disk", "hdisk1", "avgwio", 0},
+	{"disk", "hdisk1", "bsize", 87.7},
+	{"disk", "hdisk1", "busy", 0},
+	{"disk", "hdisk1", "read", 17.5},
+	{"disk", "hdisk1", "rio", 0.2},
+	{"disk", "hdisk1", "rxfer", 0.2},
+	{"disk", "hdisk1", "wio", 0},
+	{"disk", "hdisk1", "write", 0},
+	{"disk", "hdisk1", "xfer", 0.2},
+	{"file", "/", "inode", 1.1},
+	{"file", "/", "u_p", 29.8},
+	{"file", "/admin", "inode", 0},
+	{"file", "/admin", "u_p", 0.3},
+	{"file", "/home", "inode", 0.2},
+	{"file", "/home", "u_p", 6},
+	{"file", "/opt", "inode", 1.1},
+	{"file", "/opt", "u_p", 50.5},
+	{"file", "/tmp", "inode", 1.2},
+	{"file", "/tmp", "u_p", 28.4},
+	{"file", "/usr", "inode", 2.3},
+	{"file", "/usr", "u_p", 27.8},
+	{"file", "/var", "inode", 33.7},
+	{"file", "/var", "u_p", 87.9},
+	{"file", "/var/adm/ras/livedump", "inode", 0},
+	{"file", "/var/adm/ras/livedump", "u_p", 0.1},
+	{"file", "FILE", "dirblk", 0},
+	{"file", "FILE", "iget", 0},
+	{"file", "FILE", "namei", 164},
+	{"file", "FILE", "readch", 278437},
+	{"file", "FILE", "ttycanch", 0},
+	{"file", "FILE", "ttyoutch", 0},
+	{"file", "FILE", "ttyrawch", 0},
+	{"file", "FILE", "writech", 4461},
+	{"lpar", "LPAR", "capped", 0},
+	{"lpar", "LPAR", "ecidle", 0.2},
+	{"lpar", "LPAR", "ecsys", 1.49},
+	{"lpar", "LPAR", "ecuser", 2.89},
+	{"lpar", "LPAR", "ecwait", 0},
+	{"lpar", "LPAR", "entitled", 1},
+	{"lpar", "LPAR", "folded", 0},
+	{"lpar", "LPAR", "logicalcpus", 4},
+	{"lpar", "LPAR", "physicalcpu", 0.046},
+	{"lpar", "LPAR", "poocpus", 4},
+	{"lpar", "LPAR", "poolid", 0},
+	{"lpar", "LPAR", "poolidle", 0},
+	{"lpar", "LPAR", "sharedcpu", 1},
+	{"lpar", "LPAR", "usedallcpu", 1.14},
+	{"lpar", "LPAR", "usedpoolcpu", 1.14},
+	{"lpar", "LPAR", "virtualcpus", 2},
+	{"lpar", "LPAR", "vpidle", 0.1},
+	{"lpar", "LPAR", "vpsys", 0.74},
+	{"lpar", "LPAR", "vpuser", 1.45},
+	{"lpar", "LPAR", "vpwait", 0},
+	{"lpar", "LPAR", "weight", 128},
+	{"mem", "MEM", "RealFree", 1.4},
+	{"mem", "MEM", "RealFreeMB", 56.3},
+	{"mem", "MEM", "RealTotalMB", 4096},
+	{"mem", "MEM", "VirtualFree", 97.5},
+	{"mem", "MEM", "VirtualFreeMB", 499.1},
+	{"mem", "MEM", "VritualTotalMB", 512},
+	{"mem", "MEMNEW", "free", 1.4},
+	{"mem", "MEMNEW", "fscache", 55.9},
+	{"mem", "MEMNEW", "pinned", 23.5},
+	{"mem", "MEMNEW", "process", 19.7},
+	{"mem", "MEMNEW", "system", 23},
+	{"mem", "MEMNEW", "user", 72.1},
+	{"mem", "MEMUSE", "maxclient", 90},
+	{"mem", "MEMUSE", "maxfree", 1088},
+	{"mem", "MEMUSE", "maxperm", 90},
+	{"mem", "MEMUSE", "minfree", 960},
+	{"mem", "MEMUSE", "minperm", 3},
+	{"mem", "MEMUSE", "numclient", 55.9},
+	{"mem", "MEMUSE", "numperm", 55.9},
+	{"mem", "PAGE", "cycles", 0},
+	{"mem", "PAGE", "faults", 1078.7},
+	{"mem", "PAGE", "pgin", 0},
+	{"mem", "PAGE", "pgout", 0.3},
+	{"mem", "PAGE", "pgsin", 0},
+	{"mem", "PAGE", "pgsout", 0},
+	{"mem", "PAGE", "reclaims", 0},
+	{"mem", "PAGE", "scans", 0},
+	{"net", "en0", "collisions", 0},
+	{"net", "en0", "ierrs", 0},
+	{"net", "en0", "oerrs", 0},
+	{"net", "en0", "packetread", 4.1},
+	{"net", "en0", "packetwrite", 0.5},
+	{"net", "en0", "read", 0.2},
+	{"net", "en0", "readsize", 51},
+	{"net", "en0", "write", 0.1},
+	{"net", "en0", "writesize", 243.1},
+	{"net", "lo0", "collisions", 0},
+	{"net", "lo0", "ierrs", 0},
+	{"net", "lo0", "oerrs", 0},
+	{"net", "lo0", "packetread", 0.8},
+	{"net", "lo0", "packetwrite", 0.8},
+	{"net", "lo0", "read", 0.1},
+	{"net", "lo0", "readsize", 67.6},
+	{"net", "lo0", "write", 0.1},
+	{"net", "lo0", "writesize", 67.6},
+	{"pools", "0", "entitled", 1},
+	{"pools", "0", "entitled_pool_capacity", 3.1},
+	{"pools", "0", "max_pool_capacity", 4},
+	{"pools", "0", "pool_busy_time", 0},
+	{"pools", "0", "pool_max_time", 0},
+	{"pools", "0", "shcpu_busy_time", 0},
+	{"pools", "0", "shcpu_in_sys", 4},
+	{"pools", "0", "shcpu_tot_time", 0},
+	{"process", "7340108", "chario", 762},
+	{"process", "7340108", "cpu", 0.11},
+	{"process", "7340108", "paging", 34},
+	{"process", "7340108", "ram", 0},
+	{"process", "7340108", "resdata", 12448},
+	{"process", "7340108", "restext", 216},
+	{"process", "7340108", "size", 12772},
+	{"process", "7340108", "sys", 0.06},
+	{"process", "7340108", "threads", 9},
+	{"process", "7340108", "usr", 0.05},
+	{"process", "8519710", "chario", 2558},
+	{"process", "8519710", "cpu", 0.17},
+	{"process", "8519710", "paging", 54},
+	{"process", "8519710", "ram", 1},
+	{"process", "8519710", "resdata", 27888},
+	{"process", "8519710", "restext", 3440},
+	{"process", "8519710", "size", 32344},
+	{"process", "8519710", "sys", 0.06},
+	{"process", "8519710", "threads", 77},
+	{"process", "8519710", "usr", 0.11},
+	{"process", "PROC", "asleepbufio", 0},
+	{"process", "PROC", "asleepdiocio", 0},
+	{"process", "PROC", "asleeprawio", 0},
+	{"process", "PROC", "exec", 3},
+	{"process", "PROC", "fork", 2},
+	{"process", "PROC", "msg", 0},
+	{"process", "PROC", "pswitch", 233},
+	{"process", "PROC", "read", 83},
+	{"process", "PROC", "runnable", 2.38},
+	{"process", "PROC", "sem", 0},
+	{"process", "PROC", "swapin", 0.02},
+	{"process", "PROC", "syscall", 1572},
+	{"process", "PROC", "write", 5},
+	{"san", "vscsi0", "read", 35.2},
+	{"san", "vscsi0", "write", 8.1},
+	{"san", "vscsi0", "xfer-tps", 2.3},
+}
+
+// the metrics have the device types, instances, counters and tags of the
+// former nmon_poweragent parser
+func TestProcesserFormerSchema(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/rcvioc03.nmon")
+	require.NoError(t, err)
+
+	acc := &testutil.Accumulator{}
+	ps := newProcesser(1, nil, acc, &nmonparser.Parser{Location: time.FixedZone("", 8*60*60)})
+	require.NoError(t, ps.parseNmonData(data))
+
+	type counter struct {
+		deviceType, instance, counter string
+	}
+	got := make(map[counter]interface{})
+	for _, m := range acc.Metrics {
+		assert.Equal(t, "nmon_"+m.Tags["device_type"], m.Measurement)
+		assert.Equal(t, map[string]string{
+			"device_type": m.Tags["device_type"],
+			"a_id":        m.Tags["a_id"],
+			"s":           "9117-MMA*06B86A1",
+			"l":           "rc_06B86A1_VIOC3",
+			"l_id":        "3",
+			"t_f":         "1516780594",
+			"v":           "1.0",
+		}, m.Tags)
+		assert.Equal(t, int64(1516780774), m.Time.Unix())
+		for k, v := range m.Fields {
+			got[counter{m.Tags["device_type"], m.Tags["a_id"], k}] = v
+		}
+	}
+
+	want := make(map[counter]interface{})
+	for _, c := range formerCounters {
+		want[counter{c.deviceType, c.instance, c.counter}] = c.value
+	}
+	assert.Equal(t, want, got)
+}
@@ -4,21 +4,23 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
-	"path/filepath"
+	"net"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"net/http"
-
-	"github.com/boltdb/bolt"
-	"github.com/jlaffaye/ftp"
 )
 
 //
@@ -26,98 +28,214 @@ var (
 	version = "1.0"
 )
 
-//
-type ftpReceiver struct {
-	sync.RWMutex
-	client   *ftp.ServerConn
-	addr     string
-	username string
-	password string
-	dirPath  string
-	period   int
+// receiver types
+const (
+	ftpReceiverType    = "ftp"
+	sftpReceiverType   = "sftp"
+	dirReceiverType    = "dir"
+	uploadReceiverType = "http"
+)
+
+// receiver fetches nmon tarballs from a file source and sends the nmon data
+// in them to the processers.
+type receiver interface {
+	Start() error
+	Stop() error
+}
+
+// newReceiver create the receiver configured by cfg.Receiver
+func newReceiver(cfg receiverConfig) (receiver, error) {
+	switch strings.ToLower(cfg.Receiver) {
+	case "", ftpReceiverType:
+		return newftpReceiver(cfg), nil
+	case sftpReceiverType:
+		return newSftpReceiver(cfg)
+	case dirReceiverType:
+		return newDirReceiver(cfg), nil
+	case uploadReceiverType:
+		return newUploadReceiver(cfg)
+	default:
+		return nil, fmt.Errorf("unknown receiver %q", cfg.Receiver)
+	}
+}
+
+// fileEntry is a tarball found on a file source
+type fileEntry struct {
+	Name string
+	Size int64
+	Time time.Time
+}
+
+// source is a file source polled by a receiver
+type source interface {
+	list() ([]fileEntry, error)
+	read(name string) ([]byte, error)
+	remove(name string) error
+}
+
+// report is the nmon data of one tarball sent to the processers, the
+// processer sends the result of parsing it to done.
+type report struct {
+	name string
+	data []byte
+	done chan error
+}
 
-	db     *bolt.DB
-	bucket string
+// baseReceiver implements the parts shared by all receivers: the state of
+// the tarballs, the debug http server and the processing of tarballs.
+type baseReceiver struct {
+	sync.RWMutex
 
-	httpsrv     *http.ServeMux
-	debugListen string
+	state    *fileState
+	last     map[string]int64
+	inflight map[string]string // name of the tarball by checksum
+	resume   []fileEntry       // tarballs interrupted before the start
 
 	deletelist  []string
 	processlist []string
-	last        map[string]int64
-
-	wg *sync.WaitGroup
 
-	dataChan chan<- []byte
+	mux        *http.ServeMux
+	srv        *http.Server
+	listener   net.Listener
+	httpListen string
+
+	// credentials and certificate of the http server
+	basicUsername string
+	basicPassword string
+	tlsCert       string
+	tlsKey        string
+
+	period   time.Duration
+	done     chan struct{}
+	wg       sync.WaitGroup
+	dataChan chan<- *report
 }
 
 //
-func newftpReceiver(cfg receiverConfig) *ftpReceiver {
-	return &ftpReceiver{
-		addr:        cfg.FtpServer,
-		username:    cfg.FtpUsername,
-		password:    cfg.FtpPassword,
-		dirPath:     cfg.FtpDirPath,
-		period:      cfg.FtpPullPeriod,
-		last:        make(map[string]int64),
-		bucket:      "ftpfilefetcher",
-		httpsrv:     http.DefaultServeMux,
-		debugListen: cfg.HttpListen,
-		wg:          new(sync.WaitGroup),
-		dataChan:    cfg.WriteDataChan,
-		db:          cfg.DB,
+func newBaseReceiver(cfg receiverConfig) *baseReceiver {
+	return &baseReceiver{
+		state:      &fileState{db: cfg.DB},
+		last:       make(map[string]int64),
+		inflight:   make(map[string]string),
+		mux:        http.NewServeMux(),
+		httpListen: cfg.HttpListen,
+		period:     time.Duration(cfg.PullPeriod) * time.Second,
+		done:       make(chan struct{}),
+		dataChan:   cfg.WriteDataChan,
+
+		basicUsername: cfg.BasicUsername,
+		basicPassword: cfg.BasicPassword,
+		tlsCert:       cfg.TlsCert,
+		tlsKey:        cfg.TlsKey,
 	}
 }
 
-//
-func (p *ftpReceiver) Start() error {
-	var err error
-
-	tx, err := p.db.Begin(true)
-	if err != nil {
-		return fmt.Errorf("open bolt tx failed. error: %s", err)
+// start load the state from db and start the debug http server
+func (p *baseReceiver) start() error {
+	if err := p.state.init(p.last); err != nil {
+		return fmt.Errorf("load state failed. error: %s", err)
 	}
-
-	bkt, err := tx.CreateBucketIfNotExists([]byte(p.bucket))
+	resume, err := p.state.recover()
 	if err != nil {
-		return fmt.Errorf("create bucket failed. error: %s", err)
+		return fmt.Errorf("recover state failed. error: %s", err)
 	}
+	p.resume = resume
 
-	bkt.ForEach(p.foreach)
+	if p.httpListen == "" {
+		return nil
+	}
 
-	err = tx.Commit()
+	ln, err := net.Listen("tcp", p.httpListen)
 	if err != nil {
-		if tx.Rollback() != nil {
-			log.Printf("tx rollback failed. error: %s\n", err)
+		return fmt.Errorf("listen on %s failed. error: %s", p.httpListen, err)
+	}
+	if p.tlsCert != "" || p.tlsKey != "" {
+		cert, err := tls.LoadX509KeyPair(p.tlsCert, p.tlsKey)
+		if err != nil {
+			ln.Close()
+			return fmt.Errorf("load tls_cert and tls_key failed. error: %s", err)
 		}
-		return fmt.Errorf("tx commit failed. error: %s", err)
+		ln = tls.NewListener(ln, &tls.Config{Certificates: []tls.Certificate{cert}})
 	}
+	p.listener = ln
 
 	// start debug
-	p.httpsrv.HandleFunc("/debug", p.debugHandle)
+	p.mux.Handle("/debug", p.authenticated(http.HandlerFunc(p.debugHandle)))
+	p.srv = &http.Server{Handler: p.mux}
 	go func() {
-		if err := http.ListenAndServe(p.debugListen, p.httpsrv); err != nil {
-			log.Printf("start debug http server failed. error: %s\n", err)
+		if err := p.srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.Printf("start http server failed. error: %s\n", err)
 		}
 	}()
 
-	go p.getFileList()
-
 	return nil
 }
 
-// foreach conv kev/value to cache
-func (p *ftpReceiver) foreach(key, value []byte) error {
-	iv, err := strconv.ParseInt(string(value), 10, 64)
-	if err != nil {
-		return err
+// authenticated requires the basic_username and basic_password, when they
+// are set, for the requests to a handler.
+func (p *baseReceiver) authenticated(h http.Handler) http.Handler {
+	if p.basicUsername == "" && p.basicPassword == "" {
+		return h
+	}
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		username, password, ok := req.BasicAuth()
+		if !ok ||
+			subtle.ConstantTimeCompare([]byte(username), []byte(p.basicUsername)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(password), []byte(p.basicPassword)) != 1 {
+			rw.Header().Set("WWW-Authenticate", `Basic realm="nmon_poweragent"`)
+			http.Error(rw, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(rw, req)
+	})
+}
+
+// stop the http server and the pull loop
+func (p *baseReceiver) stop() error {
+	close(p.done)
+
+	var err error
+	if p.srv != nil {
+		err = p.srv.Shutdown(context.Background())
+	}
+	p.wg.Wait()
+	return err
+}
+
+// stopped return true once stop was called
+func (p *baseReceiver) stopped() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
 	}
-	p.last[string(key)] = iv
-	return nil
+}
+
+// poll call fetch immediately and then every period until stopped
+func (p *baseReceiver) poll(fetch func()) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.period)
+		defer ticker.Stop()
+		for {
+			fetch()
+			select {
+			case <-ticker.C:
+			case <-p.done:
+				return
+			}
+		}
+	}()
 }
 
 // debugHandle print cache/processlist/deletelist
-func (p *ftpReceiver) debugHandle(resp http.ResponseWriter, req *http.Request) {
+func (p *baseReceiver) debugHandle(resp http.ResponseWriter, req *http.Request) {
+	p.RLock()
+	defer p.RUnlock()
+
 	var data = make([]byte, 0)
 
 	data = append(data, []byte("cache: ")...)
@@ -136,153 +254,306 @@ func (p *ftpReceiver) debugHandle(resp http.ResponseWriter, req *http.Request) {
 	resp.Write(data)
 }
 
-// stop ftpFileFetcher
-func (p *ftpReceiver) Stop() error {
-	err := p.db.Close()
+// sync process the new tarballs of a source, and delete the processed ones
+// from it.
+func (p *baseReceiver) sync(src source) {
+	lst, err := src.list()
 	if err != nil {
-		log.Printf("close db failed. error: %s\n", err)
+		log.Printf("list files failed. error: %s\n", err)
+		return
 	}
-	return p.client.Logout()
-}
 
-// presist store the last cache into db
-func (p *ftpReceiver) presist() {
-	tx, err := p.db.Begin(true)
-	if err != nil {
-		log.Printf("open bolt tx failed. error: %s\n", err)
-		return
+	process, remove := p.parseFilesList(lst)
+	process = p.resumed(process)
+
+	// the tarballs are read one by one, as the ftp and sftp clients can not
+	// be used concurrently, and processed in parallel.
+	var wg sync.WaitGroup
+	for _, v := range process {
+		if p.stopped() {
+			break
+		}
+
+		data, err := src.read(v.Name)
+		if err != nil {
+			log.Printf("get file %s failed. error: %s\n", v.Name, err)
+			continue
+		}
+
+		wg.Add(1)
+		go func(v fileEntry, data []byte) {
+			defer wg.Done()
+			p.handle(v, data)
+		}(v, data)
 	}
+	wg.Wait()
 
-	bkt := tx.Bucket([]byte(p.bucket))
-	for k, v := range p.last {
-		bkt.Put([]byte(k), []byte(fmt.Sprintf("%d", v)))
+	for _, v := range process {
+		r, err := p.state.get(v.Name)
+		if err != nil {
+			log.Printf("get state of file %s failed. error: %s\n", v.Name, err)
+			continue
+		}
+		if r != nil && r.done() {
+			remove = append(remove, v)
+		}
 	}
 
-	err = tx.Commit()
-	if err != nil {
-		log.Printf("tx commit failed. error: %s\n", err)
-		if err = tx.Rollback(); err != nil {
-			log.Printf("tx rollback failed. error: %s\n", err)
+	for _, v := range remove {
+		if err := src.remove(v.Name); err != nil {
+			log.Printf("delete file %s failed. error: %s\n", v.Name, err)
+			continue
 		}
+		p.deleted(v)
+	}
+
+	if err := p.state.prune(); err != nil {
+		log.Printf("prune state failed. error: %s\n", err)
 	}
 }
 
-//
-func (p *ftpReceiver) getFileList() {
-	var err error
-	ticker := time.NewTicker(time.Duration(p.period) * time.Second)
-	for {
-		// connect to ftp
-		p.client, err = ftp.Connect(p.addr)
-		if err != nil {
-			log.Printf("connect to ftp failed. error: %s", err)
-		} else {
-			err = p.client.Login(p.username, p.password)
-			if err != nil {
-				log.Printf("login to ftp failed. error: %s", err)
-			} else {
-				lst, err := p.client.List(p.dirPath)
-				if err != nil {
-					log.Printf("list dir %s failed. error: %s\n", p.dirPath, err)
-				} else {
-					p.parseFilesList(lst)
-					p.presist()
-					p.processFiles()
-					p.deleteFiles()
-				}
-			}
+// resumed add the tarballs interrupted before the start to the tarballs to
+// process, unless they are listed on the source, and forget them. the
+// tarballs which can not be read are resumed again on the next start.
+func (p *baseReceiver) resumed(process []fileEntry) []fileEntry {
+	p.Lock()
+	defer p.Unlock()
+
+	listed := make(map[string]bool, len(process))
+	for _, v := range process {
+		listed[v.Name] = true
+	}
+	for _, v := range p.resume {
+		if !listed[v.Name] {
+			process = append(process, v)
+			p.processlist = append(p.processlist, v.Name)
 		}
-		<-ticker.C
 	}
+	p.resume = nil
+	return process
 }
 
 // if there are more than two tar.gz file of one host,
 // check all the files's timestamp, if timestamp less than or equal the timestamp
 // cahced, will not process this file, otherwise will process it.
-// then delete it.
+// then delete it. a file processed before is not processed again unless its
+// size or modification time changed, it is only deleted.
+func (p *baseReceiver) parseFilesList(lst []fileEntry) (process, remove []fileEntry) {
+	p.Lock()
+	defer p.Unlock()
 
-// 9117-MMA*06B86A1-rc_06B86A1_VIOC3-1516690201.tar.gz
-// 9117-MMA is machine model
-// rc_06B86A1_VIOC3 is LPARNumberName
-// 06B86A1 is serial number
-// 1516690201 is report time
-func (p *ftpReceiver) parseFilesList(lst []*ftp.Entry) {
-	var (
-		lparname string
-	)
 	p.deletelist = make([]string, 0)
 	p.processlist = make([]string, 0)
 
 	for _, v := range lst {
-		p.deletelist = append(p.deletelist, v.Name)
+		switch p.accept(v) {
+		case actionProcess:
+			process = append(process, v)
+			p.processlist = append(p.processlist, v.Name)
+		case actionRemove:
+			remove = append(remove, v)
+			p.deletelist = append(p.deletelist, v.Name)
+		}
+	}
+	return process, remove
+}
 
-		array := strings.Split(v.Name, "*")
-		if len(array) != 2 {
-			log.Printf("filename %s field format wrong when split it by sep *\n", v.Name)
-			continue
+// what to do with a listed tarball
+const (
+	actionKeep = iota
+	actionProcess
+	actionRemove
+)
+
+// accept return what to do with a tarball, a new tarball is marked as seen.
+// must be called with the lock held.
+func (p *baseReceiver) accept(v fileEntry) int {
+	lparname, err := lparName(v.Name)
+	if err != nil {
+		log.Println(err)
+		return actionRemove
+	}
+
+	r, err := p.state.get(v.Name)
+	if err != nil {
+		log.Printf("get state of file %s failed. error: %s\n", v.Name, err)
+		return actionKeep
+	}
+	if r != nil && r.same(v) {
+		if r.done() {
+			return actionRemove
 		}
+		// resume a tarball whose processing was interrupted
+		return actionProcess
+	}
 
-		array1 := strings.Split(array[1], "-")
-		if len(array1) != 3 {
-			log.Printf("filename %s field format wrong when split it by sep -\n", v.Name)
-			continue
+	lastTimestamp, exist := p.last[lparname]
+	if exist {
+		if v.Time.Unix() <= lastTimestamp {
+			log.Printf("file %s current timestamp(%d) less than or equal last one(%d), skip it.\n", v.Name, v.Time.Unix(), lastTimestamp)
+			return actionRemove
 		}
+	}
 
-		lparname = array1[1]
+	if !p.setState(v, stateSeen, "") {
+		return actionKeep
+	}
+	return actionProcess
+}
 
-		lastTimestamp, exist := p.last[lparname]
-		if exist {
-			if v.Time.Unix() <= lastTimestamp {
-				log.Printf("file %s current timestamp(%d) less than or equal last one(%d), skip it.\n", v.Name, v.Time.Unix(), lastTimestamp)
-				continue
-			}
-		}
+// handle process the content of a tarball, and return true once its
+// metrics have been added to the accumulator.
+func (p *baseReceiver) handle(v fileEntry, data []byte) bool {
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
 
+	dup, err := p.claim(v.Name, checksum)
+	if err != nil {
+		log.Printf("get state of file %s failed. error: %s\n", v.Name, err)
+		return false
+	}
+	if dup != "" {
+		log.Printf("file %s has the same content as file %s, skip it.\n", v.Name, dup)
+		return p.setState(v, stateAcknowledged, checksum)
+	}
+	defer func() {
+		p.Lock()
+		delete(p.inflight, checksum)
+		p.Unlock()
+	}()
+
+	if !p.setState(v, stateDownloaded, checksum) {
+		return false
+	}
+
+	r, err := nmonTgzFileReader(data)
+	if err != nil {
+		// a broken tarball will not get better by trying again
+		log.Printf("extract file %s failed, error: %s\n", v.Name, err)
+		return p.setState(v, stateAcknowledged, checksum)
+	}
+	if !p.setState(v, stateParsed, checksum) {
+		return false
+	}
+
+	rp := &report{name: v.Name, data: r, done: make(chan error, 1)}
+	select {
+	case p.dataChan <- rp:
+	case <-p.done:
+		return false
+	}
+
+	select {
+	case err = <-rp.done:
+	case <-p.done:
+		return false
+	}
+	if err != nil {
+		log.Printf("parse file %s failed. error: %s\n", v.Name, err)
+	}
+
+	lparname, _ := lparName(v.Name)
+	if err := p.state.acknowledge(v, checksum, lparname); err != nil {
+		log.Printf("set state of file %s failed. error: %s\n", v.Name, err)
+		return false
+	}
+
+	p.Lock()
+	if v.Time.Unix() > p.last[lparname] {
 		p.last[lparname] = v.Time.Unix()
-		p.processlist = append(p.processlist, v.Name)
 	}
+	p.Unlock()
+	return true
+}
+
+// claim return the name of a tarball with the same content which is
+// already processed or being processed, otherwise the content is claimed
+// for the named tarball and "" is returned.
+func (p *baseReceiver) claim(name, checksum string) (string, error) {
+	p.Lock()
+	defer p.Unlock()
 
+	if dup, ok := p.inflight[checksum]; ok {
+		return dup, nil
+	}
+	dup, err := p.state.duplicate(checksum)
+	if err != nil || dup != "" {
+		return dup, err
+	}
+	p.inflight[checksum] = name
+	return "", nil
 }
 
-// delete tarball from ftp server
-func (p *ftpReceiver) deleteFiles() {
-	var err error
-	for _, v := range p.deletelist {
-		err = p.client.Delete(filepath.Join(p.dirPath, v))
-		if err != nil {
-			log.Printf("delete file %s from server failed. error: %s\n", v, err)
-		}
+// deleted mark a tarball deleted from the source
+func (p *baseReceiver) deleted(v fileEntry) {
+	r, err := p.state.get(v.Name)
+	if err != nil || r == nil {
+		return
 	}
+	p.setState(v, stateDeleted, r.Checksum)
 }
 
-// call goruntines to process multi hosts's nmon tarball
-func (p *ftpReceiver) processFiles() {
-	for _, v := range p.processlist {
-		resp, err := p.client.Retr(filepath.Join(p.dirPath, v))
-		if err != nil {
-			log.Printf("get file %s from server failed. error: %s\n", v, err)
-			continue
-		}
+//
+func (p *baseReceiver) setState(v fileEntry, state, checksum string) bool {
+	if err := p.state.set(v, state, checksum); err != nil {
+		log.Printf("set state of file %s failed. error: %s\n", v.Name, err)
+		return false
+	}
+	return true
+}
 
-		data, err := ioutil.ReadAll(resp)
-		if err != nil {
-			log.Printf("read from resp of file: %s failed. error: %s\n", v, err)
-		}
-		go func(name string, d []byte) {
-			p.wg.Add(1)
-			r, err := nmonTgzFileReader(d)
-			if err != nil {
-				log.Printf("extract file %s failed, error: %s\n", name, err)
-			} else {
-				// parse to json format and send to socket
-				p.dataChan <- r
-			}
-			p.wg.Done()
-		}(v, data)
+// 9117-MMA*06B86A1-rc_06B86A1_VIOC3-1516690201.tar.gz
+// 9117-MMA is machine model
+// rc_06B86A1_VIOC3 is LPARNumberName
+// 06B86A1 is serial number
+// 1516690201 is report time
+func splitFileName(name string) ([]string, error) {
+	array := strings.Split(name, "*")
+	if len(array) != 2 {
+		return nil, fmt.Errorf("filename %s field format wrong when split it by sep *", name)
+	}
 
-		resp.Close()
+	array1 := strings.Split(array[1], "-")
+	if len(array1) != 3 {
+		return nil, fmt.Errorf("filename %s field format wrong when split it by sep -", name)
 	}
-	p.wg.Wait()
+	return array1, nil
+}
+
+// lparName return the LPARNumberName part of a tarball name
+func lparName(name string) (string, error) {
+	array, err := splitFileName(name)
+	if err != nil {
+		return "", err
+	}
+	return array[1], nil
+}
+
+// reportTime return the report time part of a tarball name
+func reportTime(name string) (time.Time, error) {
+	array, err := splitFileName(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	ts := array[2]
+	if i := strings.Index(ts, "."); i >= 0 {
+		ts = ts[:i]
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("filename %s report time is wrong", name)
+	}
+	return time.Unix(sec, 0), nil
+}
+
+// isTarball return true for the names of nmon tarballs, temporary files of
+// rsync or scp which are still being written are skipped.
+func isTarball(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	return strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz")
 }
 
 // expression the nmon tar.gz file
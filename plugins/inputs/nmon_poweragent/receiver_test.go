@@ -0,0 +1,471 @@
+package nmon
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTarball    = "9117-MMA*06B86A1-rc_06B86A1_VIOC3-1516690201.tar.gz"
+	testOldTarball = "9117-MMA*06B86A1-rc_06B86A1_VIOC3-1516690101.tar.gz"
+	testNmonData   = "AAA,progname,topas_nmon\n"
+)
+
+// newTestReceiverConfig return a receiver config using a temporary
+// directory and db, the nmon data of the processed reports is sent to the
+// returned channel.
+func newTestReceiverConfig(t *testing.T) (receiverConfig, chan string, func()) {
+	dir, err := ioutil.TempDir("", "nmon_poweragent")
+	require.NoError(t, err)
+
+	db, err := bolt.Open(filepath.Join(dir, "test.db"), 0600, nil)
+	require.NoError(t, err)
+
+	ch := make(chan *report)
+	out := make(chan string, 10)
+	go func() {
+		for r := range ch {
+			out <- string(r.data)
+			r.done <- nil
+		}
+	}()
+
+	cfg := receiverConfig{
+		PullPeriod:    3600,
+		LocalDirPath:  filepath.Join(dir, "spool"),
+		WriteDataChan: ch,
+		DB:            db,
+	}
+	require.NoError(t, os.Mkdir(cfg.LocalDirPath, 0755))
+	return cfg, out, func() {
+		close(ch)
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func writeTarball(t *testing.T, dir, name string, mtime time.Time) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, testTgz(t, testNmonData), 0644))
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+	return path
+}
+
+func testTgz(t *testing.T, data string) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name: "report.nmon",
+		Mode: 0644,
+		Size: int64(len(data)),
+	}))
+	_, err := tw.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func TestNewReceiver(t *testing.T) {
+	r, err := newReceiver(receiverConfig{})
+	require.NoError(t, err)
+	assert.IsType(t, &ftpReceiver{}, r)
+
+	r, err = newReceiver(receiverConfig{Receiver: "dir"})
+	require.NoError(t, err)
+	assert.IsType(t, &dirReceiver{}, r)
+
+	_, err = newReceiver(receiverConfig{Receiver: "http"})
+	assert.Error(t, err)
+
+	// the uploads have to be authenticated
+	_, err = newReceiver(receiverConfig{Receiver: "http", HttpListen: "127.0.0.1:0"})
+	assert.Error(t, err)
+
+	_, err = newReceiver(receiverConfig{Receiver: "sftp", SftpPrivateKey: "/nonexistent"})
+	assert.Error(t, err)
+
+	_, err = newReceiver(receiverConfig{Receiver: "nfs"})
+	assert.Error(t, err)
+}
+
+func TestNewSftpReceiverHostKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nmon")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	keyPath := filepath.Join(dir, "id_rsa")
+	require.NoError(t, ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}), 0600))
+	knownHosts := filepath.Join(dir, "known_hosts")
+	require.NoError(t, ioutil.WriteFile(knownHosts, nil, 0644))
+
+	cfg := receiverConfig{Receiver: "sftp", SftpPrivateKey: keyPath}
+	// the host key must be verified
+	_, err = newReceiver(cfg)
+	assert.Error(t, err)
+
+	cfg.SftpHostKey = "not a key"
+	_, err = newReceiver(cfg)
+	assert.Error(t, err)
+
+	cfg.SftpHostKey = ""
+	cfg.SftpKnownHosts = knownHosts
+	r, err := newReceiver(cfg)
+	require.NoError(t, err)
+	assert.IsType(t, &sftpReceiver{}, r)
+
+	cfg.SftpKnownHosts = filepath.Join(dir, "nonexistent")
+	_, err = newReceiver(cfg)
+	assert.Error(t, err)
+
+	cfg.SftpKnownHosts = ""
+	cfg.SftpInsecureIgnoreHostKey = true
+	r, err = newReceiver(cfg)
+	require.NoError(t, err)
+	assert.IsType(t, &sftpReceiver{}, r)
+}
+
+func TestReportTime(t *testing.T) {
+	tm, err := reportTime(testTarball)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1516690201), tm.Unix())
+
+	_, err = reportTime("report.tar.gz")
+	assert.Error(t, err)
+}
+
+func TestDirReceiver(t *testing.T) {
+	cfg, ch, cleanup := newTestReceiverConfig(t)
+	defer cleanup()
+
+	now := time.Now()
+	tarball := writeTarball(t, cfg.LocalDirPath, testTarball, now)
+
+	// a file still being written by rsync is left alone
+	partial := filepath.Join(cfg.LocalDirPath, "."+testTarball+".Xyz123")
+	require.NoError(t, ioutil.WriteFile(partial, []byte("partial"), 0644))
+
+	r := newDirReceiver(cfg)
+	require.NoError(t, r.Start())
+	defer r.Stop()
+
+	select {
+	case data := <-ch:
+		assert.Equal(t, testNmonData, data)
+	case <-time.After(5 * time.Second):
+		t.Fatal("tarball was not processed")
+	}
+	// wait for the first pull to finish
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(tarball); os.IsNotExist(err) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	_, err := os.Stat(partial)
+	assert.NoError(t, err)
+
+	// a file older than the last processed one of the same host is skipped
+	older := writeTarball(t, cfg.LocalDirPath, testOldTarball, now.Add(-time.Hour))
+	r.getFileList()
+
+	assert.Len(t, ch, 0)
+	_, err = os.Stat(older)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(tarball)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDirReceiverResume(t *testing.T) {
+	cfg, ch, cleanup := newTestReceiverConfig(t)
+	defer cleanup()
+
+	now := time.Now()
+	tarball := writeTarball(t, cfg.LocalDirPath, testTarball, now)
+	fi, err := os.Stat(tarball)
+	require.NoError(t, err)
+	v := fileEntry{Name: testTarball, Size: fi.Size(), Time: now}
+
+	// simulate a crash after the tarball was handed to the processers
+	state := &fileState{db: cfg.DB}
+	require.NoError(t, state.init(map[string]int64{}))
+	require.NoError(t, state.set(v, stateParsed, "0123"))
+
+	r := newDirReceiver(cfg)
+	require.NoError(t, r.start())
+	r.getFileList()
+	require.NoError(t, r.stop())
+
+	require.Len(t, ch, 1)
+	assert.Equal(t, testNmonData, <-ch)
+
+	rec, err := state.get(testTarball)
+	require.NoError(t, err)
+	assert.Equal(t, stateDeleted, rec.State)
+	_, err = os.Stat(tarball)
+	assert.True(t, os.IsNotExist(err))
+}
+
+// testSource is a source which does not list its tarballs
+type testSource map[string][]byte
+
+func (s testSource) list() ([]fileEntry, error) {
+	return nil, nil
+}
+
+func (s testSource) read(name string) ([]byte, error) {
+	data, ok := s[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return data, nil
+}
+
+func (s testSource) remove(name string) error {
+	delete(s, name)
+	return nil
+}
+
+func TestReceiverResumeUnlisted(t *testing.T) {
+	cfg, ch, cleanup := newTestReceiverConfig(t)
+	defer cleanup()
+
+	data := testTgz(t, testNmonData)
+	v := fileEntry{Name: testTarball, Size: int64(len(data)), Time: time.Now()}
+	gone := fileEntry{Name: testOldTarball, Size: 1, Time: time.Now()}
+
+	state := &fileState{db: cfg.DB}
+	require.NoError(t, state.init(map[string]int64{}))
+	require.NoError(t, state.set(v, stateDownloaded, "0123"))
+	require.NoError(t, state.set(gone, stateSeen, ""))
+
+	// the interrupted tarballs are processed on the first pull, even when
+	// the source does not list them
+	src := testSource{testTarball: data}
+	r := newBaseReceiver(cfg)
+	require.NoError(t, r.start())
+	r.sync(src)
+	require.NoError(t, r.stop())
+
+	require.Len(t, ch, 1)
+	assert.Equal(t, testNmonData, <-ch)
+	assert.Len(t, src, 0)
+	rec, err := state.get(testTarball)
+	require.NoError(t, err)
+	assert.Equal(t, stateDeleted, rec.State)
+
+	// the tarball which could not be read is resumed on the next start
+	rec, err = state.get(testOldTarball)
+	require.NoError(t, err)
+	assert.Equal(t, stateSeen, rec.State)
+	interrupted, err := state.recover()
+	require.NoError(t, err)
+	require.Len(t, interrupted, 1)
+	assert.Equal(t, testOldTarball, interrupted[0].Name)
+}
+
+func TestDirReceiverAcknowledgedNotReprocessed(t *testing.T) {
+	cfg, ch, cleanup := newTestReceiverConfig(t)
+	defer cleanup()
+
+	now := time.Now()
+	tarball := writeTarball(t, cfg.LocalDirPath, testTarball, now)
+	fi, err := os.Stat(tarball)
+	require.NoError(t, err)
+	v := fileEntry{Name: testTarball, Size: fi.Size(), Time: now}
+
+	// simulate a crash after the metrics were added, but before the
+	// tarball was deleted
+	state := &fileState{db: cfg.DB}
+	require.NoError(t, state.init(map[string]int64{}))
+	require.NoError(t, state.acknowledge(v, "0123", "rc_06B86A1_VIOC3"))
+
+	r := newDirReceiver(cfg)
+	require.NoError(t, r.start())
+	r.getFileList()
+
+	assert.Len(t, ch, 0)
+	_, err = os.Stat(tarball)
+	assert.True(t, os.IsNotExist(err))
+
+	// the same content under another name is not processed either
+	writeTarball(t, cfg.LocalDirPath, "9117-MMA*06B86A1-rc_06B86A1_VIOC3-1516690301.tar.gz", now.Add(time.Hour))
+	writeTarball(t, cfg.LocalDirPath, "9117-MMA*06B86A1-rc_06B86A1_VIOC3-1516690401.tar.gz", now.Add(2*time.Hour))
+	r.getFileList()
+	require.NoError(t, r.stop())
+
+	assert.Len(t, ch, 1)
+	files, err := ioutil.ReadDir(cfg.LocalDirPath)
+	require.NoError(t, err)
+	assert.Len(t, files, 0)
+}
+
+// upload uploads a tarball to an upload receiver, with the given credentials
+// when the username is set, and returns the status of the response.
+func upload(t *testing.T, r *uploadReceiver, name string, data []byte, username, password string) int {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	fw, err := w.CreateFormFile("file", name)
+	require.NoError(t, err)
+	fw.Write(data)
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest("POST", uploadEndpoint, &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+	rw := httptest.NewRecorder()
+	r.mux.ServeHTTP(rw, req)
+	return rw.Code
+}
+
+func TestUploadReceiver(t *testing.T) {
+	cfg, ch, cleanup := newTestReceiverConfig(t)
+	defer cleanup()
+	cfg.HttpListen = "127.0.0.1:0"
+	cfg.BasicUsername = "nmon"
+	cfg.BasicPassword = "secret"
+
+	r, err := newUploadReceiver(cfg)
+	require.NoError(t, err)
+	require.NoError(t, r.Start())
+	defer r.Stop()
+
+	upload := func(name string, data []byte) int {
+		return upload(t, r, name, data, "nmon", "secret")
+	}
+
+	assert.Equal(t, http.StatusAccepted, upload(testTarball, testTgz(t, testNmonData)))
+	require.Len(t, ch, 1)
+	assert.Equal(t, testNmonData, <-ch)
+
+	// a retried upload and an older report of the same host are ignored
+	assert.Equal(t, http.StatusOK, upload(testTarball, testTgz(t, testNmonData)))
+	assert.Equal(t, http.StatusOK, upload(testOldTarball, testTgz(t, testNmonData)))
+	assert.Len(t, ch, 0)
+
+	assert.Equal(t, http.StatusBadRequest, upload("report.tar.gz", testTgz(t, testNmonData)))
+}
+
+func TestUploadReceiverUnauthorized(t *testing.T) {
+	cfg, ch, cleanup := newTestReceiverConfig(t)
+	defer cleanup()
+	cfg.HttpListen = "127.0.0.1:0"
+	cfg.BasicUsername = "nmon"
+	cfg.BasicPassword = "secret"
+
+	r, err := newUploadReceiver(cfg)
+	require.NoError(t, err)
+	require.NoError(t, r.Start())
+	defer r.Stop()
+
+	assert.Equal(t, http.StatusUnauthorized, upload(t, r, testTarball, testTgz(t, testNmonData), "", ""))
+	assert.Equal(t, http.StatusUnauthorized, upload(t, r, testTarball, testTgz(t, testNmonData), "nmon", "guess"))
+	assert.Len(t, ch, 0)
+
+	req := httptest.NewRequest("GET", "/debug", nil)
+	rw := httptest.NewRecorder()
+	r.mux.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusUnauthorized, rw.Code)
+}
+
+// writeCertificate writes a self-signed certificate of 127.0.0.1 and its key
+// to dir, and returns their paths.
+func writeCertificate(t *testing.T, dir string) (string, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: der,
+	}), 0644))
+	require.NoError(t, ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}), 0600))
+	return certPath, keyPath
+}
+
+func TestUploadReceiverTLS(t *testing.T) {
+	cfg, _, cleanup := newTestReceiverConfig(t)
+	defer cleanup()
+	cfg.HttpListen = "127.0.0.1:0"
+	cfg.BasicUsername = "nmon"
+	cfg.BasicPassword = "secret"
+	cfg.TlsCert, cfg.TlsKey = writeCertificate(t, cfg.LocalDirPath)
+
+	r, err := newUploadReceiver(cfg)
+	require.NoError(t, err)
+	require.NoError(t, r.Start())
+	defer r.Stop()
+
+	cert, err := ioutil.ReadFile(cfg.TlsCert)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(cert))
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{RootCAs: pool},
+	}}
+
+	url := "https://" + r.listener.Addr().String() + "/debug"
+	req, err := http.NewRequest("GET", url, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req.SetBasicAuth("nmon", "secret")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// a bad certificate is an error
+	cfg.TlsKey = cfg.TlsCert
+	r2, err := newUploadReceiver(cfg)
+	require.NoError(t, err)
+	assert.Error(t, r2.Start())
+}
@@ -0,0 +1,325 @@
+package nmon
+
+import (
+	"bufio"
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// layout of the time and date of the AAA lines, ie 15:56:34 24-JAN-2018
+const aaaLayout = "15:04:05 02-Jan-2006"
+
+// record is the part of an nmon metric of a device, in the schema the
+// poweragent backend reads: a device type, an instance and the former
+// counter names of the fields.
+type record struct {
+	deviceType string
+	instance   string
+	fields     map[string]interface{}
+}
+
+// section tells how the metrics of an nmon section become records.
+type section struct {
+	deviceType string
+	// counters are the names of the fields of the sections with a line per
+	// instance, the fields without one are dropped.
+	counters map[string]string
+	// suffixes are the names of the fields of the sections with a column
+	// per instance and counter, by the suffix of the column, ie -read-kb/s.
+	suffixes map[string]string
+	// counter is the name of the field of the sections with a column per
+	// instance, ie DISKBUSY.
+	counter string
+}
+
+var cpuCounters = map[string]string{
+	"user": "user",
+	"sys":  "sys",
+	"wait": "wait",
+	"idle": "idle",
+}
+
+// sections are the sections of the former nmon_poweragent parser, the
+// metrics of the other sections are kept as they are.
+var sections = map[string]*section{
+	"CPU":  {deviceType: "cpu", counters: cpuCounters},
+	"PCPU": {deviceType: "cpu", counters: cpuCounters},
+	"SCPU": {deviceType: "cpu", counters: cpuCounters},
+	"CPU_ALL": {deviceType: "cpu", counters: map[string]string{
+		"user":         "user",
+		"sys":          "sys",
+		"wait":         "wait",
+		"idle":         "idle",
+		"physicalcpus": "physicalCPUS",
+	}},
+	"PCPU_ALL": {deviceType: "cpu", counters: map[string]string{
+		"user":              "user",
+		"sys":               "sys",
+		"wait":              "wait",
+		"idle":              "idle",
+		"entitled_capacity": "entitledCapacity",
+	}},
+	"SCPU_ALL": {deviceType: "cpu", counters: cpuCounters},
+	"MEM": {deviceType: "mem", counters: map[string]string{
+		"real_free":         "RealFree",
+		"virtual_free":      "VirtualFree",
+		"real_free(mb)":     "RealFreeMB",
+		"virtual_free(mb)":  "VirtualFreeMB",
+		"real_total(mb)":    "RealTotalMB",
+		"virtual_total(mb)": "VritualTotalMB",
+	}},
+	"MEMNEW": {deviceType: "mem", counters: map[string]string{
+		"process": "process",
+		"fscache": "fscache",
+		"system":  "system",
+		"free":    "free",
+		"pinned":  "pinned",
+		"user":    "user",
+	}},
+	"MEMUSE": {deviceType: "mem", counters: map[string]string{
+		"numperm":   "numperm",
+		"minperm":   "minperm",
+		"maxperm":   "maxperm",
+		"minfree":   "minfree",
+		"maxfree":   "maxfree",
+		"numclient": "numclient",
+		"maxclient": "maxclient",
+	}},
+	"PAGE": {deviceType: "mem", counters: map[string]string{
+		"faults":   "faults",
+		"pgin":     "pgin",
+		"pgout":    "pgout",
+		"pgsin":    "pgsin",
+		"pgsout":   "pgsout",
+		"reclaims": "reclaims",
+		"scans":    "scans",
+		"cycles":   "cycles",
+	}},
+	"PROC": {deviceType: "process", counters: map[string]string{
+		"runnable":      "runnable",
+		"swap-in":       "swapin",
+		"pswitch":       "pswitch",
+		"syscall":       "syscall",
+		"read":          "read",
+		"write":         "write",
+		"fork":          "fork",
+		"exec":          "exec",
+		"sem":           "sem",
+		"msg":           "msg",
+		"asleep_bufio":  "asleepbufio",
+		"asleep_rawio":  "asleeprawio",
+		"asleep_diocio": "asleepdiocio",
+	}},
+	"TOP": {deviceType: "process", counters: map[string]string{
+		"cpu":     "cpu",
+		"usr":     "usr",
+		"sys":     "sys",
+		"threads": "threads",
+		"size":    "size",
+		"restext": "restext",
+		"resdata": "resdata",
+		"chario":  "chario",
+		"ram":     "ram",
+		"paging":  "paging",
+	}},
+	"FILE": {deviceType: "file", counters: map[string]string{
+		"iget":     "iget",
+		"namei":    "namei",
+		"dirblk":   "dirblk",
+		"readch":   "readch",
+		"writech":  "writech",
+		"ttyrawch": "ttyrawch",
+		"ttycanch": "ttycanch",
+		"ttyoutch": "ttyoutch",
+	}},
+	"JFSFILE":  {deviceType: "file", counter: "u_p"},
+	"JFSINODE": {deviceType: "file", counter: "inode"},
+	"NET": {deviceType: "net", suffixes: map[string]string{
+		"-read-kb/s":  "read",
+		"-write-kb/s": "write",
+	}},
+	"NETPACKET": {deviceType: "net", suffixes: map[string]string{
+		"-reads/s":  "packetread",
+		"-writes/s": "packetwrite",
+	}},
+	"NETSIZE": {deviceType: "net", suffixes: map[string]string{
+		"-readsize":  "readsize",
+		"-writesize": "writesize",
+	}},
+	"NETERROR": {deviceType: "net", suffixes: map[string]string{
+		"-ierrs":      "ierrs",
+		"-oerrs":      "oerrs",
+		"-collisions": "collisions",
+	}},
+	"IOADAPT": {deviceType: "san", suffixes: map[string]string{
+		"_read-kb/s":  "read",
+		"_write-kb/s": "write",
+		"_xfer-tps":   "xfer-tps",
+	}},
+	"DISKBUSY":   {deviceType: "disk", counter: "busy"},
+	"DISKREAD":   {deviceType: "disk", counter: "read"},
+	"DISKWRITE":  {deviceType: "disk", counter: "write"},
+	"DISKXFER":   {deviceType: "disk", counter: "xfer"},
+	"DISKRXFER":  {deviceType: "disk", counter: "rxfer"},
+	"DISKBSIZE":  {deviceType: "disk", counter: "bsize"},
+	"DISKRIO":    {deviceType: "disk", counter: "rio"},
+	"DISKWIO":    {deviceType: "disk", counter: "wio"},
+	"DISKAVGRIO": {deviceType: "disk", counter: "avgrio"},
+	"DISKAVGWIO": {deviceType: "disk", counter: "avgwio"},
+	"LPAR": {deviceType: "lpar", counters: map[string]string{
+		"physicalcpu": "physicalcpu",
+		"virtualcpus": "virtualcpus",
+		"logicalcpus": "logicalcpus",
+		"poolcpus":    "poocpus",
+		"entitled":    "entitled",
+		"weight":      "weight",
+		"poolidle":    "poolidle",
+		"usedallcpu":  "usedallcpu",
+		"usedpoolcpu": "usedpoolcpu",
+		"sharedcpu":   "sharedcpu",
+		"capped":      "capped",
+		"ec_user":     "ecuser",
+		"ec_sys":      "ecsys",
+		"ec_wait":     "ecwait",
+		"ec_idle":     "ecidle",
+		"vp_user":     "vpuser",
+		"vp_sys":      "vpsys",
+		"vp_wait":     "vpwait",
+		"vp_idle":     "vpidle",
+		"folded":      "folded",
+		"pool_id":     "poolid",
+	}},
+	"POOLS": {deviceType: "pools", counters: map[string]string{
+		"shcpus_in_sys":          "shcpu_in_sys",
+		"max_pool_capacity":      "max_pool_capacity",
+		"entitled_pool_capacity": "entitled_pool_capacity",
+		"pool_max_time":          "pool_max_time",
+		"pool_busy_time":         "pool_busy_time",
+		"shcpu_tot_time":         "shcpu_tot_time",
+		"shcpu_busy_time":        "shcpu_busy_time",
+		"entitled":               "entitled",
+	}},
+}
+
+// records returns the records of a metric of the nmon data format, one for
+// every instance of the metric, ordered by instance.
+func records(m telegraf.Metric) []record {
+	name := strings.TrimPrefix(m.Name(), "nmon_")
+	fields := m.Fields()
+	object := m.Tags()["object"]
+
+	s, ok := sections[name]
+	if !ok {
+		return []record{{
+			deviceType: strings.ToLower(name),
+			instance:   object,
+			fields:     fields,
+		}}
+	}
+
+	byInstance := make(map[string]map[string]interface{})
+	add := func(instance, counter string, value interface{}) {
+		if _, ok := byInstance[instance]; !ok {
+			byInstance[instance] = make(map[string]interface{})
+		}
+		byInstance[instance][counter] = value
+	}
+	switch {
+	case s.counter != "":
+		for k, v := range fields {
+			add(k, s.counter, v)
+		}
+	case s.suffixes != nil:
+		for k, v := range fields {
+			for suffix, counter := range s.suffixes {
+				if strings.HasSuffix(k, suffix) {
+					add(strings.TrimSuffix(k, suffix), counter, v)
+				}
+			}
+		}
+	default:
+		instance := object
+		if name == "POOLS" {
+			// the instance of a pool is its id
+			if id, ok := fields["pool_id"].(float64); ok {
+				instance = strconv.FormatFloat(id, 'f', -1, 64)
+			}
+		}
+		for k, v := range fields {
+			if counter, ok := s.counters[k]; ok {
+				add(instance, counter, v)
+			}
+		}
+	}
+
+	instances := make([]string, 0, len(byInstance))
+	for instance := range byInstance {
+		instances = append(instances, instance)
+	}
+	sort.Strings(instances)
+
+	recs := make([]record, 0, len(instances))
+	for _, instance := range instances {
+		recs = append(recs, record{
+			deviceType: s.deviceType,
+			instance:   instance,
+			fields:     byInstance[instance],
+		})
+	}
+	return recs
+}
+
+// tags returns the tags of a record: its device type and instance, and the
+// system of the metric in the s, l and l_id tags.
+func (r record) tags(m telegraf.Metric, start string) map[string]string {
+	mtags := m.Tags()
+	tags := map[string]string{
+		"device_type": r.deviceType,
+		"a_id":        r.instance,
+		"v":           version,
+	}
+	if mtags["machine_type"] != "" || mtags["serial_number"] != "" {
+		tags["s"] = mtags["machine_type"] + "*" + mtags["serial_number"]
+	}
+	if lpar, ok := mtags["lpar"]; ok {
+		tags["l"] = lpar
+		tags["l_id"] = mtags["lpar_id"]
+	}
+	if start != "" {
+		tags["t_f"] = start
+	}
+	return tags
+}
+
+// startTime returns the start of a recording, in seconds since the epoch,
+// from its AAA time and date lines.
+//
+//   AAA,time,15:56:34
+//   AAA,date,24-JAN-2018
+func startTime(data []byte, loc *time.Location) string {
+	var clock, date string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	// the AAA lines are before the first snapshot
+	for scanner.Scan() && !strings.HasPrefix(scanner.Text(), "ZZZZ,") {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, "AAA,time,") {
+			clock = strings.TrimPrefix(line, "AAA,time,")
+		}
+		if strings.HasPrefix(line, "AAA,date,") {
+			date = strings.TrimPrefix(line, "AAA,date,")
+		}
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	t, err := time.ParseInLocation(aaaLayout, clock+" "+date, loc)
+	if err != nil {
+		return ""
+	}
+	return strconv.FormatInt(t.Unix(), 10)
+}
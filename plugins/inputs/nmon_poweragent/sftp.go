@@ -0,0 +1,148 @@
+package nmon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// timeout of connecting to the sftp server
+const sftpDialTimeout = 30 * time.Second
+
+// sftpReceiver pulls nmon tarballs from a sftp server, authenticating with
+// a private key.
+type sftpReceiver struct {
+	*baseReceiver
+
+	client  *sftp.Client
+	addr    string
+	dirPath string
+	config  *ssh.ClientConfig
+}
+
+//
+func newSftpReceiver(cfg receiverConfig) (*sftpReceiver, error) {
+	key, err := ioutil.ReadFile(cfg.SftpPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("read private key %s failed. error: %s", cfg.SftpPrivateKey, err)
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key %s failed. error: %s", cfg.SftpPrivateKey, err)
+	}
+
+	hostKeyCallback, err := sftpHostKeyCallback(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sftpReceiver{
+		baseReceiver: newBaseReceiver(cfg),
+		addr:         cfg.SftpServer,
+		dirPath:      cfg.SftpDirPath,
+		config: &ssh.ClientConfig{
+			User:            cfg.SftpUsername,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         sftpDialTimeout,
+		},
+	}, nil
+}
+
+// sftpHostKeyCallback return the check of the host key of the sftp server,
+// against sftp_host_key or the sftp_known_hosts file. the host key is only
+// left unchecked when sftp_insecure_ignore_host_key is set.
+func sftpHostKeyCallback(cfg receiverConfig) (ssh.HostKeyCallback, error) {
+	switch {
+	case cfg.SftpHostKey != "":
+		hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(cfg.SftpHostKey))
+		if err != nil {
+			return nil, fmt.Errorf("parse host key failed. error: %s", err)
+		}
+		return ssh.FixedHostKey(hostKey), nil
+	case cfg.SftpKnownHosts != "":
+		callback, err := knownhosts.New(cfg.SftpKnownHosts)
+		if err != nil {
+			return nil, fmt.Errorf("read known hosts %s failed. error: %s", cfg.SftpKnownHosts, err)
+		}
+		return callback, nil
+	case cfg.SftpInsecureIgnoreHostKey:
+		log.Printf("W! sftp_insecure_ignore_host_key is set, the host key of %s will not be verified", cfg.SftpServer)
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	return nil, fmt.Errorf("one of sftp_host_key and sftp_known_hosts must be set " +
+		"to verify the host key of the sftp server")
+}
+
+//
+func (p *sftpReceiver) Start() error {
+	if err := p.start(); err != nil {
+		return err
+	}
+
+	p.poll(p.getFileList)
+	return nil
+}
+
+// stop sftpReceiver
+func (p *sftpReceiver) Stop() error {
+	return p.stop()
+}
+
+//
+func (p *sftpReceiver) getFileList() {
+	conn, err := ssh.Dial("tcp", p.addr, p.config)
+	if err != nil {
+		log.Printf("connect to sftp server failed. error: %s", err)
+		return
+	}
+	defer conn.Close()
+
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		log.Printf("start sftp session failed. error: %s", err)
+		return
+	}
+	defer client.Close()
+
+	p.client = client
+	p.sync(p)
+}
+
+// list the tarballs on the sftp server
+func (p *sftpReceiver) list() ([]fileEntry, error) {
+	lst, err := p.client.ReadDir(p.dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("list dir %s failed. error: %s", p.dirPath, err)
+	}
+
+	entries := make([]fileEntry, 0, len(lst))
+	for _, v := range lst {
+		if !v.Mode().IsRegular() || !isTarball(v.Name()) {
+			continue
+		}
+		entries = append(entries, fileEntry{Name: v.Name(), Size: v.Size(), Time: v.ModTime()})
+	}
+	return entries, nil
+}
+
+// read a tarball from the sftp server
+func (p *sftpReceiver) read(name string) ([]byte, error) {
+	f, err := p.client.Open(path.Join(p.dirPath, name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+// delete a tarball from the sftp server
+func (p *sftpReceiver) remove(name string) error {
+	return p.client.Remove(path.Join(p.dirPath, name))
+}
@@ -0,0 +1,213 @@
+package nmon
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// states of a tarball, every transition is committed to the db before the
+// next step starts, so processing resumes at the right step after a crash.
+const (
+	stateSeen         = "seen"         // listed on the source
+	stateDownloaded   = "downloaded"   // content read and checksummed
+	stateParsed       = "parsed"       // handed to the processers
+	stateAcknowledged = "acknowledged" // metrics added to the accumulator
+	stateDeleted      = "deleted"      // removed from the source
+)
+
+// bucket names
+var (
+	lastBucket      = []byte("ftpfilefetcher") // last report time of each host
+	filesBucket     = []byte("files")          // fileRecord of each tarball
+	checksumsBucket = []byte("checksums")      // tarball name of each checksum
+)
+
+// stateRetention is how long the record of a deleted tarball is kept, a
+// tarball showing up again within it is not processed twice.
+const stateRetention = 7 * 24 * time.Hour
+
+// fileRecord is the state of one tarball, stored as json in the files bucket
+// keyed by the tarball name.
+type fileRecord struct {
+	State    string    `json:"state"`
+	Size     int64     `json:"size"`
+	ModTime  int64     `json:"mtime"`
+	Checksum string    `json:"checksum,omitempty"`
+	Updated  time.Time `json:"updated"`
+}
+
+// done return true if the metrics of the tarball have been added to the
+// accumulator.
+func (r *fileRecord) done() bool {
+	return r.State == stateAcknowledged || r.State == stateDeleted
+}
+
+// same return true if the record describes the tarball v, a tarball
+// replaced on the source with new content is processed again.
+func (r *fileRecord) same(v fileEntry) bool {
+	return r.Size == v.Size && r.ModTime == v.Time.Unix()
+}
+
+// fileState is the bolt backed store of the tarball records. It is not kept
+// in the state store of the agent, which is only saved every flush interval,
+// as every transition, and the checksum of an acknowledged tarball, must be
+// on disk before the next step starts.
+type fileState struct {
+	db *bolt.DB
+}
+
+// init create the buckets and load the last report time of each host
+func (s *fileState) init(last map[string]int64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{lastBucket, filesBucket, checksumsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return fmt.Errorf("create bucket %s failed. error: %s", name, err)
+			}
+		}
+
+		return tx.Bucket(lastBucket).ForEach(func(key, value []byte) error {
+			iv, err := strconv.ParseInt(string(value), 10, 64)
+			if err != nil {
+				return err
+			}
+			last[string(key)] = iv
+			return nil
+		})
+	})
+}
+
+// recover return the tarballs whose processing was interrupted, so they
+// are processed again.
+func (s *fileState) recover() ([]fileEntry, error) {
+	var interrupted []fileEntry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(filesBucket).ForEach(func(key, value []byte) error {
+			var r fileRecord
+			if err := json.Unmarshal(value, &r); err != nil {
+				log.Printf("W! state of file %s is broken. error: %s\n", key, err)
+				return nil
+			}
+			if !r.done() {
+				log.Printf("I! resume file %s interrupted in state %s\n", key, r.State)
+				interrupted = append(interrupted, fileEntry{
+					Name: string(key),
+					Size: r.Size,
+					Time: time.Unix(r.ModTime, 0),
+				})
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return interrupted, s.prune()
+}
+
+// prune remove the records of tarballs deleted longer than stateRetention
+// ago, and broken records.
+func (s *fileState) prune() error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		files := tx.Bucket(filesBucket)
+		checksums := tx.Bucket(checksumsBucket)
+
+		var expired [][]byte
+		err := files.ForEach(func(key, value []byte) error {
+			var r fileRecord
+			if err := json.Unmarshal(value, &r); err != nil {
+				expired = append(expired, key)
+				return nil
+			}
+
+			if r.State == stateDeleted && time.Since(r.Updated) > stateRetention {
+				expired = append(expired, key)
+				if r.Checksum != "" && string(checksums.Get([]byte(r.Checksum))) == string(key) {
+					return checksums.Delete([]byte(r.Checksum))
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range expired {
+			if err := files.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// get return the record of a tarball, or nil if it is unknown
+func (s *fileState) get(name string) (*fileRecord, error) {
+	var r *fileRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(filesBucket).Get([]byte(name))
+		if value == nil {
+			return nil
+		}
+		r = new(fileRecord)
+		return json.Unmarshal(value, r)
+	})
+	return r, err
+}
+
+// set store the new state of a tarball
+func (s *fileState) set(v fileEntry, state, checksum string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putRecord(tx, v, state, checksum)
+	})
+}
+
+// duplicate return the name of an already processed tarball with the same
+// checksum, or "" if there is none.
+func (s *fileState) duplicate(checksum string) (string, error) {
+	var name string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		name = string(tx.Bucket(checksumsBucket).Get([]byte(checksum)))
+		return nil
+	})
+	return name, err
+}
+
+// acknowledge mark a tarball processed, and store its checksum and the
+// report time of its host in the same transaction.
+func (s *fileState) acknowledge(v fileEntry, checksum, lparname string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := putRecord(tx, v, stateAcknowledged, checksum); err != nil {
+			return err
+		}
+		if err := tx.Bucket(checksumsBucket).Put([]byte(checksum), []byte(v.Name)); err != nil {
+			return err
+		}
+
+		bkt := tx.Bucket(lastBucket)
+		last, _ := strconv.ParseInt(string(bkt.Get([]byte(lparname))), 10, 64)
+		if v.Time.Unix() <= last {
+			return nil
+		}
+		return bkt.Put([]byte(lparname), []byte(fmt.Sprintf("%d", v.Time.Unix())))
+	})
+}
+
+//
+func putRecord(tx *bolt.Tx, v fileEntry, state, checksum string) error {
+	data, err := json.Marshal(&fileRecord{
+		State:    state,
+		Size:     v.Size,
+		ModTime:  v.Time.Unix(),
+		Checksum: checksum,
+		Updated:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(filesBucket).Put([]byte(v.Name), data)
+}
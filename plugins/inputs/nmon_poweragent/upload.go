@@ -0,0 +1,114 @@
+package nmon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+)
+
+// uploadEndpoint is the path tarballs are uploaded to
+const uploadEndpoint = "/upload"
+
+// DEFAULT_MAX_UPLOAD_SIZE is the default maximum size of an uploaded tarball
+const DEFAULT_MAX_UPLOAD_SIZE = 32 * 1024 * 1024
+
+// uploadReceiver accepts nmon tarballs uploaded to its http server as a
+// multipart form file named "file", by the clients knowing the basic_username
+// and basic_password, for example with:
+//   curl -u nmon:secret \
+//     -F file=@9117-MMA*06B86A1-rc_06B86A1_VIOC3-1516690201.tar.gz \
+//     https://127.0.0.1:12345/upload
+type uploadReceiver struct {
+	*baseReceiver
+
+	maxSize int64
+}
+
+//
+func newUploadReceiver(cfg receiverConfig) (*uploadReceiver, error) {
+	if cfg.HttpListen == "" {
+		return nil, fmt.Errorf("http receiver requires http_listen")
+	}
+	if cfg.BasicUsername == "" || cfg.BasicPassword == "" {
+		return nil, fmt.Errorf("http receiver requires basic_username and basic_password")
+	}
+
+	p := &uploadReceiver{
+		baseReceiver: newBaseReceiver(cfg),
+		maxSize:      cfg.MaxUploadSize,
+	}
+	if p.maxSize == 0 {
+		p.maxSize = DEFAULT_MAX_UPLOAD_SIZE
+	}
+	p.mux.Handle(uploadEndpoint, p.authenticated(p))
+	return p, nil
+}
+
+// the uploads are not kept, an upload interrupted before the start was not
+// answered and is retried by the client.
+func (p *uploadReceiver) Start() error {
+	return p.start()
+}
+
+// stop uploadReceiver
+func (p *uploadReceiver) Stop() error {
+	return p.stop()
+}
+
+//
+func (p *uploadReceiver) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req.Body = http.MaxBytesReader(rw, req.Body, p.maxSize)
+	file, header, err := req.FormFile("file")
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("read upload failed. error: %s", err), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	name := filepath.Base(header.Filename)
+	t, err := reportTime(name)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("read upload failed. error: %s", err), http.StatusBadRequest)
+		return
+	}
+	v := fileEntry{Name: name, Size: int64(len(data)), Time: t}
+
+	p.Lock()
+	p.processlist = p.processlist[:0]
+	action := p.accept(v)
+	if action == actionProcess {
+		p.processlist = append(p.processlist, name)
+	}
+	p.Unlock()
+
+	switch action {
+	case actionKeep:
+		http.Error(rw, "state is not available", http.StatusServiceUnavailable)
+		return
+	case actionRemove:
+		// an older or already processed report, nothing to do
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// the upload is only answered once the metrics have been added to the
+	// accumulator, an unanswered upload has to be retried by the client.
+	if !p.handle(v, data) {
+		http.Error(rw, fmt.Sprintf("processing file %s failed", name), http.StatusServiceUnavailable)
+		return
+	}
+	p.deleted(v)
+	rw.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,333 @@
+// +build !windows
+
+package ping
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	protocolICMP     = 1
+	protocolIPv6ICMP = 58
+
+	// payloadSize is the size of the data of the echo requests, as the
+	// "ping -s 16" the plugin used to run.
+	payloadSize = 16
+
+	// maxPending is the number of echo requests a socket can wait for, as
+	// they are told apart by their 16 bits sequence number.
+	maxPending = 0xffff
+)
+
+// probers counts the probers of the process, to give them distinct echo
+// identifiers.
+var probers uint32
+
+// prober sends the echo requests of the pings of a gather over one socket per
+// address family, and dispatches the echo replies to the pings by sequence
+// number. It opens unprivileged datagram sockets where allowed, raw sockets
+// otherwise.
+type prober struct {
+	// source is the interface, or the address, to send from
+	source string
+	id     int
+
+	mu    sync.Mutex
+	conns map[bool]*probeConn // by v6
+}
+
+func newProber(source string) *prober {
+	return &prober{
+		source: source,
+		id:     (os.Getpid() + int(atomic.AddUint32(&probers, 1))) & 0xffff,
+		conns:  make(map[bool]*probeConn),
+	}
+}
+
+// ping is the HostPinger of the prober.
+func (p *prober) ping(
+	addr *net.IPAddr,
+	count int,
+	interval time.Duration,
+	timeout time.Duration,
+) ([]Reply, error) {
+	c, err := p.conn(addr.IP.To4() == nil)
+	if err != nil {
+		return nil, err
+	}
+
+	replies := make(chan Reply, count)
+	seqs := make([]uint16, 0, count)
+	defer func() {
+		c.forget(seqs...)
+	}()
+
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+		seq, err := c.send(addr, i, replies)
+		if err != nil {
+			return nil, err
+		}
+		seqs = append(seqs, seq)
+	}
+
+	// the replies of the earlier requests had more time to come back, they
+	// are lost if they took longer than the timeout
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var received []Reply
+	for got := 0; got < count; got++ {
+		select {
+		case r := <-replies:
+			if r.RTT <= timeout {
+				received = append(received, r)
+			}
+		case <-timer.C:
+			return received, nil
+		}
+	}
+	return received, nil
+}
+
+// close closes the sockets of the prober.
+func (p *prober) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, c := range p.conns {
+		c.conn.Close()
+	}
+}
+
+// conn returns the socket of the address family, opening it on first use.
+func (p *prober) conn(v6 bool) (*probeConn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if c, ok := p.conns[v6]; ok {
+		return c, nil
+	}
+	c, err := p.listen(v6)
+	if err != nil {
+		return nil, err
+	}
+	p.conns[v6] = c
+	go c.read()
+	return c, nil
+}
+
+func (p *prober) listen(v6 bool) (*probeConn, error) {
+	networks := []string{"udp4", "ip4:icmp"}
+	address := "0.0.0.0"
+	if v6 {
+		networks = []string{"udp6", "ip6:ipv6-icmp"}
+		address = "::"
+	}
+	if p.source != "" {
+		var err error
+		address, err = sourceAddress(p.source, v6)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	for i, network := range networks {
+		var conn *icmp.PacketConn
+		conn, err = icmp.ListenPacket(network, address)
+		if err != nil {
+			continue
+		}
+		c := &probeConn{
+			conn:     conn,
+			v6:       v6,
+			datagram: i == 0,
+			id:       p.id,
+			pending:  make(map[uint16]*echoRequest),
+		}
+		// the TTL is not available on every platform, the replies are
+		// still received without it
+		if v6 {
+			conn.IPv6PacketConn().SetControlMessage(ipv6.FlagHopLimit, true)
+		} else {
+			conn.IPv4PacketConn().SetControlMessage(ipv4.FlagTTL, true)
+		}
+		return c, nil
+	}
+	return nil, fmt.Errorf("cannot open an ICMP socket, allow unprivileged "+
+		"ICMP sockets with the net.ipv4.ping_group_range sysctl or grant "+
+		"the CAP_NET_RAW capability: %s", err)
+}
+
+// probeConn is a socket of a prober, with the echo requests waiting for their
+// reply by sequence number.
+type probeConn struct {
+	conn *icmp.PacketConn
+	v6   bool
+	// datagram is true for the unprivileged sockets, for which the kernel
+	// sets the identifier of the requests and only passes on their replies
+	datagram bool
+	id       int
+
+	mu      sync.Mutex
+	seq     uint16
+	pending map[uint16]*echoRequest
+}
+
+type echoRequest struct {
+	addr    net.IP
+	index   int
+	sent    time.Time
+	replies chan<- Reply
+}
+
+// send sends the echo request of the given index to an address, its reply
+// will be sent on the replies channel.
+func (c *probeConn) send(addr *net.IPAddr, index int, replies chan<- Reply) (uint16, error) {
+	c.mu.Lock()
+	if len(c.pending) >= maxPending {
+		c.mu.Unlock()
+		return 0, errors.New("too many echo requests waiting for a reply")
+	}
+	// skip the sequence numbers still waiting for a reply
+	for {
+		c.seq++
+		if _, ok := c.pending[c.seq]; !ok {
+			break
+		}
+	}
+	seq := c.seq
+	c.pending[seq] = &echoRequest{
+		addr:    addr.IP,
+		index:   index,
+		sent:    time.Now(),
+		replies: replies,
+	}
+	c.mu.Unlock()
+
+	var typ icmp.Type = ipv4.ICMPTypeEcho
+	if c.v6 {
+		typ = ipv6.ICMPTypeEchoRequest
+	}
+	msg := icmp.Message{
+		Type: typ,
+		Body: &icmp.Echo{
+			ID:   c.id,
+			Seq:  int(seq),
+			Data: make([]byte, payloadSize),
+		},
+	}
+	// the checksum of ICMPv6 messages is set by the kernel
+	b, err := msg.Marshal(nil)
+	if err == nil {
+		var dst net.Addr = addr
+		if c.datagram {
+			dst = &net.UDPAddr{IP: addr.IP, Zone: addr.Zone}
+		}
+		_, err = c.conn.WriteTo(b, dst)
+	}
+	if err != nil {
+		c.forget(seq)
+		return 0, err
+	}
+	return seq, nil
+}
+
+// forget stops waiting for the replies of the given sequence numbers.
+func (c *probeConn) forget(seqs ...uint16) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, seq := range seqs {
+		delete(c.pending, seq)
+	}
+}
+
+// read dispatches the echo replies until the socket is closed.
+func (c *probeConn) read() {
+	proto := protocolICMP
+	if c.v6 {
+		proto = protocolIPv6ICMP
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, ttl, src, err := c.readFrom(buf)
+		received := time.Now()
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				continue
+			}
+			return
+		}
+
+		msg, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil {
+			continue
+		}
+		if msg.Type != ipv4.ICMPTypeEchoReply && msg.Type != ipv6.ICMPTypeEchoReply {
+			continue
+		}
+		echo, ok := msg.Body.(*icmp.Echo)
+		// the raw sockets receive the replies of every process
+		if !ok || (!c.datagram && echo.ID != c.id) {
+			continue
+		}
+		c.reply(uint16(echo.Seq), src, ttl, received)
+	}
+}
+
+func (c *probeConn) readFrom(b []byte) (int, int, net.Addr, error) {
+	if c.v6 {
+		n, cm, src, err := c.conn.IPv6PacketConn().ReadFrom(b)
+		if cm != nil {
+			return n, cm.HopLimit, src, err
+		}
+		return n, 0, src, err
+	}
+	n, cm, src, err := c.conn.IPv4PacketConn().ReadFrom(b)
+	if cm != nil {
+		return n, cm.TTL, src, err
+	}
+	return n, 0, src, err
+}
+
+// reply passes on the reply to the echo request of a sequence number, if it
+// comes from the address the request was sent to.
+func (c *probeConn) reply(seq uint16, src net.Addr, ttl int, received time.Time) {
+	var ip net.IP
+	switch src := src.(type) {
+	case *net.UDPAddr:
+		ip = src.IP
+	case *net.IPAddr:
+		ip = src.IP
+	}
+
+	c.mu.Lock()
+	req, ok := c.pending[seq]
+	if ok && req.addr.Equal(ip) {
+		delete(c.pending, seq)
+	} else {
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if ok {
+		req.replies <- Reply{
+			Seq: req.index,
+			RTT: received.Sub(req.sent),
+			TTL: ttl,
+		}
+	}
+}
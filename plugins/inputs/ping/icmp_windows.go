@@ -0,0 +1,298 @@
+// +build windows
+
+package ping
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	// payloadSize is the size of the data of the echo requests, as on the
+	// other platforms.
+	payloadSize = 16
+
+	// replySize is the size of the buffer of the replies, large enough for
+	// the ICMP error messages quoting the request.
+	replySize = 1500
+
+	// ipReqTimedOut is the IP_REQ_TIMED_OUT status of ipexport.h
+	ipReqTimedOut = 11010
+)
+
+var (
+	iphlpapi = syscall.NewLazyDLL("iphlpapi.dll")
+
+	procIcmpCreateFile  = iphlpapi.NewProc("IcmpCreateFile")
+	procIcmp6CreateFile = iphlpapi.NewProc("Icmp6CreateFile")
+	procIcmpCloseHandle = iphlpapi.NewProc("IcmpCloseHandle")
+	procIcmpSendEcho2Ex = iphlpapi.NewProc("IcmpSendEcho2Ex")
+	procIcmp6SendEcho2  = iphlpapi.NewProc("Icmp6SendEcho2")
+)
+
+// icmpEchoReply is the ICMP_ECHO_REPLY of ipexport.h
+type icmpEchoReply struct {
+	Address       uint32
+	Status        uint32
+	RoundTripTime uint32
+	DataSize      uint16
+	Reserved      uint16
+	Data          uintptr
+	Options       ipOptionInformation
+}
+
+// ipOptionInformation is the IP_OPTION_INFORMATION of ipexport.h
+type ipOptionInformation struct {
+	TTL         uint8
+	Tos         uint8
+	Flags       uint8
+	OptionsSize uint8
+	OptionsData uintptr
+}
+
+// icmpv6EchoReply is the ICMPV6_ECHO_REPLY of ipexport.h, its address is a
+// packed IPV6_ADDRESS_EX.
+type icmpv6EchoReply struct {
+	Address       [26]byte
+	Status        uint32
+	RoundTripTime uint32
+}
+
+// sockaddrInet6 is the SOCKADDR_IN6 of ws2ipdef.h
+type sockaddrInet6 struct {
+	Family   uint16
+	Port     uint16
+	Flowinfo uint32
+	Addr     [16]byte
+	ScopeID  uint32
+}
+
+// prober sends the echo requests of the pings of a gather with the ICMP API
+// of Windows, IcmpSendEcho2Ex and Icmp6SendEcho2, which unlike the ICMP
+// sockets needs no privileges. It opens one ICMP handle per address family.
+type prober struct {
+	// source is the interface, or the address, to send from
+	source string
+
+	mu      sync.Mutex
+	handles map[bool]*echoHandle // by v6
+}
+
+func newProber(source string) *prober {
+	return &prober{
+		source:  source,
+		handles: make(map[bool]*echoHandle),
+	}
+}
+
+// ping is the HostPinger of the prober. The requests are sent every interval,
+// each waiting for its reply within the timeout.
+func (p *prober) ping(
+	addr *net.IPAddr,
+	count int,
+	interval time.Duration,
+	timeout time.Duration,
+) ([]Reply, error) {
+	h, err := p.handle(addr.IP.To4() == nil)
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		reply *Reply
+		err   error
+	}
+	results := make(chan result, count)
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+		go func(index int) {
+			r, err := h.send(addr, index, timeout)
+			results <- result{r, err}
+		}(i)
+	}
+
+	var replies []Reply
+	var firstErr error
+	for i := 0; i < count; i++ {
+		res := <-results
+		switch {
+		case res.err != nil && firstErr == nil:
+			firstErr = res.err
+		case res.reply != nil:
+			replies = append(replies, *res.reply)
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return replies, nil
+}
+
+// close closes the ICMP handles of the prober.
+func (p *prober) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, h := range p.handles {
+		procIcmpCloseHandle.Call(uintptr(h.handle))
+	}
+}
+
+// handle returns the ICMP handle of the address family, opening it on first
+// use.
+func (p *prober) handle(v6 bool) (*echoHandle, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if h, ok := p.handles[v6]; ok {
+		return h, nil
+	}
+
+	h := &echoHandle{v6: v6}
+	if p.source != "" {
+		address, err := sourceAddress(p.source, v6)
+		if err != nil {
+			return nil, err
+		}
+		network := "ip4"
+		if v6 {
+			network = "ip6"
+		}
+		if h.source, err = net.ResolveIPAddr(network, address); err != nil {
+			return nil, err
+		}
+	}
+
+	create := procIcmpCreateFile
+	if v6 {
+		create = procIcmp6CreateFile
+	}
+	if err := create.Find(); err != nil {
+		return nil, err
+	}
+	r, _, err := create.Call()
+	if syscall.Handle(r) == syscall.InvalidHandle {
+		return nil, fmt.Errorf("cannot open an ICMP handle: %s", err)
+	}
+	h.handle = syscall.Handle(r)
+	p.handles[v6] = h
+	return h, nil
+}
+
+// echoHandle is an ICMP handle of a prober, with the address to send from.
+type echoHandle struct {
+	handle syscall.Handle
+	v6     bool
+	source *net.IPAddr
+}
+
+// send sends the echo request of the given index to an address and waits for
+// its reply. It returns a nil reply when none was received within the
+// timeout.
+func (h *echoHandle) send(addr *net.IPAddr, index int, timeout time.Duration) (*Reply, error) {
+	data := make([]byte, payloadSize)
+	buf := make([]byte, replySize)
+	ms := uintptr(timeout / time.Millisecond)
+
+	var status uint32
+	var ttl int
+	sent := time.Now()
+	if h.v6 {
+		src := sockaddr6(h.source)
+		dst := sockaddr6(addr)
+		r, _, err := procIcmp6SendEcho2.Call(
+			uintptr(h.handle), 0, 0, 0,
+			uintptr(unsafe.Pointer(&src)),
+			uintptr(unsafe.Pointer(&dst)),
+			uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)),
+			0,
+			uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)),
+			ms)
+		if r == 0 {
+			return failedReply(err, index, time.Since(sent))
+		}
+		status = (*icmpv6EchoReply)(unsafe.Pointer(&buf[0])).Status
+	} else {
+		r, _, err := procIcmpSendEcho2Ex.Call(
+			uintptr(h.handle), 0, 0, 0,
+			uintptr(ipAddr(h.source)),
+			uintptr(ipAddr(addr)),
+			uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)),
+			0,
+			uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)),
+			ms)
+		if r == 0 {
+			return failedReply(err, index, time.Since(sent))
+		}
+		reply := (*icmpEchoReply)(unsafe.Pointer(&buf[0]))
+		status = reply.Status
+		ttl = int(reply.Options.TTL)
+	}
+	// the RoundTripTime of the replies only has a resolution of a millisecond
+	rtt := time.Since(sent)
+
+	switch {
+	case status == 0:
+		return &Reply{Seq: index, RTT: rtt, TTL: ttl}, nil
+	case status == ipReqTimedOut || rtt > timeout:
+		return nil, nil
+	}
+	return &Reply{Seq: index, RTT: rtt, Failed: true}, nil
+}
+
+// failedReply returns the reply of an echo request the ICMP API failed with
+// the given error: none when it timed out, a failed reply on an IP status,
+// ie a destination unreachable, or the error otherwise.
+func failedReply(err error, index int, rtt time.Duration) (*Reply, error) {
+	errno, ok := err.(syscall.Errno)
+	switch {
+	case ok && errno == ipReqTimedOut:
+		return nil, nil
+	case ok && ipStatus(uint32(errno)):
+		return &Reply{Seq: index, RTT: rtt, Failed: true}, nil
+	}
+	return nil, err
+}
+
+// ipStatus tells whether a code is an IP status of ipexport.h, between
+// IP_STATUS_BASE and IP_GENERAL_FAILURE.
+func ipStatus(code uint32) bool {
+	return code > 11000 && code <= 11050
+}
+
+// ipAddr returns the IPv4 address as the IPAddr of inaddr.h, or 0 for any.
+func ipAddr(addr *net.IPAddr) uint32 {
+	if addr == nil {
+		return 0
+	}
+	ip := addr.IP.To4()
+	if ip == nil {
+		return 0
+	}
+	// the IPAddr is in network order in memory
+	return uint32(ip[0]) | uint32(ip[1])<<8 | uint32(ip[2])<<16 | uint32(ip[3])<<24
+}
+
+// sockaddr6 returns the IPv6 address as a SOCKADDR_IN6, the unspecified
+// address when it is nil.
+func sockaddr6(addr *net.IPAddr) sockaddrInet6 {
+	sa := sockaddrInet6{Family: syscall.AF_INET6}
+	if addr == nil {
+		return sa
+	}
+	copy(sa.Addr[:], addr.IP.To16())
+	if addr.Zone != "" {
+		if iface, err := net.InterfaceByName(addr.Zone); err == nil {
+			sa.ScopeID = uint32(iface.Index)
+		} else if id, err := strconv.ParseUint(addr.Zone, 10, 32); err == nil {
+			sa.ScopeID = uint32(id)
+		}
+	}
+	return sa
+}
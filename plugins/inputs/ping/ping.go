@@ -1,42 +1,53 @@
-// +build !windows
-
 package ping
 
 import (
-	"errors"
 	"fmt"
+	"math"
 	"net"
-	"os/exec"
-	"runtime"
-	"strconv"
-	"strings"
+	"sort"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/influxdata/telegraf"
-	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
-// HostPinger is a function that runs the "ping" function using a list of
-// passed arguments. This can be easily switched with a mocked ping function
-// for unit test purposes (see ping_test.go)
-type HostPinger func(timeout float64, args ...string) (string, error)
+// DEFAULT_TIMEOUT is the time to wait for a reply when the timeout is not
+// set.
+const DEFAULT_TIMEOUT = 5 * time.Second
+
+// Reply is a reply received for the echo request of index Seq.
+type Reply struct {
+	Seq int
+	RTT time.Duration
+	TTL int
+	// Failed is set for the replies other than an echo reply, ie a
+	// destination unreachable from a router, which only Windows reports
+	Failed bool
+}
+
+// HostPinger is a function that sends count echo requests to an address,
+// one every interval, and returns the replies received within timeout. This
+// can be easily switched with a mocked ping function for unit test purposes
+// (see ping_test.go)
+type HostPinger func(addr *net.IPAddr, count int, interval, timeout time.Duration) ([]Reply, error)
 
 type Ping struct {
-	// Interval at which to ping (ping -i <INTERVAL>)
+	// Interval at which to ping, in seconds
 	PingInterval float64 `toml:"ping_interval"`
 
-	// Number of pings to send (ping -c <COUNT>)
+	// Number of pings to send
 	Count int
 
-	// Ping timeout, in seconds. 0 means no timeout (ping -W <TIMEOUT>)
+	// Ping timeout, in seconds. 0 means the default timeout
 	Timeout float64
 
-	// Interface to send ping from (ping -I <INTERFACE>)
+	// Interface, or address, to send ping from
 	Interface string
 
+	// Ping the IPv6 address of the urls
+	IPv6 bool
+
 	// URLs to ping
 	Urls []string
 
@@ -49,19 +60,25 @@ func (_ *Ping) Description() string {
 }
 
 const sampleConfig = `
-  ## NOTE: this plugin forks the ping command. You may need to set capabilities
-  ## via setcap cap_net_raw+p /bin/ping
+  ## NOTE: this plugin sends ICMP echo requests itself. It uses unprivileged
+  ## ICMP sockets when allowed, on Linux by the net.ipv4.ping_group_range
+  ## sysctl, and raw sockets otherwise, which require root or the
+  ## CAP_NET_RAW capability: setcap cap_net_raw=eip /usr/bin/telegraf
+  ## On Windows, it uses the ICMP API of the system, which needs no privileges.
   #
   ## List of urls to ping
   urls = ["www.google.com"] # required
-  ## number of pings to send per collection (ping -c <COUNT>)
+  ## number of pings to send per collection
   # count = 1
-  ## interval, in s, at which to ping. 0 == default (ping -i <PING_INTERVAL>)
+  ## interval, in s, at which to ping.
   # ping_interval = 1.0
-  ## per-ping timeout, in s. 0 == no timeout (ping -W <TIMEOUT>)
+  ## per-ping timeout, in s. 0 == default of 5s
   # timeout = 1.0
-  ## interface to send ping from (ping -I <INTERFACE>)
+  ## interface, or source address, to send ping from
   # interface = ""
+  ## ping the IPv6 address of the urls, instead of the IPv4 address when
+  ## they have both
+  # ipv6 = false
 `
 
 func (_ *Ping) SampleConfig() string {
@@ -69,6 +86,24 @@ func (_ *Ping) SampleConfig() string {
 }
 
 func (p *Ping) Gather(acc telegraf.Accumulator) error {
+	pingHost := p.pingHost
+	if pingHost == nil {
+		prober := newProber(p.Interface)
+		defer prober.close()
+		pingHost = prober.ping
+	}
+
+	count := p.Count
+	if count < 1 {
+		count = 1
+	}
+	interval := seconds(p.PingInterval, time.Second)
+	timeout := seconds(p.Timeout, DEFAULT_TIMEOUT)
+
+	network := "ip"
+	if p.IPv6 {
+		network = "ip6"
+	}
 
 	var wg sync.WaitGroup
 
@@ -80,7 +115,7 @@ func (p *Ping) Gather(acc telegraf.Accumulator) error {
 			tags := map[string]string{"url": u}
 			fields := map[string]interface{}{"result_code": 0}
 
-			_, err := net.LookupHost(u)
+			addr, err := net.ResolveIPAddr(network, u)
 			if err != nil {
 				acc.AddError(err)
 				fields["result_code"] = 1
@@ -88,57 +123,22 @@ func (p *Ping) Gather(acc telegraf.Accumulator) error {
 				return
 			}
 
-			args := p.args(u)
-			totalTimeout := float64(p.Count)*p.Timeout + float64(p.Count-1)*p.PingInterval
-
-			out, err := p.pingHost(totalTimeout, args...)
-			if err != nil {
-				// Some implementations of ping return a 1 exit code on
-				// timeout, if this occurs we will not exit and try to parse
-				// the output.
-				status := -1
-				if exitError, ok := err.(*exec.ExitError); ok {
-					if ws, ok := exitError.Sys().(syscall.WaitStatus); ok {
-						status = ws.ExitStatus()
-					}
-				}
-
-				if status != 1 {
-					// Combine go err + stderr output
-					out = strings.TrimSpace(out)
-					if len(out) > 0 {
-						acc.AddError(fmt.Errorf("%s, %s", out, err))
-					} else {
-						acc.AddError(err)
-					}
-					acc.AddFields("ping", fields, tags)
-					return
-				}
-			}
-
-			trans, rec, min, avg, max, stddev, err := processPingOutput(out)
+			replies, err := pingHost(addr, count, interval, timeout)
 			if err != nil {
-				// fatal error
 				acc.AddError(fmt.Errorf("%s: %s", err, u))
+				fields["result_code"] = 2
+				for k, v := range platformFields(count, nil, err) {
+					fields[k] = v
+				}
 				acc.AddFields("ping", fields, tags)
 				return
 			}
-			// Calculate packet loss percentage
-			loss := float64(trans-rec) / float64(trans) * 100.0
-			fields["packets_transmitted"] = trans
-			fields["packets_received"] = rec
-			fields["percent_packet_loss"] = loss
-			if min > 0 {
-				fields["minimum_response_ms"] = min
-			}
-			if avg > 0 {
-				fields["average_response_ms"] = avg
-			}
-			if max > 0 {
-				fields["maximum_response_ms"] = max
+
+			for k, v := range replyStats(count, replies) {
+				fields[k] = v
 			}
-			if stddev > 0 {
-				fields["standard_deviation_ms"] = stddev
+			for k, v := range platformFields(count, replies, nil) {
+				fields[k] = v
 			}
 			acc.AddFields("ping", fields, tags)
 		}(url)
@@ -149,100 +149,108 @@ func (p *Ping) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
-func hostPinger(timeout float64, args ...string) (string, error) {
-	bin, err := exec.LookPath("ping")
-	if err != nil {
-		return "", err
+// replyStats returns the fields of the replies to count echo requests: the
+// loss, the minimum, average, maximum and standard deviation of the round
+// trip times, the TTL of the last reply, and the jitter, the average
+// difference between the round trip times of consecutive replies. The
+// failed replies only count as received packets.
+func replyStats(count int, received []Reply) map[string]interface{} {
+	fields := map[string]interface{}{
+		"packets_transmitted": count,
+		"packets_received":    len(received),
+		"percent_packet_loss": float64(count-len(received)) / float64(count) * 100.0,
 	}
-	c := exec.Command(bin, args...)
-	out, err := internal.CombinedOutputTimeout(c,
-		time.Second*time.Duration(timeout+5))
-	return string(out), err
-}
-
-// args returns the arguments for the 'ping' executable
-func (p *Ping) args(url string) []string {
-	// Build the ping command args based on toml config
-	args := []string{"-c", strconv.Itoa(p.Count), "-n", "-s", "16"}
-	if p.PingInterval > 0 {
-		args = append(args, "-i", strconv.FormatFloat(p.PingInterval, 'f', 1, 64))
+	replies := echoReplies(received)
+	if len(replies) == 0 {
+		return fields
 	}
-	if p.Timeout > 0 {
-		switch runtime.GOOS {
-		case "darwin":
-			args = append(args, "-W", strconv.FormatFloat(p.Timeout*1000, 'f', 1, 64))
-		case "linux":
-			args = append(args, "-W", strconv.FormatFloat(p.Timeout, 'f', 1, 64))
-		default:
-			// Not sure the best option here, just assume GNU ping?
-			args = append(args, "-W", strconv.FormatFloat(p.Timeout, 'f', 1, 64))
+
+	sort.Slice(replies, func(i, j int) bool {
+		return replies[i].Seq < replies[j].Seq
+	})
+
+	var min, max, sum, sumSquares, jitter float64
+	for i, r := range replies {
+		rtt := float64(r.RTT) / float64(time.Millisecond)
+		if i == 0 || rtt < min {
+			min = rtt
+		}
+		if rtt > max {
+			max = rtt
+		}
+		sum += rtt
+		sumSquares += rtt * rtt
+		if i > 0 {
+			jitter += math.Abs(rtt - float64(replies[i-1].RTT)/float64(time.Millisecond))
 		}
 	}
-	if p.Interface != "" {
-		args = append(args, "-I", p.Interface)
+	n := float64(len(replies))
+	avg := sum / n
+
+	fields["minimum_response_ms"] = min
+	fields["average_response_ms"] = avg
+	fields["maximum_response_ms"] = max
+	fields["standard_deviation_ms"] = math.Sqrt(math.Max(sumSquares/n-avg*avg, 0))
+	if len(replies) > 1 {
+		fields["jitter_ms"] = jitter / (n - 1)
+	}
+	if ttl := replies[len(replies)-1].TTL; ttl > 0 {
+		fields["ttl"] = ttl
 	}
-	args = append(args, url)
-	return args
+	return fields
 }
 
-// processPingOutput takes in a string output from the ping command, like:
-//
-//     PING www.google.com (173.194.115.84): 56 data bytes
-//     64 bytes from 173.194.115.84: icmp_seq=0 ttl=54 time=52.172 ms
-//     64 bytes from 173.194.115.84: icmp_seq=1 ttl=54 time=34.843 ms
-//
-//     --- www.google.com ping statistics ---
-//     2 packets transmitted, 2 packets received, 0.0% packet loss
-//     round-trip min/avg/max/stddev = 34.843/43.508/52.172/8.664 ms
-//
-// It returns (<transmitted packets>, <received packets>, <average response>)
-func processPingOutput(out string) (int, int, float64, float64, float64, float64, error) {
-	var trans, recv int
-	var min, avg, max, stddev float64
-	// Set this error to nil if we find a 'transmitted' line
-	err := errors.New("Fatal error processing ping output")
-	lines := strings.Split(out, "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "transmitted") &&
-			strings.Contains(line, "received") {
-			stats := strings.Split(line, ", ")
-			// Transmitted packets
-			trans, err = strconv.Atoi(strings.Split(stats[0], " ")[0])
-			if err != nil {
-				return trans, recv, min, avg, max, stddev, err
-			}
-			// Received packets
-			recv, err = strconv.Atoi(strings.Split(stats[1], " ")[0])
-			if err != nil {
-				return trans, recv, min, avg, max, stddev, err
-			}
-		} else if strings.Contains(line, "min/avg/max") {
-			stats := strings.Split(line, " ")[3]
-			min, err = strconv.ParseFloat(strings.Split(stats, "/")[0], 64)
-			if err != nil {
-				return trans, recv, min, avg, max, stddev, err
-			}
-			avg, err = strconv.ParseFloat(strings.Split(stats, "/")[1], 64)
-			if err != nil {
-				return trans, recv, min, avg, max, stddev, err
-			}
-			max, err = strconv.ParseFloat(strings.Split(stats, "/")[2], 64)
-			if err != nil {
-				return trans, recv, min, avg, max, stddev, err
-			}
-			stddev, err = strconv.ParseFloat(strings.Split(stats, "/")[3], 64)
-			if err != nil {
-				return trans, recv, min, avg, max, stddev, err
-			}
+// echoReplies returns the replies that are not failed.
+func echoReplies(replies []Reply) []Reply {
+	echoes := make([]Reply, 0, len(replies))
+	for _, r := range replies {
+		if !r.Failed {
+			echoes = append(echoes, r)
+		}
+	}
+	return echoes
+}
+
+// sourceAddress returns the address to send from of an address, or of an
+// interface name.
+func sourceAddress(source string, v6 bool) (string, error) {
+	if ip := net.ParseIP(source); ip != nil {
+		return source, nil
+	}
+
+	iface, err := net.InterfaceByName(source)
+	if err != nil {
+		return "", err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return "", err
+	}
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok || (ipnet.IP.To4() == nil) != v6 {
+			continue
+		}
+		if v6 && ipnet.IP.IsLinkLocalUnicast() {
+			return ipnet.IP.String() + "%" + iface.Name, nil
 		}
+		return ipnet.IP.String(), nil
+	}
+	return "", fmt.Errorf("interface %s has no address to ping from", source)
+}
+
+// seconds returns a duration in seconds as a time.Duration, or the default
+// when it is not positive.
+func seconds(s float64, def time.Duration) time.Duration {
+	if s <= 0 {
+		return def
 	}
-	return trans, recv, min, avg, max, stddev, err
+	return time.Duration(s * float64(time.Second))
 }
 
 func init() {
 	inputs.Add("ping", func() telegraf.Input {
 		return &Ping{
-			pingHost:     hostPinger,
 			PingInterval: 1.0,
 			Count:        1,
 			Timeout:      1.0,
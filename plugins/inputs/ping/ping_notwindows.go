@@ -0,0 +1,9 @@
+// +build !windows
+
+package ping
+
+// platformFields returns the fields only reported on some platforms, none
+// here.
+func platformFields(count int, replies []Reply, err error) map[string]interface{} {
+	return nil
+}
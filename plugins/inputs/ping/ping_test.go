@@ -4,230 +4,133 @@ package ping
 
 import (
 	"errors"
-	"reflect"
-	"runtime"
-	"sort"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-// BSD/Darwin ping output
-var bsdPingOutput = `
-PING www.google.com (216.58.217.36): 56 data bytes
-64 bytes from 216.58.217.36: icmp_seq=0 ttl=55 time=15.087 ms
-64 bytes from 216.58.217.36: icmp_seq=1 ttl=55 time=21.564 ms
-64 bytes from 216.58.217.36: icmp_seq=2 ttl=55 time=27.263 ms
-64 bytes from 216.58.217.36: icmp_seq=3 ttl=55 time=18.828 ms
-64 bytes from 216.58.217.36: icmp_seq=4 ttl=55 time=18.378 ms
-
---- www.google.com ping statistics ---
-5 packets transmitted, 5 packets received, 0.0% packet loss
-round-trip min/avg/max/stddev = 15.087/20.224/27.263/4.076 ms
-`
-
-// Linux ping output
-var linuxPingOutput = `
-PING www.google.com (216.58.218.164) 56(84) bytes of data.
-64 bytes from host.net (216.58.218.164): icmp_seq=1 ttl=63 time=35.2 ms
-64 bytes from host.net (216.58.218.164): icmp_seq=2 ttl=63 time=42.3 ms
-64 bytes from host.net (216.58.218.164): icmp_seq=3 ttl=63 time=45.1 ms
-64 bytes from host.net (216.58.218.164): icmp_seq=4 ttl=63 time=43.5 ms
-64 bytes from host.net (216.58.218.164): icmp_seq=5 ttl=63 time=51.8 ms
-
---- www.google.com ping statistics ---
-5 packets transmitted, 5 received, 0% packet loss, time 4010ms
-rtt min/avg/max/mdev = 35.225/43.628/51.806/5.325 ms
-`
-
-// Fatal ping output (invalid argument)
-var fatalPingOutput = `
-ping: -i interval too short: Operation not permitted
-`
-
-// Test that ping command output is processed properly
-func TestProcessPingOutput(t *testing.T) {
-	trans, rec, min, avg, max, stddev, err := processPingOutput(bsdPingOutput)
-	assert.NoError(t, err)
-	assert.Equal(t, 5, trans, "5 packets were transmitted")
-	assert.Equal(t, 5, rec, "5 packets were transmitted")
-	assert.InDelta(t, 15.087, min, 0.001)
-	assert.InDelta(t, 20.224, avg, 0.001)
-	assert.InDelta(t, 27.263, max, 0.001)
-	assert.InDelta(t, 4.076, stddev, 0.001)
-
-	trans, rec, min, avg, max, stddev, err = processPingOutput(linuxPingOutput)
-	assert.NoError(t, err)
-	assert.Equal(t, 5, trans, "5 packets were transmitted")
-	assert.Equal(t, 5, rec, "5 packets were transmitted")
-	assert.InDelta(t, 35.225, min, 0.001)
-	assert.InDelta(t, 43.628, avg, 0.001)
-	assert.InDelta(t, 51.806, max, 0.001)
-	assert.InDelta(t, 5.325, stddev, 0.001)
-}
-
-// Test that processPingOutput returns an error when 'ping' fails to run, such
-// as when an invalid argument is provided
-func TestErrorProcessPingOutput(t *testing.T) {
-	_, _, _, _, _, _, err := processPingOutput(fatalPingOutput)
-	assert.Error(t, err, "Error was expected from processPingOutput")
-}
-
-// Test that arg lists and created correctly
-func TestArgs(t *testing.T) {
-	p := Ping{
-		Count: 2,
-	}
-
-	// Actual and Expected arg lists must be sorted for reflect.DeepEqual
-
-	actual := p.args("www.google.com")
-	expected := []string{"-c", "2", "-n", "-s", "16", "www.google.com"}
-	sort.Strings(actual)
-	sort.Strings(expected)
-	assert.True(t, reflect.DeepEqual(expected, actual),
-		"Expected: %s Actual: %s", expected, actual)
-
-	p.Interface = "eth0"
-	actual = p.args("www.google.com")
-	expected = []string{"-c", "2", "-n", "-s", "16", "-I", "eth0",
-		"www.google.com"}
-	sort.Strings(actual)
-	sort.Strings(expected)
-	assert.True(t, reflect.DeepEqual(expected, actual),
-		"Expected: %s Actual: %s", expected, actual)
-
-	p.Timeout = 12.0
-	actual = p.args("www.google.com")
-	switch runtime.GOOS {
-	case "darwin":
-		expected = []string{"-c", "2", "-n", "-s", "16", "-I", "eth0", "-W",
-			"12000.0", "www.google.com"}
-	case "freebsd":
-		expected = []string{"-c", "2", "-n", "-s", "16", "-I", "eth0", "-t",
-			"12.0", "www.google.com"}
-	default:
-		expected = []string{"-c", "2", "-n", "-s", "16", "-I", "eth0", "-W",
-			"12.0", "www.google.com"}
-	}
-
-	sort.Strings(actual)
-	sort.Strings(expected)
-	assert.True(t, reflect.DeepEqual(expected, actual),
-		"Expected: %s Actual: %s", expected, actual)
-
-	p.PingInterval = 1.2
-	actual = p.args("www.google.com")
-	switch runtime.GOOS {
-	case "darwin":
-		expected = []string{"-c", "2", "-n", "-s", "16", "-I", "eth0", "-W",
-			"12000.0", "-i", "1.2", "www.google.com"}
-	case "freebsd":
-		expected = []string{"-c", "2", "-n", "-s", "16", "-I", "eth0", "-t",
-			"12.0", "-i", "1.2", "www.google.com"}
-	default:
-		expected = []string{"-c", "2", "-n", "-s", "16", "-I", "eth0", "-W",
-			"12.0", "-i", "1.2", "www.google.com"}
-	}
-	sort.Strings(actual)
-	sort.Strings(expected)
-	assert.True(t, reflect.DeepEqual(expected, actual),
-		"Expected: %s Actual: %s", expected, actual)
+func ms(f float64) time.Duration {
+	return time.Duration(f * float64(time.Millisecond))
 }
 
-func mockHostPinger(timeout float64, args ...string) (string, error) {
-	return linuxPingOutput, nil
+func mockHostPinger(addr *net.IPAddr, count int, interval, timeout time.Duration) ([]Reply, error) {
+	return []Reply{
+		{Seq: 0, RTT: ms(35.2), TTL: 63},
+		{Seq: 1, RTT: ms(42.3), TTL: 63},
+		{Seq: 2, RTT: ms(45.1), TTL: 63},
+		{Seq: 3, RTT: ms(43.5), TTL: 63},
+		{Seq: 4, RTT: ms(51.8), TTL: 63},
+	}, nil
 }
 
 // Test that Gather function works on a normal ping
 func TestPingGather(t *testing.T) {
 	var acc testutil.Accumulator
 	p := Ping{
-		Urls:     []string{"www.google.com", "www.reddit.com"},
+		Urls:     []string{"127.0.0.1", "::1"},
+		Count:    5,
 		pingHost: mockHostPinger,
 	}
 
-	acc.GatherError(p.Gather)
-	tags := map[string]string{"url": "www.google.com"}
+	require.NoError(t, acc.GatherError(p.Gather))
+	tags := map[string]string{"url": "127.0.0.1"}
 	fields := map[string]interface{}{
 		"packets_transmitted":   5,
 		"packets_received":      5,
 		"percent_packet_loss":   0.0,
-		"minimum_response_ms":   35.225,
-		"average_response_ms":   43.628,
-		"maximum_response_ms":   51.806,
-		"standard_deviation_ms": 5.325,
+		"minimum_response_ms":   35.2,
+		"average_response_ms":   43.58,
+		"maximum_response_ms":   51.8,
+		"standard_deviation_ms": 5.324,
+		"jitter_ms":             4.95,
+		"ttl":                   63,
 		"result_code":           0,
 	}
-	acc.AssertContainsTaggedFields(t, "ping", fields, tags)
+	assertFields(t, &acc, fields, tags)
 
-	tags = map[string]string{"url": "www.reddit.com"}
-	acc.AssertContainsTaggedFields(t, "ping", fields, tags)
+	tags = map[string]string{"url": "::1"}
+	assertFields(t, &acc, fields, tags)
 }
 
-var lossyPingOutput = `
-PING www.google.com (216.58.218.164) 56(84) bytes of data.
-64 bytes from host.net (216.58.218.164): icmp_seq=1 ttl=63 time=35.2 ms
-64 bytes from host.net (216.58.218.164): icmp_seq=3 ttl=63 time=45.1 ms
-64 bytes from host.net (216.58.218.164): icmp_seq=5 ttl=63 time=51.8 ms
-
---- www.google.com ping statistics ---
-5 packets transmitted, 3 received, 40% packet loss, time 4010ms
-rtt min/avg/max/mdev = 35.225/44.033/51.806/5.325 ms
-`
+// assertFields checks the fields of the ping of a url, with a delta for the
+// float fields.
+func assertFields(
+	t *testing.T,
+	acc *testutil.Accumulator,
+	expected map[string]interface{},
+	tags map[string]string,
+) {
+	for _, m := range acc.Metrics {
+		if m.Tags["url"] != tags["url"] {
+			continue
+		}
+		assert.Len(t, m.Fields, len(expected))
+		for k, v := range expected {
+			if f, ok := v.(float64); ok {
+				assert.InDelta(t, f, m.Fields[k], 0.001, k)
+			} else {
+				assert.EqualValues(t, v, m.Fields[k], k)
+			}
+		}
+		return
+	}
+	t.Errorf("no ping of %s", tags["url"])
+}
 
-func mockLossyHostPinger(timeout float64, args ...string) (string, error) {
-	return lossyPingOutput, nil
+func mockLossyHostPinger(addr *net.IPAddr, count int, interval, timeout time.Duration) ([]Reply, error) {
+	// the replies are not necessarily in order
+	return []Reply{
+		{Seq: 4, RTT: ms(51.8), TTL: 62},
+		{Seq: 0, RTT: ms(35.2), TTL: 63},
+		{Seq: 2, RTT: ms(45.1), TTL: 63},
+	}, nil
 }
 
 // Test that Gather works on a ping with lossy packets
 func TestLossyPingGather(t *testing.T) {
 	var acc testutil.Accumulator
 	p := Ping{
-		Urls:     []string{"www.google.com"},
+		Urls:     []string{"127.0.0.1"},
+		Count:    5,
 		pingHost: mockLossyHostPinger,
 	}
 
-	acc.GatherError(p.Gather)
-	tags := map[string]string{"url": "www.google.com"}
+	require.NoError(t, acc.GatherError(p.Gather))
+	tags := map[string]string{"url": "127.0.0.1"}
 	fields := map[string]interface{}{
 		"packets_transmitted":   5,
 		"packets_received":      3,
 		"percent_packet_loss":   40.0,
-		"minimum_response_ms":   35.225,
+		"minimum_response_ms":   35.2,
 		"average_response_ms":   44.033,
-		"maximum_response_ms":   51.806,
-		"standard_deviation_ms": 5.325,
+		"maximum_response_ms":   51.8,
+		"standard_deviation_ms": 6.819,
+		"jitter_ms":             8.3,
+		"ttl":                   62,
 		"result_code":           0,
 	}
-	acc.AssertContainsTaggedFields(t, "ping", fields, tags)
+	assertFields(t, &acc, fields, tags)
 }
 
-var errorPingOutput = `
-PING www.amazon.com (176.32.98.166): 56 data bytes
-Request timeout for icmp_seq 0
-
---- www.amazon.com ping statistics ---
-2 packets transmitted, 0 packets received, 100.0% packet loss
-`
-
-func mockErrorHostPinger(timeout float64, args ...string) (string, error) {
-	// This error will not trigger correct error paths
-	return errorPingOutput, nil
+func mockErrorHostPinger(addr *net.IPAddr, count int, interval, timeout time.Duration) ([]Reply, error) {
+	return nil, nil
 }
 
-// Test that Gather works on a ping with no transmitted packets, even though the
-// command returns an error
+// Test that Gather works on a ping without any reply
 func TestBadPingGather(t *testing.T) {
 	var acc testutil.Accumulator
 	p := Ping{
-		Urls:     []string{"www.amazon.com"},
+		Urls:     []string{"127.0.0.1"},
+		Count:    2,
 		pingHost: mockErrorHostPinger,
 	}
 
-	acc.GatherError(p.Gather)
-	tags := map[string]string{"url": "www.amazon.com"}
+	require.NoError(t, acc.GatherError(p.Gather))
+	tags := map[string]string{"url": "127.0.0.1"}
 	fields := map[string]interface{}{
 		"packets_transmitted": 2,
 		"packets_received":    0,
@@ -237,29 +140,69 @@ func TestBadPingGather(t *testing.T) {
 	acc.AssertContainsTaggedFields(t, "ping", fields, tags)
 }
 
-func mockFatalHostPinger(timeout float64, args ...string) (string, error) {
-	return fatalPingOutput, errors.New("So very bad")
+func mockFatalHostPinger(addr *net.IPAddr, count int, interval, timeout time.Duration) ([]Reply, error) {
+	return nil, errors.New("So very bad")
 }
 
-// Test that a fatal ping command does not gather any statistics.
+// Test that a fatal ping does not gather any statistics.
 func TestFatalPingGather(t *testing.T) {
 	var acc testutil.Accumulator
 	p := Ping{
-		Urls:     []string{"www.amazon.com"},
+		Urls:     []string{"127.0.0.1"},
 		pingHost: mockFatalHostPinger,
 	}
 
-	acc.GatherError(p.Gather)
-	assert.False(t, acc.HasMeasurement("packets_transmitted"),
-		"Fatal ping should not have packet measurements")
-	assert.False(t, acc.HasMeasurement("packets_received"),
-		"Fatal ping should not have packet measurements")
-	assert.False(t, acc.HasMeasurement("percent_packet_loss"),
-		"Fatal ping should not have packet measurements")
-	assert.False(t, acc.HasMeasurement("minimum_response_ms"),
-		"Fatal ping should not have packet measurements")
-	assert.False(t, acc.HasMeasurement("average_response_ms"),
-		"Fatal ping should not have packet measurements")
-	assert.False(t, acc.HasMeasurement("maximum_response_ms"),
-		"Fatal ping should not have packet measurements")
+	assert.Error(t, acc.GatherError(p.Gather))
+	tags := map[string]string{"url": "127.0.0.1"}
+	fields := map[string]interface{}{
+		"result_code": 2,
+	}
+	acc.AssertContainsTaggedFields(t, "ping", fields, tags)
+}
+
+// Test that the hosts are resolved to the address of the requested family.
+func TestPingIPv6(t *testing.T) {
+	var addrs []*net.IPAddr
+	var acc testutil.Accumulator
+	p := Ping{
+		Urls: []string{"::1"},
+		IPv6: true,
+		pingHost: func(addr *net.IPAddr, count int, interval, timeout time.Duration) ([]Reply, error) {
+			addrs = append(addrs, addr)
+			return nil, nil
+		},
+	}
+
+	require.NoError(t, acc.GatherError(p.Gather))
+	require.Len(t, addrs, 1)
+	assert.True(t, addrs[0].IP.Equal(net.IPv6loopback))
+
+	p.Urls = []string{"127.0.0.1"}
+	assert.Error(t, acc.GatherError(p.Gather))
+	acc.AssertContainsTaggedFields(t, "ping",
+		map[string]interface{}{"result_code": 1},
+		map[string]string{"url": "127.0.0.1"})
+}
+
+// Test the native pinger on the loopback interface, where the system allows
+// opening an ICMP socket.
+func TestNativePing(t *testing.T) {
+	p := newProber("")
+	defer p.close()
+
+	if _, err := p.conn(false); err != nil {
+		t.Skipf("cannot open an ICMP socket: %s", err)
+	}
+
+	addr := &net.IPAddr{IP: net.IPv4(127, 0, 0, 1)}
+	replies, err := p.ping(addr, 3, 10*time.Millisecond, time.Second)
+	require.NoError(t, err)
+	require.Len(t, replies, 3)
+	seqs := map[int]bool{}
+	for _, r := range replies {
+		seqs[r.Seq] = true
+		assert.True(t, r.RTT > 0)
+		assert.True(t, r.RTT < time.Second)
+	}
+	assert.Equal(t, map[int]bool{0: true, 1: true, 2: true}, seqs)
 }
@@ -4,119 +4,71 @@ package ping
 
 import (
 	"errors"
+	"net"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-// Windows ping format ( should support multilanguage ?)
-var winPLPingOutput = `
-Badanie 8.8.8.8 z 32 bajtami danych:
-Odpowiedz z 8.8.8.8: bajtow=32 czas=49ms TTL=43
-Odpowiedz z 8.8.8.8: bajtow=32 czas=46ms TTL=43
-Odpowiedz z 8.8.8.8: bajtow=32 czas=48ms TTL=43
-Odpowiedz z 8.8.8.8: bajtow=32 czas=57ms TTL=43
-
-Statystyka badania ping dla 8.8.8.8:
-    Pakiety: Wyslane = 4, Odebrane = 4, Utracone = 0
-             (0% straty),
-Szacunkowy czas bladzenia pakietww w millisekundach:
-    Minimum = 46 ms, Maksimum = 57 ms, Czas sredni = 50 ms
-`
-
-// Windows ping format ( should support multilanguage ?)
-var winENPingOutput = `
-Pinging 8.8.8.8 with 32 bytes of data:
-Reply from 8.8.8.8: bytes=32 time=52ms TTL=43
-Reply from 8.8.8.8: bytes=32 time=50ms TTL=43
-Reply from 8.8.8.8: bytes=32 time=50ms TTL=43
-Reply from 8.8.8.8: bytes=32 time=51ms TTL=43
-
-Ping statistics for 8.8.8.8:
-    Packets: Sent = 4, Received = 4, Lost = 0 (0% loss),
-Approximate round trip times in milli-seconds:
-    Minimum = 50ms, Maximum = 52ms, Average = 50ms
-`
-
-func TestHost(t *testing.T) {
-	trans, recReply, recPacket, avg, min, max, err := processPingOutput(winPLPingOutput)
-	assert.NoError(t, err)
-	assert.Equal(t, 4, trans, "4 packets were transmitted")
-	assert.Equal(t, 4, recReply, "4 packets were reply")
-	assert.Equal(t, 4, recPacket, "4 packets were received")
-	assert.Equal(t, 50, avg, "Average 50")
-	assert.Equal(t, 46, min, "Min 46")
-	assert.Equal(t, 57, max, "max 57")
-
-	trans, recReply, recPacket, avg, min, max, err = processPingOutput(winENPingOutput)
-	assert.NoError(t, err)
-	assert.Equal(t, 4, trans, "4 packets were transmitted")
-	assert.Equal(t, 4, recReply, "4 packets were reply")
-	assert.Equal(t, 4, recPacket, "4 packets were received")
-	assert.Equal(t, 50, avg, "Average 50")
-	assert.Equal(t, 50, min, "Min 50")
-	assert.Equal(t, 52, max, "Max 52")
-}
-
-func mockHostPinger(timeout float64, args ...string) (string, error) {
-	return winENPingOutput, nil
+func mockHostPinger(addr *net.IPAddr, count int, interval, timeout time.Duration) ([]Reply, error) {
+	return []Reply{
+		{Seq: 0, RTT: 52 * time.Millisecond, TTL: 43},
+		{Seq: 1, RTT: 50 * time.Millisecond, TTL: 43},
+		{Seq: 2, RTT: 50 * time.Millisecond, TTL: 43},
+		{Seq: 3, RTT: 51 * time.Millisecond, TTL: 43},
+	}, nil
 }
 
 // Test that Gather function works on a normal ping
 func TestPingGather(t *testing.T) {
 	var acc testutil.Accumulator
 	p := Ping{
-		Urls:     []string{"www.google.com", "www.reddit.com"},
+		Urls:     []string{"127.0.0.1", "::1"},
+		Count:    4,
 		pingHost: mockHostPinger,
 	}
 
-	acc.GatherError(p.Gather)
-	tags := map[string]string{"url": "www.google.com"}
+	require.NoError(t, acc.GatherError(p.Gather))
+	tags := map[string]string{"url": "127.0.0.1"}
 	fields := map[string]interface{}{
-		"packets_transmitted": 4,
-		"packets_received":    4,
-		"reply_received":      4,
-		"percent_packet_loss": 0.0,
-		"percent_reply_loss":  0.0,
-		"average_response_ms": 50.0,
-		"minimum_response_ms": 50.0,
-		"maximum_response_ms": 52.0,
-		"result_code":         0,
+		"packets_transmitted":   4,
+		"packets_received":      4,
+		"reply_received":        4,
+		"percent_packet_loss":   0.0,
+		"percent_reply_loss":    0.0,
+		"average_response_ms":   50.75,
+		"minimum_response_ms":   50.0,
+		"maximum_response_ms":   52.0,
+		"standard_deviation_ms": 0.82915619758885,
+		"jitter_ms":             1.0,
+		"ttl":                   43,
+		"result_code":           0,
 	}
 	acc.AssertContainsTaggedFields(t, "ping", fields, tags)
 
-	tags = map[string]string{"url": "www.reddit.com"}
+	tags = map[string]string{"url": "::1"}
 	acc.AssertContainsTaggedFields(t, "ping", fields, tags)
 }
 
-var errorPingOutput = `
-Badanie nask.pl [195.187.242.157] z 32 bajtami danych:
-Upłynął limit czasu żądania.
-Upłynął limit czasu żądania.
-Upłynął limit czasu żądania.
-Upłynął limit czasu żądania.
-
-Statystyka badania ping dla 195.187.242.157:
-    Pakiety: Wysłane = 4, Odebrane = 0, Utracone = 4
-             (100% straty),
-`
-
-func mockErrorHostPinger(timeout float64, args ...string) (string, error) {
-	return errorPingOutput, errors.New("No packets received")
+func mockErrorHostPinger(addr *net.IPAddr, count int, interval, timeout time.Duration) ([]Reply, error) {
+	return nil, nil
 }
 
-// Test that Gather works on a ping with no transmitted packets, even though the
-// command returns an error
+// Test that Gather works on a ping without any reply
 func TestBadPingGather(t *testing.T) {
 	var acc testutil.Accumulator
 	p := Ping{
-		Urls:     []string{"www.amazon.com"},
+		Urls:     []string{"127.0.0.1"},
+		Count:    4,
 		pingHost: mockErrorHostPinger,
 	}
 
-	acc.GatherError(p.Gather)
-	tags := map[string]string{"url": "www.amazon.com"}
+	require.NoError(t, acc.GatherError(p.Gather))
+	tags := map[string]string{"url": "127.0.0.1"}
 	fields := map[string]interface{}{
 		"packets_transmitted": 4,
 		"packets_received":    0,
@@ -128,145 +80,45 @@ func TestBadPingGather(t *testing.T) {
 	acc.AssertContainsTaggedFields(t, "ping", fields, tags)
 }
 
-var lossyPingOutput = `
-Badanie thecodinglove.com [66.6.44.4] z 9800 bajtami danych:
-Upłynął limit czasu żądania.
-Odpowiedź z 66.6.44.4: bajtów=9800 czas=114ms TTL=48
-Odpowiedź z 66.6.44.4: bajtów=9800 czas=114ms TTL=48
-Odpowiedź z 66.6.44.4: bajtów=9800 czas=118ms TTL=48
-Odpowiedź z 66.6.44.4: bajtów=9800 czas=114ms TTL=48
-Odpowiedź z 66.6.44.4: bajtów=9800 czas=114ms TTL=48
-Upłynął limit czasu żądania.
-Odpowiedź z 66.6.44.4: bajtów=9800 czas=119ms TTL=48
-Odpowiedź z 66.6.44.4: bajtów=9800 czas=116ms TTL=48
-
-Statystyka badania ping dla 66.6.44.4:
-    Pakiety: Wysłane = 9, Odebrane = 7, Utracone = 2
-             (22% straty),
-Szacunkowy czas błądzenia pakietów w millisekundach:
-    Minimum = 114 ms, Maksimum = 119 ms, Czas średni = 115 ms
-`
-
-func mockLossyHostPinger(timeout float64, args ...string) (string, error) {
-	return lossyPingOutput, nil
+func mockFatalHostPinger(addr *net.IPAddr, count int, interval, timeout time.Duration) ([]Reply, error) {
+	return nil, errors.New("So very bad")
 }
 
-// Test that Gather works on a ping with lossy packets
-func TestLossyPingGather(t *testing.T) {
+// Test that a fatal ping does not gather any statistics.
+func TestFatalPingGather(t *testing.T) {
 	var acc testutil.Accumulator
 	p := Ping{
-		Urls:     []string{"www.google.com"},
-		pingHost: mockLossyHostPinger,
+		Urls:     []string{"127.0.0.1"},
+		pingHost: mockFatalHostPinger,
 	}
 
-	acc.GatherError(p.Gather)
-	tags := map[string]string{"url": "www.google.com"}
+	assert.Error(t, acc.GatherError(p.Gather))
+	tags := map[string]string{"url": "127.0.0.1"}
 	fields := map[string]interface{}{
-		"packets_transmitted": 9,
-		"packets_received":    7,
-		"reply_received":      7,
-		"percent_packet_loss": 22.22222222222222,
-		"percent_reply_loss":  22.22222222222222,
-		"average_response_ms": 115.0,
-		"minimum_response_ms": 114.0,
-		"maximum_response_ms": 119.0,
-		"result_code":         0,
+		"errors":      100.0,
+		"result_code": 2,
 	}
 	acc.AssertContainsTaggedFields(t, "ping", fields, tags)
 }
 
-// Fatal ping output (invalid argument)
-var fatalPingOutput = `
-Bad option -d.
-
-
-Usage: ping [-t] [-a] [-n count] [-l size] [-f] [-i TTL] [-v TOS]
-            [-r count] [-s count] [[-j host-list] | [-k host-list]]
-            [-w timeout] [-R] [-S srcaddr] [-4] [-6] target_name
-
-Options:
-    -t             Ping the specified host until stopped.
-                   To see statistics and continue - type Control-Break;
-                   To stop - type Control-C.
-    -a             Resolve addresses to hostnames.
-    -n count       Number of echo requests to send.
-    -l size        Send buffer size.
-    -f             Set Don't Fragment flag in packet (IPv4-only).
-    -i TTL         Time To Live.
-    -v TOS         Type Of Service (IPv4-only. This setting has been deprecated
-                   and has no effect on the type of service field in the IP Header).
-    -r count       Record route for count hops (IPv4-only).
-    -s count       Timestamp for count hops (IPv4-only).
-    -j host-list   Loose source route along host-list (IPv4-only).
-    -k host-list   Strict source route along host-list (IPv4-only).
-    -w timeout     Timeout in milliseconds to wait for each reply.
-    -R             Use routing header to test reverse route also (IPv6-only).
-    -S srcaddr     Source address to use.
-    -4             Force using IPv4.
-    -6             Force using IPv6.
-
-`
-
-func mockFatalHostPinger(timeout float64, args ...string) (string, error) {
-	return fatalPingOutput, errors.New("So very bad")
-}
-
-// Test that a fatal ping command does not gather any statistics.
-func TestFatalPingGather(t *testing.T) {
-	var acc testutil.Accumulator
-	p := Ping{
-		Urls:     []string{"www.amazon.com"},
-		pingHost: mockFatalHostPinger,
-	}
-
-	acc.GatherError(p.Gather)
-	assert.True(t, acc.HasFloatField("ping", "errors"),
-		"Fatal ping should have packet measurements")
-	assert.False(t, acc.HasInt64Field("ping", "packets_transmitted"),
-		"Fatal ping should not have packet measurements")
-	assert.False(t, acc.HasInt64Field("ping", "packets_received"),
-		"Fatal ping should not have packet measurements")
-	assert.False(t, acc.HasFloatField("ping", "percent_packet_loss"),
-		"Fatal ping should not have packet measurements")
-	assert.False(t, acc.HasFloatField("ping", "percent_reply_loss"),
-		"Fatal ping should not have packet measurements")
-	assert.False(t, acc.HasInt64Field("ping", "average_response_ms"),
-		"Fatal ping should not have packet measurements")
-	assert.False(t, acc.HasInt64Field("ping", "maximum_response_ms"),
-		"Fatal ping should not have packet measurements")
-	assert.False(t, acc.HasInt64Field("ping", "minimum_response_ms"),
-		"Fatal ping should not have packet measurements")
-}
-
-var UnreachablePingOutput = `
-Pinging www.google.pl [8.8.8.8] with 32 bytes of data:
-Request timed out.
-Request timed out.
-Reply from 194.204.175.50: Destination net unreachable.
-Request timed out.
-
-Ping statistics for 8.8.8.8:
-    Packets: Sent = 4, Received = 1, Lost = 3 (75% loss),
-`
-
-func mockUnreachableHostPinger(timeout float64, args ...string) (string, error) {
-	return UnreachablePingOutput, errors.New("So very bad")
+func mockUnreachableHostPinger(addr *net.IPAddr, count int, interval, timeout time.Duration) ([]Reply, error) {
+	return []Reply{
+		{Seq: 2, RTT: 10 * time.Millisecond, Failed: true},
+	}, nil
 }
 
-//Reply from 185.28.251.217: TTL expired in transit.
-
-// in case 'Destination net unreachable' ping app return receive packet which is not what we need
-// it's not contain valid metric so treat it as lost one
+// Test that a destination unreachable, or a TTL expired in transit, counts as
+// a received packet but not as a reply, and gives no response time.
 func TestUnreachablePingGather(t *testing.T) {
 	var acc testutil.Accumulator
 	p := Ping{
-		Urls:     []string{"www.google.com"},
+		Urls:     []string{"127.0.0.1"},
+		Count:    4,
 		pingHost: mockUnreachableHostPinger,
 	}
 
-	acc.GatherError(p.Gather)
-
-	tags := map[string]string{"url": "www.google.com"}
+	require.NoError(t, acc.GatherError(p.Gather))
+	tags := map[string]string{"url": "127.0.0.1"}
 	fields := map[string]interface{}{
 		"packets_transmitted": 4,
 		"packets_received":    1,
@@ -276,60 +128,42 @@ func TestUnreachablePingGather(t *testing.T) {
 		"result_code":         0,
 	}
 	acc.AssertContainsTaggedFields(t, "ping", fields, tags)
-
-	assert.False(t, acc.HasFloatField("ping", "errors"),
-		"Fatal ping should not have packet measurements")
-	assert.False(t, acc.HasInt64Field("ping", "average_response_ms"),
-		"Fatal ping should not have packet measurements")
-	assert.False(t, acc.HasInt64Field("ping", "maximum_response_ms"),
-		"Fatal ping should not have packet measurements")
-	assert.False(t, acc.HasInt64Field("ping", "minimum_response_ms"),
-		"Fatal ping should not have packet measurements")
-}
-
-var TTLExpiredPingOutput = `
-Pinging www.google.pl [8.8.8.8] with 32 bytes of data:
-Request timed out.
-Request timed out.
-Reply from 185.28.251.217: TTL expired in transit.
-Request timed out.
-
-Ping statistics for 8.8.8.8:
-    Packets: Sent = 4, Received = 1, Lost = 3 (75% loss),
-`
-
-func mockTTLExpiredPinger(timeout float64, args ...string) (string, error) {
-	return TTLExpiredPingOutput, errors.New("So very bad")
 }
 
-// in case 'Destination net unreachable' ping app return receive packet which is not what we need
-// it's not contain valid metric so treat it as lost one
-func TestTTLExpiredPingGather(t *testing.T) {
-	var acc testutil.Accumulator
-	p := Ping{
-		Urls:     []string{"www.google.com"},
-		pingHost: mockTTLExpiredPinger,
+// Test the native pinger on the loopback interface, which needs no
+// privileges on Windows.
+func TestNativePing(t *testing.T) {
+	p := newProber("")
+	defer p.close()
+
+	addr := &net.IPAddr{IP: net.IPv4(127, 0, 0, 1)}
+	replies, err := p.ping(addr, 3, 10*time.Millisecond, time.Second)
+	require.NoError(t, err)
+	require.Len(t, replies, 3)
+	seqs := map[int]bool{}
+	for _, r := range replies {
+		seqs[r.Seq] = true
+		assert.False(t, r.Failed)
+		assert.True(t, r.RTT < time.Second)
+		assert.True(t, r.TTL > 0)
 	}
+	assert.Equal(t, map[int]bool{0: true, 1: true, 2: true}, seqs)
+}
 
-	acc.GatherError(p.Gather)
+// Test that an IP status of a failed echo request is a failed reply, and a
+// timeout no reply.
+func TestFailedReply(t *testing.T) {
+	r, err := failedReply(syscall.Errno(ipReqTimedOut), 1, time.Second)
+	assert.NoError(t, err)
+	assert.Nil(t, r)
 
-	tags := map[string]string{"url": "www.google.com"}
-	fields := map[string]interface{}{
-		"packets_transmitted": 4,
-		"packets_received":    1,
-		"reply_received":      0,
-		"percent_packet_loss": 75.0,
-		"percent_reply_loss":  100.0,
-		"result_code":         0,
-	}
-	acc.AssertContainsTaggedFields(t, "ping", fields, tags)
+	// IP_DEST_HOST_UNREACHABLE
+	r, err = failedReply(syscall.Errno(11003), 1, time.Second)
+	assert.NoError(t, err)
+	require.NotNil(t, r)
+	assert.Equal(t, Reply{Seq: 1, RTT: time.Second, Failed: true}, *r)
 
-	assert.False(t, acc.HasFloatField("ping", "errors"),
-		"Fatal ping should not have packet measurements")
-	assert.False(t, acc.HasInt64Field("ping", "average_response_ms"),
-		"Fatal ping should not have packet measurements")
-	assert.False(t, acc.HasInt64Field("ping", "maximum_response_ms"),
-		"Fatal ping should not have packet measurements")
-	assert.False(t, acc.HasInt64Field("ping", "minimum_response_ms"),
-		"Fatal ping should not have packet measurements")
+	// ERROR_INVALID_PARAMETER
+	_, err = failedReply(syscall.Errno(87), 1, time.Second)
+	assert.Error(t, err)
 }
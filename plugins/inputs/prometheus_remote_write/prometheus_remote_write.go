@@ -0,0 +1,274 @@
+package prometheus_remote_write
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/prompb"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+const (
+	// DEFAULT_MAX_BODY_SIZE is the default maximum request body size, in bytes.
+	// if the request body is over this size, we will return an HTTP 413 error.
+	// 32 MB
+	DEFAULT_MAX_BODY_SIZE = 32 * 1024 * 1024
+
+	DEFAULT_PATH = "/api/v1/write"
+)
+
+// PrometheusRemoteWrite receives the remote write requests of Prometheus
+// servers. Every sample becomes a metric named after the series, with the
+// other labels of the series as tags and a value field, like the untyped
+// samples of the prometheus input.
+type PrometheusRemoteWrite struct {
+	ServiceAddress string
+	Path           string
+	ReadTimeout    internal.Duration
+	WriteTimeout   internal.Duration
+	MaxBodySize    int64
+	Port           int
+
+	TlsAllowedCacerts []string
+	TlsCert           string
+	TlsKey            string
+
+	mu sync.Mutex
+	wg sync.WaitGroup
+
+	listener net.Listener
+	acc      telegraf.Accumulator
+
+	BytesRecv       selfstat.Stat
+	RequestsServed  selfstat.Stat
+	WritesServed    selfstat.Stat
+	SamplesRecv     selfstat.Stat
+	NotFoundsServed selfstat.Stat
+}
+
+const sampleConfig = `
+  ## Address and port to host the remote write listener on
+  service_address = ":1234"
+
+  ## Path of the remote write endpoint, the url of the remote_write
+  ## section of the Prometheus configuration is http://<service_address><path>
+  # path = "/api/v1/write"
+
+  ## maximum duration before timing out read of the request
+  # read_timeout = "10s"
+  ## maximum duration before timing out write of the response
+  # write_timeout = "10s"
+
+  ## Maximum allowed http request body size in bytes.
+  ## 0 means to use the default of 33,554,432 bytes (32 mebibytes)
+  # max_body_size = 0
+
+  ## Set one or more allowed client CA certificate file names to
+  ## enable mutually authenticated TLS connections
+  # tls_allowed_cacerts = ["/etc/telegraf/clientca.pem"]
+
+  ## Add service certificate and key
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+`
+
+func (p *PrometheusRemoteWrite) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *PrometheusRemoteWrite) Description() string {
+	return "Prometheus remote write listener"
+}
+
+func (p *PrometheusRemoteWrite) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
+// Start starts the remote write listener service.
+func (p *PrometheusRemoteWrite) Start(acc telegraf.Accumulator) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	tags := map[string]string{
+		"address": p.ServiceAddress,
+	}
+	p.BytesRecv = selfstat.Register("prometheus_remote_write", "bytes_received", tags)
+	p.RequestsServed = selfstat.Register("prometheus_remote_write", "requests_served", tags)
+	p.WritesServed = selfstat.Register("prometheus_remote_write", "writes_served", tags)
+	p.SamplesRecv = selfstat.Register("prometheus_remote_write", "samples_received", tags)
+	p.NotFoundsServed = selfstat.Register("prometheus_remote_write", "not_founds_served", tags)
+
+	if p.Path == "" {
+		p.Path = DEFAULT_PATH
+	}
+	if p.MaxBodySize == 0 {
+		p.MaxBodySize = DEFAULT_MAX_BODY_SIZE
+	}
+	if p.ReadTimeout.Duration < time.Second {
+		p.ReadTimeout.Duration = time.Second * 10
+	}
+	if p.WriteTimeout.Duration < time.Second {
+		p.WriteTimeout.Duration = time.Second * 10
+	}
+
+	p.acc = acc
+
+	tlsConf := p.getTLSConfig()
+
+	server := &http.Server{
+		Addr:         p.ServiceAddress,
+		Handler:      p,
+		ReadTimeout:  p.ReadTimeout.Duration,
+		WriteTimeout: p.WriteTimeout.Duration,
+		TLSConfig:    tlsConf,
+	}
+
+	var err error
+	var listener net.Listener
+	if tlsConf != nil {
+		listener, err = tls.Listen("tcp", p.ServiceAddress, tlsConf)
+	} else {
+		listener, err = net.Listen("tcp", p.ServiceAddress)
+	}
+	if err != nil {
+		return err
+	}
+	p.listener = listener
+	p.Port = listener.Addr().(*net.TCPAddr).Port
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		server.Serve(p.listener)
+	}()
+
+	log.Printf("I! Started Prometheus remote write listener service on %s\n", p.ServiceAddress)
+
+	return nil
+}
+
+// Stop cleans up all resources
+func (p *PrometheusRemoteWrite) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.listener.Close()
+	p.wg.Wait()
+
+	log.Println("I! Stopped Prometheus remote write listener service on ", p.ServiceAddress)
+}
+
+func (p *PrometheusRemoteWrite) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	defer p.RequestsServed.Incr(1)
+	if req.URL.Path != p.Path {
+		defer p.NotFoundsServed.Incr(1)
+		http.NotFound(res, req)
+		return
+	}
+	if req.Method != "POST" {
+		res.Header().Set("Allow", "POST")
+		http.Error(res, http.StatusText(http.StatusMethodNotAllowed),
+			http.StatusMethodNotAllowed)
+		return
+	}
+	defer p.WritesServed.Incr(1)
+	p.serveWrite(res, req)
+}
+
+func (p *PrometheusRemoteWrite) serveWrite(res http.ResponseWriter, req *http.Request) {
+	// Check that the content length is not too large for us to handle.
+	if req.ContentLength > p.MaxBodySize {
+		tooLarge(res)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(res, req.Body, p.MaxBodySize))
+	if err != nil {
+		// the only error of a request body is it being over the limit
+		tooLarge(res)
+		return
+	}
+	p.BytesRecv.Incr(int64(len(body)))
+
+	wr, err := prompb.Decode(body)
+	if err != nil {
+		log.Println("E! prometheus_remote_write: " + err.Error())
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, series := range wr.Timeseries {
+		var name string
+		tags := make(map[string]string, len(series.Labels))
+		for _, l := range series.Labels {
+			if l.Name == prompb.MetricNameLabel {
+				name = l.Value
+				continue
+			}
+			tags[l.Name] = l.Value
+		}
+		if name == "" {
+			continue
+		}
+		for _, s := range series.Samples {
+			t := time.Unix(0, s.Timestamp*int64(time.Millisecond))
+			p.acc.AddFields(name, map[string]interface{}{"value": s.Value}, tags, t)
+		}
+		p.SamplesRecv.Incr(int64(len(series.Samples)))
+	}
+	res.WriteHeader(http.StatusNoContent)
+}
+
+func tooLarge(res http.ResponseWriter) {
+	http.Error(res, "http: request body too large", http.StatusRequestEntityTooLarge)
+}
+
+func (p *PrometheusRemoteWrite) getTLSConfig() *tls.Config {
+	tlsConf := &tls.Config{
+		InsecureSkipVerify: false,
+		Renegotiation:      tls.RenegotiateNever,
+	}
+
+	if len(p.TlsCert) == 0 || len(p.TlsKey) == 0 {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(p.TlsCert, p.TlsKey)
+	if err != nil {
+		return nil
+	}
+	tlsConf.Certificates = []tls.Certificate{cert}
+
+	if p.TlsAllowedCacerts != nil {
+		tlsConf.ClientAuth = tls.RequireAndVerifyClientCert
+		clientPool := x509.NewCertPool()
+		for _, ca := range p.TlsAllowedCacerts {
+			c, err := ioutil.ReadFile(ca)
+			if err != nil {
+				continue
+			}
+			clientPool.AppendCertsFromPEM(c)
+		}
+		tlsConf.ClientCAs = clientPool
+	}
+
+	return tlsConf
+}
+
+func init() {
+	inputs.Add("prometheus_remote_write", func() telegraf.Input {
+		return &PrometheusRemoteWrite{
+			ServiceAddress: ":1234",
+			Path:           DEFAULT_PATH,
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package prometheus_remote_write
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/prompb"
+	"github.com/influxdata/telegraf/metric"
+	remotewrite "github.com/influxdata/telegraf/plugins/outputs/prometheus_remote_write"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newTestListener(t *testing.T, acc *testutil.Accumulator) *PrometheusRemoteWrite {
+	listener := &PrometheusRemoteWrite{
+		ServiceAddress: ":0",
+	}
+	require.NoError(t, listener.Start(acc))
+	return listener
+}
+
+func (p *PrometheusRemoteWrite) url(path string) string {
+	return "http://localhost:" + strconv.Itoa(p.Port) + path
+}
+
+func TestWrite(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	listener := newTestListener(t, acc)
+	defer listener.Stop()
+
+	body, err := prompb.Encode(&prompb.WriteRequest{
+		Timeseries: []*prompb.TimeSeries{{
+			Labels: []*prompb.Label{
+				{Name: "__name__", Value: "up"},
+				{Name: "job", Value: "node"},
+			},
+			Samples: []*prompb.Sample{
+				{Value: 1, Timestamp: 1500000000000},
+				{Value: 0, Timestamp: 1500000015000},
+			},
+		}},
+	})
+	require.NoError(t, err)
+
+	resp, err := http.Post(listener.url("/api/v1/write"), "application/x-protobuf", bytes.NewReader(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.EqualValues(t, http.StatusNoContent, resp.StatusCode)
+
+	acc.Wait(2)
+	acc.AssertContainsTaggedFields(t, "up",
+		map[string]interface{}{"value": float64(1)},
+		map[string]string{"job": "node"})
+	assert.True(t, acc.HasTimestamp("up", time.Unix(1500000000, 0)))
+}
+
+func TestWriteErrors(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	listener := newTestListener(t, acc)
+	defer listener.Stop()
+
+	resp, err := http.Post(listener.url("/api/v1/write"), "application/x-protobuf", bytes.NewBufferString("not snappy"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(listener.url("/api/v1/write"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.EqualValues(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp, err = http.Post(listener.url("/write"), "application/x-protobuf", bytes.NewBufferString(""))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.EqualValues(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestWriteTooLarge(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	listener := &PrometheusRemoteWrite{
+		ServiceAddress: ":0",
+		MaxBodySize:    16,
+	}
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	resp, err := http.Post(listener.url("/api/v1/write"), "application/x-protobuf",
+		bytes.NewReader(make([]byte, 64)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.EqualValues(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
+
+// the metrics written by the output are read back by the listener
+func TestOutputRoundTrip(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	listener := newTestListener(t, acc)
+	defer listener.Stop()
+
+	output := &remotewrite.PrometheusRemoteWrite{
+		URL:     listener.url("/api/v1/write"),
+		Timeout: internal.Duration{Duration: 5 * time.Second},
+	}
+	require.NoError(t, output.Connect())
+
+	ts := time.Unix(1500000000, 0)
+	m, err := metric.New("nmon_CPU_ALL",
+		map[string]string{"host": "aix01"},
+		map[string]interface{}{"user": 2.5},
+		ts, telegraf.Gauge)
+	require.NoError(t, err)
+	require.NoError(t, output.Write([]telegraf.Metric{m}))
+
+	acc.Wait(1)
+	acc.AssertContainsTaggedFields(t, "nmon_CPU_ALL_user",
+		map[string]interface{}{"value": 2.5},
+		map[string]string{"host": "aix01"})
+	assert.True(t, acc.HasTimestamp("nmon_CPU_ALL_user", ts))
+}
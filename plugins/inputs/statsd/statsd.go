@@ -3,6 +3,7 @@ package statsd
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -33,6 +34,9 @@ const (
 	defaultSeparator           = "_"
 	defaultAllowPendingMessage = 10000
 	MaxTCPConnections          = 250
+
+	// countersKey is the key of the counters in the state store.
+	countersKey = "counters"
 )
 
 var dropwarn = "E! Error: statsd message queue full. " +
@@ -117,6 +121,9 @@ type Statsd struct {
 
 	acc telegraf.Accumulator
 
+	// state keeps the counters across restarts
+	state telegraf.StateStore
+
 	MaxConnections     selfstat.Stat
 	CurrentConnections selfstat.Stat
 	TotalConnections   selfstat.Stat
@@ -160,6 +167,14 @@ type cachedcounter struct {
 	tags   map[string]string
 }
 
+// savedcounter is a cachedcounter as saved to the state store.
+type savedcounter struct {
+	Hash   string            `json:"hash"`
+	Name   string            `json:"name"`
+	Fields map[string]int64  `json:"fields"`
+	Tags   map[string]string `json:"tags"`
+}
+
 type cachedtimings struct {
 	name   string
 	fields map[string]RunningStats
@@ -268,6 +283,7 @@ func (s *Statsd) Gather(acc telegraf.Accumulator) error {
 	if s.DeleteCounters {
 		s.counters = make(map[string]cachedcounter)
 	}
+	s.saveCounters()
 
 	for _, metric := range s.sets {
 		fields := make(map[string]interface{})
@@ -292,6 +308,9 @@ func (s *Statsd) Start(_ telegraf.Accumulator) error {
 
 	s.Lock()
 	defer s.Unlock()
+	if err := s.loadCounters(); err != nil {
+		log.Printf("E! Error loading the statsd counters from the state, %s", err)
+	}
 	//
 	tags := map[string]string{
 		"address": s.ServiceAddress,
@@ -848,10 +867,75 @@ func (s *Statsd) Stop() {
 
 	s.Lock()
 	close(s.in)
+	s.saveCounters()
 	log.Println("I! Stopped Statsd listener service on ", s.ServiceAddress)
 	s.Unlock()
 }
 
+// loadCounters restores the counters saved to the state store, so they
+// carry on from their values before a restart.
+func (s *Statsd) loadCounters() error {
+	if s.state == nil {
+		return nil
+	}
+	b, ok := s.state.Get(countersKey)
+	if !ok {
+		return nil
+	}
+	var saved []savedcounter
+	if err := json.Unmarshal(b, &saved); err != nil {
+		return err
+	}
+	for _, c := range saved {
+		fields := make(map[string]interface{}, len(c.Fields))
+		for k, v := range c.Fields {
+			fields[k] = v
+		}
+		s.counters[c.Hash] = cachedcounter{
+			name:   c.Name,
+			fields: fields,
+			tags:   c.Tags,
+		}
+	}
+	return nil
+}
+
+// saveCounters saves the counters to the state store, it must be called
+// with the lock held.
+func (s *Statsd) saveCounters() {
+	if s.state == nil {
+		return
+	}
+	if len(s.counters) == 0 {
+		s.state.Delete(countersKey)
+		return
+	}
+	saved := make([]savedcounter, 0, len(s.counters))
+	for hash, c := range s.counters {
+		fields := make(map[string]int64, len(c.fields))
+		for k, v := range c.fields {
+			fields[k] = v.(int64)
+		}
+		saved = append(saved, savedcounter{
+			Hash:   hash,
+			Name:   c.name,
+			Fields: fields,
+			Tags:   c.tags,
+		})
+	}
+	b, err := json.Marshal(saved)
+	if err != nil {
+		log.Printf("E! Error saving the statsd counters to the state, %s", err)
+		return
+	}
+	s.state.Set(countersKey, b)
+}
+
+// SetStateStore sets the store the counters are kept in across restarts.
+func (s *Statsd) SetStateStore(store telegraf.StateStore) {
+	s.state = store
+}
+
 // IsUDP returns true if the protocol is UDP, false otherwise.
 func (s *Statsd) isUDP() bool {
 	return strings.HasPrefix(s.Protocol, "udp")
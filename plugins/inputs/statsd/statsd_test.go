@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf/internal/state"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -1485,6 +1486,44 @@ func TestParse_Counters_Delete(t *testing.T) {
 	}
 }
 
+// Test that the counters carry on from their values before a restart
+func TestCountersRestart(t *testing.T) {
+	st, err := state.Open("")
+	require.NoError(t, err)
+
+	newStatsd := func() *Statsd {
+		s := &Statsd{
+			Protocol:               "udp",
+			ServiceAddress:         "localhost:0",
+			AllowedPendingMessages: 10000,
+			MaxTCPConnections:      250,
+		}
+		s.SetStateStore(st.Namespace("inputs.statsd"))
+		return s
+	}
+
+	s := newStatsd()
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	time.Sleep(time.Millisecond * 25)
+	require.NoError(t, s.parseStatsdLine("requests:3|c"))
+	require.NoError(t, s.Gather(acc))
+	acc.AssertContainsFields(t, "requests",
+		map[string]interface{}{"value": int64(3)})
+	require.NoError(t, s.parseStatsdLine("requests:1|c"))
+	s.Stop()
+
+	s = newStatsd()
+	acc = &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	time.Sleep(time.Millisecond * 25)
+	defer s.Stop()
+	require.NoError(t, s.parseStatsdLine("requests:2|c"))
+	require.NoError(t, s.Gather(acc))
+	acc.AssertContainsFields(t, "requests",
+		map[string]interface{}{"value": int64(6)})
+}
+
 func TestParseKeyValue(t *testing.T) {
 	k, v := parseKeyValue("foo=bar")
 	if k != "foo" {
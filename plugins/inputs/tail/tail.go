@@ -11,8 +11,12 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal/globpath"
+	"github.com/influxdata/telegraf/internal/multiline"
+	"github.com/influxdata/telegraf/internal/offsets"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/parsers/csv"
+	"github.com/influxdata/telegraf/plugins/parsers/nmon"
 )
 
 const (
@@ -24,12 +28,21 @@ type Tail struct {
 	FromBeginning bool
 	Pipe          bool
 	WatchMethod   string
+	Multiline     *multiline.Config `toml:"multiline"`
+	OffsetsFile   string
 
 	tailers []*tail.Tail
 	parser  parsers.Parser
+	matcher *multiline.Matcher
+	offsets *offsets.Store
+	state   telegraf.StateStore
 	wg      sync.WaitGroup
 	acc     telegraf.Accumulator
 
+	// parsed are the offsets of the end of the last lines parsed
+	mu     sync.Mutex
+	parsed map[string]int64
+
 	sync.Mutex
 }
 
@@ -55,6 +68,12 @@ const sampleConfig = `
   ## Whether file is a named pipe
   pipe = false
 
+  ## File to save the offsets of the files to, so they are read from where
+  ## they were left after a restart, instead of from the beginning or the
+  ## end. The offsets are saved every interval and when telegraf stops.
+  ## When not set, the offsets are kept in the state_directory of the agent.
+  # offsets_file = "/var/lib/telegraf/tail_offsets.json"
+
   ## Method used to watch for file updates.  Can be either "inotify" or "poll".
   # watch_method = "inotify"
 
@@ -63,6 +82,23 @@ const sampleConfig = `
   ## more about them here:
   ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
   data_format = "influx"
+
+  ## Join the lines of multiline events, like stack traces, with newlines
+  ## before parsing them. A line continues the current event when it matches
+  ## any of the set conditions, and starts a new event otherwise.
+  # [inputs.tail.multiline]
+  #   ## Pattern of the first line of the events, the other lines continue
+  #   ## the current event.
+  #   start = '^\d{4}-\d{2}-\d{2}'
+  #   ## Pattern of the lines continuing the current event.
+  #   continuation = '^\s+at '
+  #   ## Continue the current event with the lines starting with a space or
+  #   ## a tab.
+  #   indented = false
+  #   ## Time after which the last event is parsed when no line follows it.
+  #   timeout = "5s"
+  #   ## Maximum number of lines of an event.
+  #   max_lines = 1000
 `
 
 func (t *Tail) SampleConfig() string {
@@ -74,7 +110,11 @@ func (t *Tail) Description() string {
 }
 
 func (t *Tail) Gather(acc telegraf.Accumulator) error {
-	return nil
+	t.Lock()
+	defer t.Unlock()
+
+	t.setOffsets()
+	return t.saveOffsets()
 }
 
 func (t *Tail) Start(acc telegraf.Accumulator) error {
@@ -82,6 +122,27 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 	defer t.Unlock()
 
 	t.acc = acc
+	t.parsed = make(map[string]int64)
+
+	var err error
+	t.matcher, err = t.Multiline.NewMatcher()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case t.Pipe:
+	case t.OffsetsFile != "":
+		t.offsets, err = offsets.Open(t.OffsetsFile)
+		if err != nil {
+			return fmt.Errorf("E! Error loading the offsets of %s, %s", t.OffsetsFile, err)
+		}
+	case t.state != nil:
+		t.offsets, err = offsets.Load(t.state)
+		if err != nil {
+			return fmt.Errorf("E! Error loading the offsets from the state, %s", err)
+		}
+	}
 
 	var seek *tail.SeekInfo
 	if !t.Pipe && !t.FromBeginning {
@@ -103,11 +164,17 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 			t.acc.AddError(fmt.Errorf("E! Error Glob %s failed to compile, %s", filepath, err))
 		}
 		for file, _ := range g.Match() {
+			location, offset := seek, int64(0)
+			if t.offsets != nil {
+				location, offset = t.offsets.Location(file, t.FromBeginning)
+				t.setParsed(file, offset)
+			}
+
 			tailer, err := tail.TailFile(file,
 				tail.Config{
 					ReOpen:    true,
 					Follow:    true,
-					Location:  seek,
+					Location:  location,
 					MustExist: true,
 					Poll:      poll,
 					Pipe:      t.Pipe,
@@ -119,7 +186,7 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 			}
 			// create a goroutine for each "tailer"
 			t.wg.Add(1)
-			go t.receiver(tailer)
+			go t.receiver(tailer, offset)
 			t.tailers = append(t.tailers, tailer)
 		}
 	}
@@ -128,34 +195,109 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 }
 
 // this is launched as a goroutine to continuously watch a tailed logfile
-// for changes, parse any incoming msgs, and add to the accumulator.
-func (t *Tail) receiver(tailer *tail.Tail) {
+// for changes, parse any incoming msgs, and add to the accumulator. The
+// offset is that of the end of the lines received, from the start offset.
+func (t *Tail) receiver(tailer *tail.Tail, offset int64) {
 	defer t.wg.Done()
 
-	var m telegraf.Metric
-	var err error
-	var line *tail.Line
-	for line = range tailer.Lines {
-		if line.Err != nil {
-			t.acc.AddError(fmt.Errorf("E! Error tailing file %s, Error: %s\n",
-				tailer.Filename, err))
-			continue
+	// the lines of multiline events are buffered until the next event starts
+	buf := t.matcher.NewBuffer()
+	for {
+		select {
+		case line, ok := <-tailer.Lines:
+			if !ok {
+				if event, ok := buf.Flush(); ok {
+					t.parse(tailer, event, offset)
+				}
+				if err := tailer.Err(); err != nil {
+					t.acc.AddError(fmt.Errorf("E! Error tailing file %s, Error: %s\n",
+						tailer.Filename, err))
+				}
+				return
+			}
+			if line.Err != nil {
+				t.acc.AddError(fmt.Errorf("E! Error tailing file %s, Error: %s\n",
+					tailer.Filename, line.Err))
+				continue
+			}
+			// the event returned by Add ends before the line, unless the
+			// lines are not joined
+			end := offset
+			if t.offsets != nil {
+				offset = offsets.Next(tailer, offset, line.Text)
+			}
+			if t.matcher == nil {
+				end = offset
+			}
+
+			// Fix up files with Windows line endings.
+			text := strings.TrimRight(line.Text, "\r")
+
+			if event, ok := buf.Add(text); ok {
+				t.parse(tailer, event, end)
+			}
+		case <-buf.Timeout():
+			if event, ok := buf.Flush(); ok {
+				t.parse(tailer, event, offset)
+			}
 		}
-		// Fix up files with Windows line endings.
-		text := strings.TrimRight(line.Text, "\r")
+	}
+}
+
+// parse parses a line, or a multiline event, ending at offset, and adds the
+// metrics to the accumulator. The events may hold several metrics, like the blocks of nmon
+// files. The lines of the events are parsed one by one by the parsers keeping
+// state across lines, like the csv header rows or the nmon sections, as Parse
+// would start that state anew for every event.
+func (t *Tail) parse(tailer *tail.Tail, text string, offset int64) {
+	// the offset is saved once the line is parsed, even when it is malformed
+	defer t.setParsed(tailer.Filename, offset)
 
-		m, err = t.parser.ParseLine(text)
-		if err == nil {
+	if t.matcher != nil && !keepsState(t.parser) {
+		metrics, err := t.parser.Parse([]byte(text))
+		if err != nil {
+			t.acc.AddError(fmt.Errorf("E! Malformed log line in %s: [%s], Error: %s\n",
+				tailer.Filename, text, err))
+			return
+		}
+		for _, m := range metrics {
 			t.acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
-		} else {
+		}
+		return
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		m, err := t.parser.ParseLine(line)
+		if err != nil {
 			t.acc.AddError(fmt.Errorf("E! Malformed log line in %s: [%s], Error: %s\n",
-				tailer.Filename, line.Text, err))
+				tailer.Filename, line, err))
+			continue
+		}
+		// parsers skipping header or comment lines return no metric
+		if m != nil {
+			t.acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
 		}
 	}
-	if err := tailer.Err(); err != nil {
-		t.acc.AddError(fmt.Errorf("E! Error tailing file %s, Error: %s\n",
-			tailer.Filename, err))
+}
+
+// setParsed sets the offset of the end of the last line parsed from a file.
+func (t *Tail) setParsed(file string, offset int64) {
+	if t.offsets == nil {
+		return
+	}
+	t.mu.Lock()
+	t.parsed[file] = offset
+	t.mu.Unlock()
+}
+
+// keepsState tells whether the parser keeps state across the lines given to
+// ParseLine.
+func keepsState(parser parsers.Parser) bool {
+	switch parser.(type) {
+	case *csv.Parser, *nmon.Parser:
+		return true
 	}
+	return false
 }
 
 func (t *Tail) Stop() {
@@ -169,13 +311,49 @@ func (t *Tail) Stop() {
 		}
 		tailer.Cleanup()
 	}
+	// the offsets are taken once the receivers parsed the lines they read
 	t.wg.Wait()
+	t.setOffsets()
+
+	if err := t.saveOffsets(); err != nil {
+		t.acc.AddError(err)
+	}
+}
+
+// setOffsets sets the offsets of the tailed files to the end of the lines
+// parsed, the lines still buffered are read again after a restart.
+// Assumes t's lock is held!
+func (t *Tail) setOffsets() {
+	if t.offsets == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for file, offset := range t.parsed {
+		t.offsets.Set(file, offset)
+	}
+}
+
+// saveOffsets saves the offsets to the offsets file.
+// Assumes t's lock is held!
+func (t *Tail) saveOffsets() error {
+	if t.offsets == nil {
+		return nil
+	}
+	if err := t.offsets.Save(); err != nil {
+		return fmt.Errorf("E! Error saving the offsets to %s, %s", t.OffsetsFile, err)
+	}
+	return nil
 }
 
 func (t *Tail) SetParser(parser parsers.Parser) {
 	t.parser = parser
 }
 
+func (t *Tail) SetStateStore(store telegraf.StateStore) {
+	t.state = store
+}
+
 func init() {
 	inputs.Add("tail", func() telegraf.Input {
 		return NewTail()
@@ -5,7 +5,12 @@ import (
 	"os"
 	"runtime"
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/multiline"
+	"github.com/influxdata/telegraf/internal/offsets"
+	"github.com/influxdata/telegraf/internal/state"
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/testutil"
 
@@ -133,3 +138,253 @@ func TestTailDosLineendings(t *testing.T) {
 			"usage_idle": float64(200),
 		})
 }
+
+func TestTailMultiline(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	_, err = tmpfile.WriteString("{\n  \"value\": 1\n}\n{\n  \"value\": 2\n}\n")
+	require.NoError(t, err)
+
+	tt := NewTail()
+	tt.FromBeginning = true
+	tt.Files = []string{tmpfile.Name()}
+	tt.Multiline = &multiline.Config{
+		Start:   `^\{`,
+		Timeout: internal.Duration{Duration: 100 * time.Millisecond},
+	}
+	p, err := parsers.NewParser(&parsers.Config{
+		DataFormat: "json",
+		MetricName: "json",
+	})
+	require.NoError(t, err)
+	tt.SetParser(p)
+	defer tt.Stop()
+	defer tmpfile.Close()
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	require.NoError(t, acc.GatherError(tt.Gather))
+
+	// the second event is parsed on timeout
+	acc.Wait(2)
+	assert.Empty(t, acc.Errors)
+	require.Len(t, acc.Metrics, 2)
+	assert.Equal(t, map[string]interface{}{"value": float64(1)}, acc.Metrics[0].Fields)
+	assert.Equal(t, map[string]interface{}{"value": float64(2)}, acc.Metrics[1].Fields)
+}
+
+func TestTailMultilineCSV(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	_, err = tmpfile.WriteString("host,value\n@a,1\nb,2\n@c,3\n")
+	require.NoError(t, err)
+
+	tt := NewTail()
+	tt.FromBeginning = true
+	tt.Files = []string{tmpfile.Name()}
+	tt.Multiline = &multiline.Config{
+		Start:   `^@`,
+		Timeout: internal.Duration{Duration: 100 * time.Millisecond},
+	}
+	p, err := parsers.NewParser(&parsers.Config{
+		DataFormat:        "csv",
+		MetricName:        "csv",
+		CSVHeaderRowCount: 1,
+		CSVTagColumns:     []string{"host"},
+		CSVColumnTypes:    []string{"string", "int"},
+	})
+	require.NoError(t, err)
+	tt.SetParser(p)
+	defer tt.Stop()
+	defer tmpfile.Close()
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	require.NoError(t, acc.GatherError(tt.Gather))
+
+	// the header row is only at the start of the file
+	acc.Wait(3)
+	assert.Empty(t, acc.Errors)
+	require.Len(t, acc.Metrics, 3)
+	for i, host := range []string{"@a", "b", "@c"} {
+		assert.Equal(t, map[string]string{"host": host}, acc.Metrics[i].Tags)
+		assert.Equal(t, map[string]interface{}{"value": int64(i + 1)}, acc.Metrics[i].Fields)
+	}
+}
+
+func TestTailMultilineNmon(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	_, err = tmpfile.WriteString(`AAA,host,rcvioc03
+CPU_ALL,CPU Total rcvioc03,User%,Sys%,Wait%,Idle%,Busy,PhysicalCPUs
+ZZZZ,T0001,15:59:34,24-JAN-2018
+CPU_ALL,T0001,2.9,1.5,0.3,95.3,,4
+ZZZZ,T0002,16:00:34,24-JAN-2018
+CPU_ALL,T0002,3.0,1.5,0.3,95.2,,4
+`)
+	require.NoError(t, err)
+
+	tt := NewTail()
+	tt.FromBeginning = true
+	tt.Files = []string{tmpfile.Name()}
+	tt.Multiline = &multiline.Config{
+		Start:   `^ZZZZ,`,
+		Timeout: internal.Duration{Duration: 100 * time.Millisecond},
+	}
+	p, err := parsers.NewParser(&parsers.Config{
+		DataFormat:   "nmon",
+		NmonTimezone: "UTC",
+	})
+	require.NoError(t, err)
+	tt.SetParser(p)
+	defer tt.Stop()
+	defer tmpfile.Close()
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	require.NoError(t, acc.GatherError(tt.Gather))
+
+	// the AAA and header lines of the first event apply to the next ones
+	acc.Wait(2)
+	assert.Empty(t, acc.Errors)
+	require.Len(t, acc.Metrics, 2)
+	for i, m := range acc.Metrics {
+		assert.Equal(t, "nmon_CPU_ALL", m.Measurement)
+		assert.Equal(t, "rcvioc03", m.Tags["host"])
+		assert.Equal(t, time.Date(2018, 1, 24, 15, 59+i, 34, 0, time.UTC), m.Time.UTC())
+	}
+	assert.Equal(t, 2.9, acc.Metrics[0].Fields["user"])
+	assert.Equal(t, 3.0, acc.Metrics[1].Fields["user"])
+}
+
+func TestTailOffsets(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	defer tmpfile.Close()
+	_, err = tmpfile.WriteString("cpu usage_idle=100\n")
+	require.NoError(t, err)
+
+	offsetsFile := tmpfile.Name() + ".offsets"
+	defer os.Remove(offsetsFile)
+
+	newTail := func() *Tail {
+		tt := NewTail()
+		tt.Files = []string{tmpfile.Name()}
+		tt.OffsetsFile = offsetsFile
+		p, _ := parsers.NewInfluxParser()
+		tt.SetParser(p)
+		return tt
+	}
+
+	tt := newTail()
+	tt.FromBeginning = true
+	acc := testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	acc.Wait(1)
+	tt.Stop()
+
+	// the line written while stopped is read from the saved offset
+	_, err = tmpfile.WriteString("cpu2 usage_idle=200\n")
+	require.NoError(t, err)
+
+	tt = newTail()
+	acc = testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	defer tt.Stop()
+
+	acc.Wait(1)
+	acc.AssertContainsFields(t, "cpu2",
+		map[string]interface{}{
+			"usage_idle": float64(200),
+		})
+	assert.Len(t, acc.Metrics, 1)
+}
+
+func TestTailOffsetsBuffered(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	defer tmpfile.Close()
+	first := "{\n  \"value\": 1\n}\n"
+	_, err = tmpfile.WriteString(first + "{\n  \"value\": 2\n}\n")
+	require.NoError(t, err)
+
+	offsetsFile := tmpfile.Name() + ".offsets"
+	defer os.Remove(offsetsFile)
+
+	tt := NewTail()
+	tt.FromBeginning = true
+	tt.Files = []string{tmpfile.Name()}
+	tt.OffsetsFile = offsetsFile
+	tt.Multiline = &multiline.Config{
+		Start:   `^\{`,
+		Timeout: internal.Duration{Duration: time.Hour},
+	}
+	p, err := parsers.NewParser(&parsers.Config{
+		DataFormat: "json",
+		MetricName: "json",
+	})
+	require.NoError(t, err)
+	tt.SetParser(p)
+	defer tt.Stop()
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	acc.Wait(1)
+	require.NoError(t, acc.GatherError(tt.Gather))
+
+	// the saved offset is not ahead of the buffered event
+	saved, err := offsets.Open(offsetsFile)
+	require.NoError(t, err)
+	offset, ok := saved.Resume(tmpfile.Name())
+	assert.True(t, ok)
+	assert.Equal(t, int64(len(first)), offset)
+}
+
+func TestTailStateOffsets(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	defer tmpfile.Close()
+	_, err = tmpfile.WriteString("cpu usage_idle=100\n")
+	require.NoError(t, err)
+
+	st, err := state.Open("")
+	require.NoError(t, err)
+
+	newTail := func() *Tail {
+		tt := NewTail()
+		tt.Files = []string{tmpfile.Name()}
+		p, _ := parsers.NewInfluxParser()
+		tt.SetParser(p)
+		tt.SetStateStore(st.Namespace("inputs.tail"))
+		return tt
+	}
+
+	tt := newTail()
+	tt.FromBeginning = true
+	acc := testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	acc.Wait(1)
+	tt.Stop()
+
+	// without an offsets file, the offsets are kept in the state store
+	_, err = tmpfile.WriteString("cpu2 usage_idle=200\n")
+	require.NoError(t, err)
+
+	tt = newTail()
+	acc = testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	defer tt.Stop()
+
+	acc.Wait(1)
+	acc.AssertContainsFields(t, "cpu2",
+		map[string]interface{}{
+			"usage_idle": float64(200),
+		})
+	assert.Len(t, acc.Metrics, 1)
+}
@@ -0,0 +1,50 @@
+package outputs
+
+import (
+	"fmt"
+)
+
+// PermanentError is returned by an output that rejected a batch which will
+// never be accepted, for example because it contains a malformed metric.
+// The batch is not retried; when it holds several metrics it is split and
+// written again in parts to isolate the offending metrics.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+// Permanent wraps err in a PermanentError.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent returns true if err is a PermanentError.
+func IsPermanent(err error) bool {
+	_, ok := err.(*PermanentError)
+	return ok
+}
+
+// PartialWriteError is returned by an output that wrote only some metrics of
+// a batch. The metrics of the batch not listed in Rejected or Retry have
+// been written.
+type PartialWriteError struct {
+	Err error
+	// Rejected are the indices in the batch of the metrics which will never
+	// be accepted, they are dropped. When the output can not tell which
+	// metrics were rejected it is empty.
+	Rejected []int
+	// Retry are the indices in the batch of the metrics which failed with a
+	// transient error, they are written again on the next flush.
+	Retry []int
+}
+
+func (e *PartialWriteError) Error() string {
+	return fmt.Sprintf("partial write, %d metrics rejected, %d to retry: %s",
+		len(e.Rejected), len(e.Retry), e.Err)
+}
@@ -90,6 +90,18 @@ func (f *File) Write(metrics []telegraf.Metric) error {
 		return nil
 	}
 
+	// formats grouping metrics need to see the whole batch
+	if s, ok := f.serializer.(serializers.BatchSerializer); ok {
+		b, err := s.SerializeBatch(metrics)
+		if err != nil {
+			return fmt.Errorf("failed to serialize message: %s", err)
+		}
+		if _, err = f.writer.Write(b); err != nil {
+			return fmt.Errorf("failed to write message: %s", err)
+		}
+		return nil
+	}
+
 	for _, metric := range metrics {
 		b, err := f.serializer.Serialize(metric)
 		if err != nil {
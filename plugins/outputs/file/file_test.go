@@ -40,6 +40,29 @@ func TestFileExistingFile(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestFileBatchSerializer(t *testing.T) {
+	s, _ := serializers.NewPrometheusSerializer(true)
+	fh := tmpFile()
+	f := File{
+		Files:      []string{fh},
+		serializer: s,
+	}
+
+	err := f.Connect()
+	assert.NoError(t, err)
+
+	metrics := append(testutil.MockMetrics(), testutil.MockMetrics()...)
+	err = f.Write(metrics)
+	assert.NoError(t, err)
+
+	validateFile(fh, "# TYPE test1 untyped\n"+
+		"test1{tag1=\"value1\"} 1 1257894000000\n"+
+		"test1{tag1=\"value1\"} 1 1257894000000\n", t)
+
+	err = f.Close()
+	assert.NoError(t, err)
+}
+
 func TestFileNewFile(t *testing.T) {
 	s, _ := serializers.NewInfluxSerializer()
 	fh := tmpFile()
@@ -183,7 +183,9 @@ func (i *InfluxDB) Description() string {
 // Write will choose a random server in the cluster to write to until a successful write
 // occurs, logging each unsuccessful. If all servers fail, return error.
 func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
-	r := metric.NewReader(metrics)
+	// InfluxDB has no histogram or summary type, their buckets are written
+	// as series tagged with their bound
+	r := metric.NewReader(metric.Flatten(metrics))
 
 	// This will get set to nil if a successful write occurs
 	err := fmt.Errorf("Could not write to any InfluxDB server in cluster")
@@ -201,28 +203,29 @@ func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
 			}
 
 			if strings.Contains(e.Error(), "field type conflict") {
-				log.Printf("E! Field type conflict, dropping conflicted points: %s", e)
-				// setting err to nil, otherwise we will keep retrying and points
-				// w/ conflicting types will get stuck in the buffer forever.
-				err = nil
+				// The conflicted points were dropped by InfluxDB and the
+				// others written, the conflicted points will get stuck in
+				// the buffer forever if retried.  The error does not tell
+				// which points conflict, the batch is split to reject only
+				// them, writing the other points again is harmless.
+				err = outputs.Permanent(e)
 				break
 			}
 
 			if strings.Contains(e.Error(), "points beyond retention policy") {
-				log.Printf("W! Points beyond retention policy: %s", e)
 				// This error is indicates the point is older than the
 				// retention policy permits, and is probably not a cause for
 				// concern.  Retrying will not help unless the retention
 				// policy is modified.
-				err = nil
+				err = &outputs.PartialWriteError{Err: e}
 				break
 			}
 
 			if strings.Contains(e.Error(), "unable to parse") {
-				log.Printf("E! Parse error; dropping points: %s", e)
 				// This error indicates a bug in Telegraf or InfluxDB parsing
-				// of line protocol.  Retries will not be successful.
-				err = nil
+				// of line protocol.  Retries will not be successful, the
+				// batch is split to drop only the malformed points.
+				err = outputs.Permanent(e)
 				break
 			}
 
@@ -3,10 +3,14 @@ package influxdb
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/outputs/influxdb/client"
 	"github.com/influxdata/telegraf/testutil"
 
@@ -185,6 +189,8 @@ func TestHTTPError_WriteErrors(t *testing.T) {
 		contentType string
 		body        string
 		err         error
+		partial     bool
+		permanent   bool
 	}{
 		{
 			// HTTP/1.1 400 Bad Request
@@ -194,11 +200,11 @@ func TestHTTPError_WriteErrors(t *testing.T) {
 			// {
 			//     "error": "partial write: points beyond retention policy dropped=1"
 			// }
-			name:        "beyond retention policy is not an error",
+			name:        "beyond retention policy is a partial write",
 			status:      http.StatusBadRequest,
 			contentType: "application/json",
 			body:        `{"error":"partial write: points beyond retention policy dropped=1"}`,
-			err:         nil,
+			partial:     true,
 		},
 		{
 			// HTTP/1.1 400 Bad Request
@@ -208,11 +214,11 @@ func TestHTTPError_WriteErrors(t *testing.T) {
 			// {
 			//     "error": "unable to parse 'foo bar=': missing field value"
 			// }
-			name:        "unable to parse is not an error",
+			name:        "unable to parse is a permanent error",
 			status:      http.StatusBadRequest,
 			contentType: "application/json",
 			body:        `{"error":"unable to parse 'foo bar=': missing field value"}`,
-			err:         nil,
+			permanent:   true,
 		},
 		{
 			// HTTP/1.1 400 Bad Request
@@ -222,11 +228,11 @@ func TestHTTPError_WriteErrors(t *testing.T) {
 			// {
 			//     "error": "partial write: field type conflict: input field \"bar\" on measurement \"foo\" is type float, already exists as type integer dropped=1"
 			// }
-			name:        "field type conflict is not an error",
+			name:        "field type conflict is a permanent error",
 			status:      http.StatusBadRequest,
 			contentType: "application/json",
 			body:        `{"error": "partial write: field type conflict: input field \"bar\" on measurement \"foo\" is type float, already exists as type integer dropped=1"}`,
-			err:         nil,
+			permanent:   true,
 		},
 		{
 			// HTTP/1.1 500 Internal Server Error
@@ -275,12 +281,52 @@ func TestHTTPError_WriteErrors(t *testing.T) {
 			err := influx.Connect()
 			require.NoError(t, err)
 			err = influx.Write(testutil.MockMetrics())
-			require.Equal(t, tt.err, err)
+			switch {
+			case tt.partial:
+				require.IsType(t, &outputs.PartialWriteError{}, err)
+			case tt.permanent:
+				require.True(t, outputs.IsPermanent(err))
+			default:
+				require.Equal(t, tt.err, err)
+			}
 			require.NoError(t, influx.Close())
 		})
 	}
 }
 
+func TestFieldTypeConflictRejected(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(body), "value=1i") {
+			rw.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintln(rw, `{"error": "partial write: field type conflict: input field \"value\" on measurement \"test1\" is type integer, already exists as type float dropped=1"}`)
+			return
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	influx := &InfluxDB{
+		URLs:     []string{ts.URL},
+		Database: "test",
+	}
+	ro := models.NewRunningOutput("influxdb", influx, &models.OutputConfig{}, 10, 100)
+	require.NoError(t, influx.Connect())
+	defer influx.Close()
+
+	for i := 0; i < 5; i++ {
+		ro.AddMetric(testutil.TestMetric(float64(i)+0.5, "test1"))
+	}
+	ro.AddMetric(testutil.TestMetric(1, "test1"))
+
+	// only the conflicting metric is rejected, the batch is not retried
+	require.NoError(t, ro.Write())
+	assert.Equal(t, int64(1), ro.MetricsRejected.Get())
+	assert.Equal(t, int64(5), ro.MetricsWritten.Get())
+	require.NoError(t, ro.Write())
+	assert.Equal(t, int64(0), ro.BufferSize.Get())
+}
+
 type MockClient struct {
 	writeStreamCalled int
 	contentLength     int
@@ -124,24 +124,39 @@ func (sw *SocketWriter) Write(metrics []telegraf.Metric) error {
 		}
 	}
 
-	for _, m := range metrics {
+	// metrics which can not be serialized are rejected, the remaining
+	// metrics are still written.
+	var rejected []int
+	var serr error
+	for i, m := range metrics {
 		bs, err := sw.Serialize(m)
 		if err != nil {
-			//TODO log & keep going with remaining metrics
-			return err
+			rejected = append(rejected, i)
+			serr = err
+			continue
 		}
 		if _, err := sw.Conn.Write(bs); err != nil {
-			//TODO log & keep going with remaining strings
 			if err, ok := err.(net.Error); !ok || !err.Temporary() {
 				// permanent error. close the connection
 				log.Printf("write error: %s\n", err)
 				sw.Close()
 				sw.Conn = nil
 			}
-			return err
+			if i == 0 {
+				return err
+			}
+			// the metrics before i have been written
+			retry := make([]int, 0, len(metrics)-i)
+			for j := i; j < len(metrics); j++ {
+				retry = append(retry, j)
+			}
+			return &outputs.PartialWriteError{Err: err, Rejected: rejected, Retry: retry}
 		}
 	}
 
+	if len(rejected) > 0 {
+		return &outputs.PartialWriteError{Err: serr, Rejected: rejected}
+	}
 	return nil
 }
 
@@ -181,8 +196,14 @@ func (p *JsonSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 	tags := metric.Tags()
 	fields := metric.Fields()
 	m["t"] = metric.UnixNano() / units_nanoseconds
+	// the instance is the object tag of the nmon data format when the
+	// metric has no a_id tag
+	idTag := "a_id"
+	if _, ok := tags[idTag]; !ok {
+		idTag = "object"
+	}
 	for k, v := range tags {
-		if k == "a_id" {
+		if k == idTag {
 			continue
 		}
 		m[k] = v
@@ -191,7 +212,7 @@ func (p *JsonSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 	var s = make([]map[string]interface{}, 0)
 	for k, v := range fields {
 		ss := make(map[string]interface{})
-		ss["a_id"] = tags["a_id"]
+		ss["a_id"] = tags[idTag]
 		ss["tag"] = k
 		ss["value"] = v
 		s = append(s, ss)
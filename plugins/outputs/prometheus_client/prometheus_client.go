@@ -8,13 +8,13 @@ import (
 	"os"
 	"regexp"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -300,80 +300,36 @@ func (p *PrometheusClient) Write(metrics []telegraf.Metric) error {
 
 		switch point.Type() {
 		case telegraf.Summary:
-			var mname string
-			var sum float64
-			var count uint64
+			buckets, sum, count := metric.Buckets(point)
 			summaryvalue := make(map[float64]float64)
-			for fn, fv := range point.Fields() {
-				var value float64
-				switch fv := fv.(type) {
-				case int64:
-					value = float64(fv)
-				case float64:
-					value = fv
-				default:
-					continue
-				}
-
-				switch fn {
-				case "sum":
-					sum = value
-				case "count":
-					count = uint64(value)
-				default:
-					limit, err := strconv.ParseFloat(fn, 64)
-					if err == nil {
-						summaryvalue[limit] = value
-					}
-				}
+			for _, b := range buckets {
+				summaryvalue[b.Bound] = b.Value
 			}
 			sample := &Sample{
 				Labels:       labels,
 				SummaryValue: summaryvalue,
-				Count:        count,
+				Count:        uint64(count),
 				Sum:          sum,
 				Expiration:   now.Add(p.ExpirationInterval.Duration),
 			}
-			mname = sanitize(point.Name())
+			mname := sanitize(point.Name())
 
 			p.addMetricFamily(point, sample, mname, sampleID)
 
 		case telegraf.Histogram:
-			var mname string
-			var sum float64
-			var count uint64
+			buckets, sum, count := metric.Buckets(point)
 			histogramvalue := make(map[float64]uint64)
-			for fn, fv := range point.Fields() {
-				var value float64
-				switch fv := fv.(type) {
-				case int64:
-					value = float64(fv)
-				case float64:
-					value = fv
-				default:
-					continue
-				}
-
-				switch fn {
-				case "sum":
-					sum = value
-				case "count":
-					count = uint64(value)
-				default:
-					limit, err := strconv.ParseFloat(fn, 64)
-					if err == nil {
-						histogramvalue[limit] = uint64(value)
-					}
-				}
+			for _, b := range buckets {
+				histogramvalue[b.Bound] = uint64(b.Value)
 			}
 			sample := &Sample{
 				Labels:         labels,
 				HistogramValue: histogramvalue,
-				Count:          count,
+				Count:          uint64(count),
 				Sum:            sum,
 				Expiration:     now.Add(p.ExpirationInterval.Duration),
 			}
-			mname = sanitize(point.Name())
+			mname := sanitize(point.Name())
 
 			p.addMetricFamily(point, sample, mname, sampleID)
 
@@ -0,0 +1,205 @@
+package prometheus_remote_write
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/prompb"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
+)
+
+var sampleConfig = `
+  ## URL of the remote write endpoint, ie of Prometheus, Cortex or the
+  ## Thanos receiver.
+  url = "http://127.0.0.1:9090/api/v1/write"
+
+  ## Timeout of a write request.
+  # timeout = "5s"
+
+  ## Optional HTTP basic authentication.
+  # username = "telegraf"
+  # password = "metricsmetricsmetricsmetrics"
+
+  ## Optional HTTP headers, ie the tenant of Cortex.
+  # http_headers = {"X-Scope-OrgID" = "telegraf"}
+
+  ## Optional SSL Config
+  # ssl_ca = "/etc/telegraf/ca.pem"
+  # ssl_cert = "/etc/telegraf/cert.pem"
+  # ssl_key = "/etc/telegraf/key.pem"
+  ## Use SSL but skip chain & host verification
+  # insecure_skip_verify = false
+`
+
+// PrometheusRemoteWrite writes the metrics to a Prometheus remote write
+// endpoint. The metrics are mapped to Prometheus samples like the
+// prometheus data format does.
+type PrometheusRemoteWrite struct {
+	URL         string            `toml:"url"`
+	Timeout     internal.Duration `toml:"timeout"`
+	Username    string            `toml:"username"`
+	Password    string            `toml:"password"`
+	HTTPHeaders map[string]string `toml:"http_headers"`
+
+	// Path to CA file
+	SSLCA string `toml:"ssl_ca"`
+	// Path to host cert file
+	SSLCert string `toml:"ssl_cert"`
+	// Path to cert key file
+	SSLKey string `toml:"ssl_key"`
+	// Use SSL but skip chain & host verification
+	InsecureSkipVerify bool
+
+	client *http.Client
+}
+
+func (p *PrometheusRemoteWrite) Connect() error {
+	if p.URL == "" {
+		return fmt.Errorf("prometheus_remote_write: url is required")
+	}
+
+	tlsConfig, err := internal.GetTLSConfig(
+		p.SSLCert, p.SSLKey, p.SSLCA, p.InsecureSkipVerify)
+	if err != nil {
+		return err
+	}
+
+	p.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: p.Timeout.Duration,
+	}
+	return nil
+}
+
+func (p *PrometheusRemoteWrite) Close() error {
+	return nil
+}
+
+func (p *PrometheusRemoteWrite) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *PrometheusRemoteWrite) Description() string {
+	return "Configuration for the Prometheus remote write client to send metrics to"
+}
+
+// Write sends the metrics in a single write request.
+func (p *PrometheusRemoteWrite) Write(metrics []telegraf.Metric) error {
+	req := writeRequest(metrics)
+	if len(req.Timeseries) == 0 {
+		return nil
+	}
+
+	body, err := prompb.Encode(req)
+	if err != nil {
+		return outputs.Permanent(err)
+	}
+
+	httpReq, err := http.NewRequest("POST", p.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Encoding", "snappy")
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	httpReq.Header.Set("User-Agent", "Telegraf")
+	httpReq.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	for k, v := range p.HTTPHeaders {
+		httpReq.Header.Set(k, v)
+	}
+	if p.Username != "" || p.Password != "" {
+		httpReq.SetBasicAuth(p.Username, p.Password)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	err = fmt.Errorf("prometheus_remote_write: %s returned %s: %s",
+		p.URL, resp.Status, strings.TrimSpace(string(msg)))
+	// the endpoint will never accept a batch it rejected with a client
+	// error, except when it is throttling the writes
+	if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+		return outputs.Permanent(err)
+	}
+	return err
+}
+
+// writeRequest returns the series of the metrics, with the samples of a
+// series in time order.
+func writeRequest(metrics []telegraf.Metric) *prompb.WriteRequest {
+	req := &prompb.WriteRequest{}
+	index := make(map[string]*prompb.TimeSeries)
+	for _, m := range metrics {
+		ts := m.UnixNano() / int64(time.Millisecond)
+		for _, sample := range prometheus.Samples(m) {
+			labels := seriesLabels(sample)
+			key := seriesKey(labels)
+			series, ok := index[key]
+			if !ok {
+				series = &prompb.TimeSeries{Labels: labels}
+				index[key] = series
+				req.Timeseries = append(req.Timeseries, series)
+			}
+			series.Samples = append(series.Samples,
+				&prompb.Sample{Value: sample.Value, Timestamp: ts})
+		}
+	}
+
+	for _, series := range req.Timeseries {
+		samples := series.Samples
+		sort.SliceStable(samples, func(i, j int) bool {
+			return samples[i].Timestamp < samples[j].Timestamp
+		})
+	}
+	return req
+}
+
+// seriesLabels returns the labels of the series of a sample, sorted by name.
+func seriesLabels(sample prometheus.Sample) []*prompb.Label {
+	labels := make([]*prompb.Label, 0, len(sample.Labels)+1)
+	labels = append(labels, &prompb.Label{Name: prompb.MetricNameLabel, Value: sample.Name})
+	for k, v := range sample.Labels {
+		labels = append(labels, &prompb.Label{Name: k, Value: v})
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].Name < labels[j].Name
+	})
+	return labels
+}
+
+func seriesKey(labels []*prompb.Label) string {
+	var buf bytes.Buffer
+	for _, l := range labels {
+		buf.WriteString(l.Name)
+		buf.WriteByte(0)
+		buf.WriteString(l.Value)
+		buf.WriteByte(0)
+	}
+	return buf.String()
+}
+
+func init() {
+	outputs.Add("prometheus_remote_write", func() telegraf.Output {
+		return &PrometheusRemoteWrite{
+			Timeout: internal.Duration{Duration: 5 * time.Second},
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package prometheus_remote_write
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/prompb"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+func newMetric(t *testing.T, name string, tags map[string]string,
+	fields map[string]interface{}, ts time.Time) telegraf.Metric {
+	m, err := metric.New(name, tags, fields, ts)
+	require.NoError(t, err)
+	return m
+}
+
+func TestWrite(t *testing.T) {
+	var req *prompb.WriteRequest
+	var header http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		req, err = prompb.Decode(body)
+		require.NoError(t, err)
+		header = r.Header
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	p := &PrometheusRemoteWrite{
+		URL:         ts.URL,
+		HTTPHeaders: map[string]string{"X-Scope-OrgID": "telegraf"},
+	}
+	require.NoError(t, p.Connect())
+
+	t1 := time.Unix(1500000000, 0)
+	t2 := t1.Add(10 * time.Second)
+	err := p.Write([]telegraf.Metric{
+		newMetric(t, "cpu", map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": 90.5}, t2),
+		newMetric(t, "cpu", map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": 91.0}, t1),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "snappy", header.Get("Content-Encoding"))
+	assert.Equal(t, "application/x-protobuf", header.Get("Content-Type"))
+	assert.Equal(t, "0.1.0", header.Get("X-Prometheus-Remote-Write-Version"))
+	assert.Equal(t, "telegraf", header.Get("X-Scope-OrgID"))
+
+	require.Len(t, req.Timeseries, 1)
+	series := req.Timeseries[0]
+	assert.Equal(t, []*prompb.Label{
+		{Name: "__name__", Value: "cpu_usage_idle"},
+		{Name: "host", Value: "a"},
+	}, series.Labels)
+	// the samples of a series are in time order
+	assert.Equal(t, []*prompb.Sample{
+		{Value: 91.0, Timestamp: 1500000000000},
+		{Value: 90.5, Timestamp: 1500000010000},
+	}, series.Samples)
+}
+
+func TestWriteErrors(t *testing.T) {
+	status := http.StatusBadRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "out of order sample", status)
+	}))
+	defer ts.Close()
+
+	p := &PrometheusRemoteWrite{URL: ts.URL}
+	require.NoError(t, p.Connect())
+	metrics := []telegraf.Metric{
+		newMetric(t, "cpu", nil, map[string]interface{}{"value": 1.0}, time.Now()),
+	}
+
+	// client errors are never retried
+	err := p.Write(metrics)
+	require.Error(t, err)
+	assert.True(t, outputs.IsPermanent(err))
+
+	status = http.StatusTooManyRequests
+	err = p.Write(metrics)
+	require.Error(t, err)
+	assert.False(t, outputs.IsPermanent(err))
+
+	status = http.StatusServiceUnavailable
+	err = p.Write(metrics)
+	require.Error(t, err)
+	assert.False(t, outputs.IsPermanent(err))
+}
+
+func TestConnectRequiresURL(t *testing.T) {
+	assert.Error(t, (&PrometheusRemoteWrite{}).Connect())
+}
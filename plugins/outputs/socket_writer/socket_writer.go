@@ -104,19 +104,23 @@ func (sw *SocketWriter) Write(metrics []telegraf.Metric) error {
 		}
 	}
 
+	// on stream sockets formats grouping metrics need to see the whole
+	// batch, datagrams are kept to one metric each
+	if s, ok := sw.Serializer.(serializers.BatchSerializer); ok && !sw.isDatagram() {
+		bs, err := s.SerializeBatch(metrics)
+		if err != nil {
+			return err
+		}
+		return sw.write(bs)
+	}
+
 	for _, m := range metrics {
 		bs, err := sw.Serialize(m)
 		if err != nil {
 			//TODO log & keep going with remaining metrics
 			return err
 		}
-		if _, err := sw.Conn.Write(bs); err != nil {
-			//TODO log & keep going with remaining strings
-			if err, ok := err.(net.Error); !ok || !err.Temporary() {
-				// permanent error. close the connection
-				sw.Close()
-				sw.Conn = nil
-			}
+		if err := sw.write(bs); err != nil {
 			return err
 		}
 	}
@@ -124,6 +128,28 @@ func (sw *SocketWriter) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
+func (sw *SocketWriter) write(bs []byte) error {
+	if _, err := sw.Conn.Write(bs); err != nil {
+		//TODO log & keep going with remaining strings
+		if err, ok := err.(net.Error); !ok || !err.Temporary() {
+			// permanent error. close the connection
+			sw.Close()
+			sw.Conn = nil
+		}
+		return err
+	}
+	return nil
+}
+
+// isDatagram returns true if the socket sends datagrams.
+func (sw *SocketWriter) isDatagram() bool {
+	switch strings.SplitN(sw.Address, "://", 2)[0] {
+	case "udp", "udp4", "udp6", "unixgram":
+		return true
+	}
+	return false
+}
+
 // Close closes the connection. Noop if already closed.
 func (sw *SocketWriter) Close() error {
 	if sw.Conn == nil {
@@ -0,0 +1,329 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// Parser parses comma separated values, one metric per record.
+type Parser struct {
+	MetricName string
+	// HeaderRowCount is the number of rows holding the column names, the
+	// names of a column in several rows are concatenated.
+	HeaderRowCount int
+	// SkipRows is the number of rows skipped before the header.
+	SkipRows int
+	// SkipColumns is the number of columns skipped at the start of every
+	// row.
+	SkipColumns int
+	// Delimiter separates the columns, a comma by default.
+	Delimiter string
+	// Comment starts the lines which are skipped.
+	Comment string
+	// TrimSpace removes the leading spaces of the values.
+	TrimSpace bool
+	// ColumnNames names the columns, overriding the header.
+	ColumnNames []string
+	// ColumnTypes are the types of the columns: int, float, bool or string.
+	// The type of a column without one is guessed from its value.
+	ColumnTypes []string
+	// TagColumns are the columns turned into tags.
+	TagColumns []string
+	// MeasurementColumn is the column holding the measurement name.
+	MeasurementColumn string
+	// TimestampColumn is the column holding the time of the metric, parsed
+	// according to TimestampFormat: unix, unix_ms, unix_us, unix_ns or a Go
+	// time layout.
+	TimestampColumn string
+	TimestampFormat string
+	DefaultTags     map[string]string
+
+	// state of ParseLine, which gets the rows one at a time
+	mu          sync.Mutex
+	skipped     int
+	header      [][]string
+	lineColumns []string
+}
+
+// Init checks the configuration of the parser.
+func (p *Parser) Init() error {
+	if p.HeaderRowCount == 0 && len(p.ColumnNames) == 0 {
+		return fmt.Errorf("csv_header_row_count or csv_column_names is required")
+	}
+	if utf8.RuneCountInString(p.Delimiter) > 1 {
+		return fmt.Errorf("csv_delimiter must be a single character")
+	}
+	if utf8.RuneCountInString(p.Comment) > 1 {
+		return fmt.Errorf("csv_comment must be a single character")
+	}
+	if p.TimestampColumn != "" && p.TimestampFormat == "" {
+		return fmt.Errorf("csv_timestamp_format is required with csv_timestamp_column")
+	}
+	for _, typ := range p.ColumnTypes {
+		switch typ {
+		case "", "int", "float", "bool", "string":
+		default:
+			return fmt.Errorf("invalid csv_column_types %s", typ)
+		}
+	}
+	return nil
+}
+
+func (p *Parser) newReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = p.TrimSpace
+	if p.Delimiter != "" {
+		reader.Comma, _ = utf8.DecodeRuneInString(p.Delimiter)
+	}
+	if p.Comment != "" {
+		reader.Comment, _ = utf8.DecodeRuneInString(p.Comment)
+	}
+	return reader
+}
+
+// Parse parses a whole document, starting with its skipped and header rows.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	reader := p.newReader(bytes.NewReader(buf))
+
+	for i := 0; i < p.SkipRows; i++ {
+		if _, err := reader.Read(); err != nil {
+			if err == io.EOF {
+				return make([]telegraf.Metric, 0), nil
+			}
+			return nil, err
+		}
+	}
+
+	var header [][]string
+	for i := 0; i < p.HeaderRowCount; i++ {
+		record, err := reader.Read()
+		if err != nil {
+			if err == io.EOF {
+				return make([]telegraf.Metric, 0), nil
+			}
+			return nil, err
+		}
+		header = append(header, record)
+	}
+	columns := p.columns(header)
+
+	metrics := make([]telegraf.Metric, 0)
+	now := time.Now().UTC()
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		m, err := p.parseRecord(columns, record, now)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics, nil
+}
+
+// ParseLine parses a single row. The first rows it gets are the skipped and
+// header rows, for which it returns no metric.
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	record, err := p.newReader(strings.NewReader(line)).Read()
+	if err == io.EOF {
+		// empty or comment line
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	if p.skipped < p.SkipRows {
+		p.skipped++
+		p.mu.Unlock()
+		return nil, nil
+	}
+	if len(p.header) < p.HeaderRowCount {
+		p.header = append(p.header, record)
+		p.lineColumns = p.columns(p.header)
+		p.mu.Unlock()
+		return nil, nil
+	}
+	columns := p.lineColumns
+	p.mu.Unlock()
+
+	if columns == nil {
+		columns = p.columns(nil)
+	}
+	return p.parseRecord(columns, record, time.Now().UTC())
+}
+
+// columns returns the names of the columns: the configured ones, or the
+// concatenated names of the header rows.
+func (p *Parser) columns(header [][]string) []string {
+	if len(p.ColumnNames) > 0 {
+		return p.ColumnNames
+	}
+	var columns []string
+	for _, row := range header {
+		if len(row) > p.SkipColumns {
+			row = row[p.SkipColumns:]
+		} else {
+			row = nil
+		}
+		for i, name := range row {
+			if i < len(columns) {
+				columns[i] += name
+			} else {
+				columns = append(columns, name)
+			}
+		}
+	}
+	for i := range columns {
+		columns[i] = strings.TrimSpace(columns[i])
+	}
+	return columns
+}
+
+// parseRecord returns the metric of a record, or nil if the record repeats
+// the column names.
+func (p *Parser) parseRecord(
+	columns []string,
+	record []string,
+	now time.Time,
+) (telegraf.Metric, error) {
+	if len(record) > p.SkipColumns {
+		record = record[p.SkipColumns:]
+	} else {
+		record = nil
+	}
+	if isHeader(columns, record) {
+		return nil, nil
+	}
+
+	name := p.MetricName
+	tags := make(map[string]string)
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	fields := make(map[string]interface{})
+	t := now
+	var hasTimestamp bool
+
+	for i, value := range record {
+		if i >= len(columns) {
+			break
+		}
+		column := columns[i]
+		if column == "" {
+			continue
+		}
+
+		switch {
+		case column == p.MeasurementColumn:
+			if value != "" {
+				name = value
+			}
+			continue
+		case column == p.TimestampColumn:
+			ts, err := internal.ParseTimestamp(value, p.TimestampFormat)
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %s", column, err)
+			}
+			t = ts
+			hasTimestamp = true
+			continue
+		case p.isTag(column):
+			tags[column] = value
+			continue
+		}
+
+		if value == "" {
+			continue
+		}
+		var typ string
+		if i < len(p.ColumnTypes) {
+			typ = p.ColumnTypes[i]
+		}
+		v, err := convert(value, typ)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %s", column, err)
+		}
+		fields[column] = v
+	}
+
+	if p.TimestampColumn != "" && !hasTimestamp {
+		return nil, fmt.Errorf("timestamp column %s not found", p.TimestampColumn)
+	}
+	return metric.New(name, tags, fields, t)
+}
+
+func (p *Parser) isTag(column string) bool {
+	for _, tag := range p.TagColumns {
+		if tag == column {
+			return true
+		}
+	}
+	return false
+}
+
+// isHeader returns true if the record holds the column names, ie the header
+// of a file read by ParseLine, or of concatenated documents.
+func isHeader(columns []string, record []string) bool {
+	if len(record) == 0 || len(record) != len(columns) {
+		return false
+	}
+	for i, value := range record {
+		if strings.TrimSpace(value) != columns[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// convert converts a value to typ, or guesses its type when typ is empty.
+func convert(value string, typ string) (interface{}, error) {
+	switch typ {
+	case "int":
+		return strconv.ParseInt(value, 10, 64)
+	case "float":
+		return strconv.ParseFloat(value, 64)
+	case "bool":
+		return strconv.ParseBool(value)
+	case "string":
+		return value, nil
+	}
+
+	if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return v, nil
+	}
+	if v, err := strconv.ParseFloat(value, 64); err == nil {
+		return v, nil
+	}
+	switch strings.ToLower(value) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return value, nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
@@ -0,0 +1,148 @@
+package csv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHeaderRow(t *testing.T) {
+	p := &Parser{
+		MetricName:     "csv",
+		HeaderRowCount: 1,
+		TagColumns:     []string{"host"},
+		DefaultTags:    map[string]string{"source": "file"},
+	}
+	require.NoError(t, p.Init())
+
+	metrics, err := p.Parse([]byte("host,load,up,state,ratio\nweb01,3,true,ok,0.5\nweb02,,false,,1\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	assert.Equal(t, "csv", metrics[0].Name())
+	assert.Equal(t, map[string]string{"host": "web01", "source": "file"}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{
+		"load":  int64(3),
+		"up":    true,
+		"state": "ok",
+		"ratio": float64(0.5),
+	}, metrics[0].Fields())
+	// empty values are skipped
+	assert.Equal(t, map[string]interface{}{
+		"up":    false,
+		"ratio": int64(1),
+	}, metrics[1].Fields())
+}
+
+func TestParseMultipleHeaderRows(t *testing.T) {
+	p := &Parser{
+		MetricName:     "csv",
+		HeaderRowCount: 2,
+		SkipRows:       1,
+		SkipColumns:    1,
+	}
+	require.NoError(t, p.Init())
+
+	metrics, err := p.Parse([]byte("generated by a tool\nid,mem_,mem_\n,used,free\n1,10,20\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, map[string]interface{}{
+		"mem_used": int64(10),
+		"mem_free": int64(20),
+	}, metrics[0].Fields())
+}
+
+func TestParseColumnNamesAndTypes(t *testing.T) {
+	p := &Parser{
+		MetricName:        "csv",
+		Delimiter:         ";",
+		Comment:           "#",
+		TrimSpace:         true,
+		ColumnNames:       []string{"name", "time", "value", "code"},
+		ColumnTypes:       []string{"", "", "float", "string"},
+		MeasurementColumn: "name",
+		TimestampColumn:   "time",
+		TimestampFormat:   "2006-01-02T15:04:05Z07:00",
+	}
+	require.NoError(t, p.Init())
+
+	metrics, err := p.Parse([]byte("# a comment\ncpu; 2018-10-05T10:00:00Z; 3; 007\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "cpu", metrics[0].Name())
+	assert.Equal(t, map[string]interface{}{
+		"value": float64(3),
+		"code":  "007",
+	}, metrics[0].Fields())
+	assert.Equal(t, time.Date(2018, 10, 5, 10, 0, 0, 0, time.UTC).UnixNano(),
+		metrics[0].UnixNano())
+}
+
+func TestParseInvalidType(t *testing.T) {
+	p := &Parser{
+		MetricName:  "csv",
+		ColumnNames: []string{"value"},
+		ColumnTypes: []string{"int"},
+	}
+	require.NoError(t, p.Init())
+
+	_, err := p.Parse([]byte("abc\n"))
+	assert.Error(t, err)
+}
+
+func TestParseMissingTimestamp(t *testing.T) {
+	p := &Parser{
+		MetricName:      "csv",
+		ColumnNames:     []string{"value", "time"},
+		TimestampColumn: "time",
+		TimestampFormat: "unix",
+	}
+	require.NoError(t, p.Init())
+
+	_, err := p.Parse([]byte("1\n"))
+	assert.Error(t, err)
+
+	metrics, err := p.Parse([]byte("1,1538733600\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, int64(1538733600), metrics[0].UnixNano()/1000000000)
+}
+
+func TestInit(t *testing.T) {
+	assert.Error(t, (&Parser{}).Init())
+	assert.Error(t, (&Parser{HeaderRowCount: 1, Delimiter: ";;"}).Init())
+	assert.Error(t, (&Parser{HeaderRowCount: 1, TimestampColumn: "time"}).Init())
+	assert.Error(t, (&Parser{HeaderRowCount: 1, ColumnTypes: []string{"date"}}).Init())
+}
+
+func TestParseLine(t *testing.T) {
+	p := &Parser{
+		MetricName:     "csv",
+		HeaderRowCount: 1,
+		SkipRows:       1,
+	}
+	require.NoError(t, p.Init())
+
+	m, err := p.ParseLine("skipped")
+	require.NoError(t, err)
+	assert.Nil(t, m)
+	m, err = p.ParseLine("a,b")
+	require.NoError(t, err)
+	assert.Nil(t, m)
+
+	m, err = p.ParseLine("1,2")
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	assert.Equal(t, map[string]interface{}{"a": int64(1), "b": int64(2)}, m.Fields())
+
+	// a repeated header, eg. of a rotated file, is skipped
+	m, err = p.ParseLine("a,b")
+	require.NoError(t, err)
+	assert.Nil(t, m)
+
+	m, err = p.ParseLine("")
+	require.NoError(t, err)
+	assert.Nil(t, m)
+}
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
 )
 
@@ -16,54 +18,134 @@ type JSONParser struct {
 	MetricName  string
 	TagKeys     []string
 	DefaultTags map[string]string
-}
 
-func (p *JSONParser) parseArray(buf []byte) ([]telegraf.Metric, error) {
-	metrics := make([]telegraf.Metric, 0)
+	// Query is the path of the object, or array of objects, to turn into
+	// metrics. The whole document is used when it is empty.
+	//
+	// A path is a list of object keys and array indices separated by dots,
+	// ie "data.servers.0.stats"; a dot within a key is escaped with a
+	// backslash. TagKeys, NameKey, TimeKey and FieldKeys are paths relative
+	// to every object selected by Query, a tag key naming a key of the object
+	// as is, dots and backslashes included, is not read as a path.
+	Query string
+	// NameKey is the path of the string used as the measurement name
+	// instead of MetricName.
+	NameKey string
+	// TimeKey is the path of the timestamp of the metric, parsed according to
+	// TimeFormat. The current time is used when it is empty.
+	TimeKey string
+	// TimeFormat is one of unix, unix_ms, unix_us, unix_ns or a Go time
+	// layout, ie "2006-01-02T15:04:05Z07:00".
+	TimeFormat string
+	// FieldKeys are the paths of the values flattened into fields. The whole
+	// object is flattened when it is empty.
+	FieldKeys []string
+	// StringFields are the names, or glob patterns, of the flattened fields
+	// whose string values are kept. Init must be called after setting it.
+	StringFields []string
 
-	var jsonOut []map[string]interface{}
-	err := json.Unmarshal(buf, &jsonOut)
+	stringFields filter.Filter
+}
+
+// Init checks the time format and compiles the string field patterns.
+func (p *JSONParser) Init() error {
+	if p.TimeKey != "" && p.TimeFormat == "" {
+		return fmt.Errorf("json_time_format is required with json_time_key")
+	}
+	f, err := filter.Compile(p.StringFields)
 	if err != nil {
-		err = fmt.Errorf("unable to parse out as JSON Array, %s", err)
-		return nil, err
+		return fmt.Errorf("invalid json_string_fields, %s", err)
 	}
-	for _, item := range jsonOut {
-		metrics, err = p.parseObject(metrics, item)
+	p.stringFields = f
+	return nil
+}
+
+func (p *JSONParser) parseArray(items []interface{}) ([]telegraf.Metric, error) {
+	metrics := make([]telegraf.Metric, 0)
+	now := time.Now().UTC()
+	for _, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected JSON object in array, got %T", item)
+		}
+		m, err := p.parseObject(object, now)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
 	}
 	return metrics, nil
 }
 
-func (p *JSONParser) parseObject(metrics []telegraf.Metric, jsonOut map[string]interface{}) ([]telegraf.Metric, error) {
-
+func (p *JSONParser) parseObject(
+	jsonOut map[string]interface{},
+	now time.Time,
+) (telegraf.Metric, error) {
 	tags := make(map[string]string)
 	for k, v := range p.DefaultTags {
 		tags[k] = v
 	}
 
 	for _, tag := range p.TagKeys {
-		switch v := jsonOut[tag].(type) {
+		// the keys of the object are looked up as is first, as tag_keys
+		// did not accept paths before
+		keys := []string{tag}
+		if _, ok := jsonOut[tag]; !ok {
+			keys = splitPath(tag)
+		}
+		switch v := remove(jsonOut, keys).(type) {
 		case string:
-			tags[tag] = v
+			tags[pathName(keys)] = v
 		case bool:
-			tags[tag] = strconv.FormatBool(v)
+			tags[pathName(keys)] = strconv.FormatBool(v)
 		case float64:
-			tags[tag] = strconv.FormatFloat(v, 'f', -1, 64)
+			tags[pathName(keys)] = strconv.FormatFloat(v, 'f', -1, 64)
 		}
-		delete(jsonOut, tag)
 	}
 
-	f := JSONFlattener{}
-	err := f.FlattenJSON("", jsonOut)
-	if err != nil {
-		return nil, err
+	name := p.MetricName
+	if p.NameKey != "" {
+		if v, ok := remove(jsonOut, splitPath(p.NameKey)).(string); ok && v != "" {
+			name = v
+		}
 	}
 
-	metric, err := metric.New(p.MetricName, tags, f.Fields, time.Now().UTC())
+	if p.TimeKey != "" {
+		v := remove(jsonOut, splitPath(p.TimeKey))
+		if v == nil {
+			return nil, fmt.Errorf("JSON time key %s not found", p.TimeKey)
+		}
+		t, err := internal.ParseTimestamp(v, p.TimeFormat)
+		if err != nil {
+			return nil, err
+		}
+		now = t
+	}
 
-	if err != nil {
-		return nil, err
+	f := JSONFlattener{Fields: make(map[string]interface{})}
+	if len(p.FieldKeys) == 0 {
+		if err := f.FullFlattenJSON("", jsonOut, true, false); err != nil {
+			return nil, err
+		}
+	}
+	for _, field := range p.FieldKeys {
+		keys := splitPath(field)
+		if v, ok := lookup(jsonOut, keys); ok {
+			err := f.FullFlattenJSON(pathName(keys), v, true, false)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
-	return append(metrics, metric), nil
+	for k, v := range f.Fields {
+		if _, ok := v.(string); ok {
+			if p.stringFields == nil || !p.stringFields.Match(k) {
+				delete(f.Fields, k)
+			}
+		}
+	}
+
+	return metric.New(name, tags, f.Fields, now)
 }
 
 func (p *JSONParser) Parse(buf []byte) ([]telegraf.Metric, error) {
@@ -72,17 +154,33 @@ func (p *JSONParser) Parse(buf []byte) ([]telegraf.Metric, error) {
 		return make([]telegraf.Metric, 0), nil
 	}
 
-	if !isarray(buf) {
-		metrics := make([]telegraf.Metric, 0)
-		var jsonOut map[string]interface{}
-		err := json.Unmarshal(buf, &jsonOut)
+	var jsonOut interface{}
+	err := json.Unmarshal(buf, &jsonOut)
+	if err != nil {
+		err = fmt.Errorf("unable to parse out as JSON, %s", err)
+		return nil, err
+	}
+
+	if p.Query != "" {
+		v, ok := lookup(jsonOut, splitPath(p.Query))
+		if !ok {
+			return nil, fmt.Errorf("JSON query %s did not match", p.Query)
+		}
+		jsonOut = v
+	}
+
+	switch v := jsonOut.(type) {
+	case map[string]interface{}:
+		m, err := p.parseObject(v, time.Now().UTC())
 		if err != nil {
-			err = fmt.Errorf("unable to parse out as JSON, %s", err)
 			return nil, err
 		}
-		return p.parseObject(metrics, jsonOut)
+		return []telegraf.Metric{m}, nil
+	case []interface{}:
+		return p.parseArray(v)
+	default:
+		return nil, fmt.Errorf("expected JSON object or array, got %T", jsonOut)
 	}
-	return p.parseArray(buf)
 }
 
 func (p *JSONParser) ParseLine(line string) (telegraf.Metric, error) {
@@ -166,13 +264,3 @@ func (f *JSONFlattener) FullFlattenJSON(
 	}
 	return nil
 }
-
-func isarray(buf []byte) bool {
-	ia := bytes.IndexByte(buf, '[')
-	ib := bytes.IndexByte(buf, '{')
-	if ia > -1 && ia < ib {
-		return true
-	} else {
-		return false
-	}
-}
@@ -2,8 +2,10 @@ package json
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -428,3 +430,150 @@ func TestParseArrayWithTagKeys(t *testing.T) {
 		"othertag": "baz",
 	}, metrics[1].Tags())
 }
+
+const validJSONNested = `
+{
+    "status": "ok",
+    "data": {
+        "servers": [
+            {
+                "name": "cpu",
+                "host": {"name": "web01", "dc": "east"},
+                "time": "2018-10-05T10:00:00Z",
+                "load": 0.5,
+                "state": "running",
+                "version": "1.2"
+            },
+            {
+                "name": "cpu",
+                "host": {"name": "web02", "dc": "east"},
+                "time": "2018-10-05T10:00:10Z",
+                "load": 1.5,
+                "state": "stopped",
+                "version": "1.3"
+            }
+        ]
+    }
+}
+`
+
+func TestParseJSONQuery(t *testing.T) {
+	parser := JSONParser{
+		MetricName:   "json_test",
+		TagKeys:      []string{"host.name"},
+		Query:        "data.servers",
+		NameKey:      "name",
+		TimeKey:      "time",
+		TimeFormat:   "2006-01-02T15:04:05Z07:00",
+		StringFields: []string{"stat*"},
+	}
+	assert.NoError(t, parser.Init())
+
+	metrics, err := parser.Parse([]byte(validJSONNested))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 2)
+	assert.Equal(t, "cpu", metrics[0].Name())
+	assert.Equal(t, map[string]interface{}{
+		"load":  float64(0.5),
+		"state": "running",
+	}, metrics[0].Fields())
+	assert.Equal(t, map[string]string{"host_name": "web01"}, metrics[0].Tags())
+	assert.Equal(t, time.Date(2018, 10, 5, 10, 0, 0, 0, time.UTC).UnixNano(),
+		metrics[0].Time().UnixNano())
+	assert.Equal(t, map[string]string{"host_name": "web02"}, metrics[1].Tags())
+	assert.Equal(t, time.Date(2018, 10, 5, 10, 0, 10, 0, time.UTC).UnixNano(),
+		metrics[1].Time().UnixNano())
+
+	// pick an object within an array
+	parser = JSONParser{
+		MetricName: "json_test",
+		Query:      "data.servers.1",
+		FieldKeys:  []string{"load", "host"},
+	}
+	metrics, err = parser.Parse([]byte(validJSONNested))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, "json_test", metrics[0].Name())
+	assert.Equal(t, map[string]interface{}{
+		"load": float64(1.5),
+	}, metrics[0].Fields())
+
+	parser = JSONParser{
+		MetricName: "json_test",
+		Query:      "data.missing",
+	}
+	_, err = parser.Parse([]byte(validJSONNested))
+	assert.Error(t, err)
+}
+
+func TestParseWithLiteralTagKeys(t *testing.T) {
+	// keys with dots and backslashes are tag keys as they were before
+	// tag_keys accepted paths
+	parser := JSONParser{
+		MetricName: "json_test",
+		TagKeys:    []string{"host.name", `dc\zone`, "rack.id"},
+	}
+	metrics, err := parser.Parse([]byte(`{"host.name": "web01", "dc\\zone": "a",` +
+		` "rack": {"id": "r1"}, "load": 0.5}`))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, map[string]string{
+		"host.name": "web01",
+		`dc\zone`:   "a",
+		"rack_id":   "r1",
+	}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{"load": float64(0.5)}, metrics[0].Fields())
+}
+
+func TestParseJSONStringFields(t *testing.T) {
+	parser := JSONParser{
+		MetricName:   "json_test",
+		StringFields: []string{"status"},
+		FieldKeys:    []string{"status"},
+	}
+	assert.NoError(t, parser.Init())
+	metrics, err := parser.Parse([]byte(validJSONNested))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, map[string]interface{}{"status": "ok"}, metrics[0].Fields())
+}
+
+func TestParseJSONUnixTime(t *testing.T) {
+	tests := []struct {
+		format string
+		json   string
+		time   time.Time
+	}{
+		{"unix", `{"ts": 1538733600, "a": 1}`, time.Unix(1538733600, 0)},
+		{"unix", `{"ts": 1538733600.5, "a": 1}`,
+			time.Unix(1538733600, 500000000)},
+		{"unix_ms", `{"ts": "1538733600123", "a": 1}`,
+			time.Unix(1538733600, 123000000)},
+		{"unix_us", `{"ts": 1538733600123456, "a": 1}`,
+			time.Unix(1538733600, 123456000)},
+		{"unix_ns", `{"ts": "1538733600123456789", "a": 1}`,
+			time.Unix(1538733600, 123456789)},
+	}
+	for _, tt := range tests {
+		parser := JSONParser{
+			MetricName: "json_test",
+			TimeKey:    "ts",
+			TimeFormat: tt.format,
+		}
+		metrics, err := parser.Parse([]byte(tt.json))
+		assert.NoError(t, err, tt.format)
+		assert.Len(t, metrics, 1)
+		assert.Equal(t, tt.time.UnixNano(), metrics[0].Time().UnixNano(), tt.format)
+		assert.Equal(t, map[string]interface{}{"a": float64(1)},
+			metrics[0].Fields(), tt.format)
+	}
+
+	parser := JSONParser{MetricName: "json_test", TimeKey: "ts"}
+	assert.Error(t, parser.Init())
+}
+
+func TestSplitPath(t *testing.T) {
+	assert.Equal(t, []string{"a"}, splitPath("a"))
+	assert.Equal(t, []string{"a", "0", "b"}, splitPath("a.0.b"))
+	assert.Equal(t, []string{"a.b", "c"}, splitPath(`a\.b.c`))
+}
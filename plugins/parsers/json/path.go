@@ -0,0 +1,74 @@
+package json
+
+import (
+	"strconv"
+	"strings"
+)
+
+// splitPath splits a path into its keys, a backslash escapes the next
+// character.
+func splitPath(path string) []string {
+	var keys []string
+	key := make([]byte, 0, len(path))
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '\\' && i+1 < len(path):
+			i++
+			key = append(key, path[i])
+		case c == '.':
+			keys = append(keys, string(key))
+			key = key[:0]
+		default:
+			key = append(key, c)
+		}
+	}
+	return append(keys, string(key))
+}
+
+// pathName returns the name of the tag or field of a path, which is named
+// like the flattened fields.
+func pathName(keys []string) string {
+	return strings.Join(keys, "_")
+}
+
+// lookup returns the value at the path of keys in v.
+func lookup(v interface{}, keys []string) (interface{}, bool) {
+	for _, key := range keys {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = t[key]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil, false
+			}
+			v = t[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// remove deletes the value at the path of keys from its object and returns
+// it. Values within arrays are returned but not deleted.
+func remove(object map[string]interface{}, keys []string) interface{} {
+	parent, ok := lookup(object, keys[:len(keys)-1])
+	if !ok {
+		return nil
+	}
+	key := keys[len(keys)-1]
+	switch t := parent.(type) {
+	case map[string]interface{}:
+		v := t[key]
+		delete(t, key)
+		return v
+	case []interface{}:
+		v, _ := lookup(t, []string{key})
+		return v
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package nmon
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+const (
+	fieldSep = ","
+
+	// layout of the time and date of the ZZZZ lines, ie 15:59:34 24-JAN-2018,
+	// joined by a space as a comma after the seconds is a fraction of second
+	zzzzLayout = "15:04:05 02-Jan-2006"
+)
+
+// defaultHeaders are the header lines of the AIX sections with fixed
+// columns. They are used when the data does not hold the header of a
+// section, like the incremental reports of the nmon reporters.
+var defaultHeaders = map[string]string{
+	"CPU":      "CPU,CPU,User%,Sys%,Wait%,Idle%",
+	"PCPU":     "PCPU,PCPU,User ,Sys ,Wait ,Idle",
+	"SCPU":     "SCPU,SCPU,User ,Sys ,Wait ,Idle",
+	"CPU_ALL":  "CPU_ALL,CPU Total,User%,Sys%,Wait%,Idle%,Busy,PhysicalCPUs",
+	"PCPU_ALL": "PCPU_ALL,PCPU Total,User  ,Sys  ,Wait  ,Idle  , Entitled Capacity",
+	"SCPU_ALL": "SCPU_ALL,SCPU Total,User  ,Sys  ,Wait  ,Idle",
+	"MEM":      "MEM,Memory,Real Free %,Virtual free %,Real free(MB),Virtual free(MB),Real total(MB),Virtual total(MB)",
+	"MEMNEW":   "MEMNEW,Memory New,Process%,FScache%,System%,Free%,Pinned%,User%",
+	"MEMUSE":   "MEMUSE,Memory Use,%numperm,%minperm,%maxperm,minfree,maxfree,%numclient,%maxclient, lruable pages",
+	"PAGE":     "PAGE,Paging,faults,pgin,pgout,pgsin,pgsout,reclaims,scans,cycles",
+	"PROC":     "PROC,Processes,Runnable,Swap-in,pswitch,syscall,read,write,fork,exec,sem,msg,asleep_bufio,asleep_rawio,asleep_diocio",
+	"FILE":     "FILE,File I/O,iget,namei,dirblk,readch,writech,ttyrawch,ttycanch,ttyoutch",
+	"LPAR":     "LPAR,Logical Partition,PhysicalCPU,virtualCPUs,logicalCPUs,poolCPUs,entitled,weight,PoolIdle,usedAllCPU%,usedPoolCPU%,SharedCPU,Capped,EC_User%,EC_Sys%,EC_Wait%,EC_Idle%,VP_User%,VP_Sys%,VP_Wait%,VP_Idle%,Folded,Pool_id",
+	"POOLS":    "POOLS,Multiple CPU Pools,shcpus_in_sys,max_pool_capacity,entitled_pool_capacity,pool_max_time,pool_busy_time,shcpu_tot_time,shcpu_busy_time,Pool_id,entitled",
+	"TOP":      "TOP,+PID,Time,%CPU,%Usr,%Sys,Threads,Size,ResText,ResData,CharIO,%RAM,Paging,Command,WLMclass",
+}
+
+// Parser parses the output of nmon, the AIX topas_nmon and the Linux nmon
+// recordings as well as the incremental reports of the nmon reporters.
+//
+// Every data line of a section becomes a metric named nmon_<section>, with
+// a field per column of the header line of the section and the time of the
+// preceding ZZZZ line. The header lines, AAA lines and ZZZZ lines are kept
+// while parsing, the following data lines depend on them.
+type Parser struct {
+	// Location is the time zone of the ZZZZ lines, the local time zone by
+	// default.
+	Location    *time.Location
+	DefaultTags map[string]string
+
+	// state of ParseLine, which gets the lines one at a time
+	mu    sync.Mutex
+	state *state
+}
+
+// state is what is known of the data parsed so far.
+type state struct {
+	headers   map[string][]string
+	tags      map[string]string // from the AAA lines
+	timestamp time.Time         // of the last ZZZZ line
+}
+
+func newState() *state {
+	return &state{
+		headers: make(map[string][]string),
+		tags:    make(map[string]string),
+	}
+}
+
+// Parse parses a whole nmon file or report.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	s := newState()
+	metrics := make([]telegraf.Metric, 0)
+
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(buf)+1)
+	for scanner.Scan() {
+		m, err := p.parseLine(s, scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// ParseLine parses a single line, using the header, AAA and ZZZZ lines of
+// the previous calls. It returns no metric for those lines.
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.state == nil {
+		p.state = newState()
+	}
+	return p.parseLine(p.state, line)
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+func (p *Parser) parseLine(s *state, line string) (telegraf.Metric, error) {
+	line = strings.TrimRight(line, "\r")
+	if strings.TrimSpace(line) == "" {
+		return nil, nil
+	}
+
+	fields := strings.Split(line, fieldSep)
+	key := fields[0]
+	switch {
+	case key == "AAA":
+		s.parseAAA(fields)
+		return nil, nil
+	case key == "ZZZZ":
+		t, err := p.parseZZZZ(fields)
+		if err != nil {
+			return nil, err
+		}
+		s.timestamp = t
+		return nil, nil
+	case strings.HasPrefix(key, "BBB"):
+		// configuration of the system
+		return nil, nil
+	case len(fields) < 3:
+		// ie the TOP,%CPU Utilisation line
+		return nil, nil
+	}
+
+	// TOP lines have the PID before the snapshot
+	object, start := key, 2
+	if key == "TOP" {
+		object, start = fields[1], 3
+	}
+	if !isSnapshot(fields[start-1]) {
+		s.headers[key] = fields
+		return nil, nil
+	}
+
+	header := s.header(key)
+	if header == nil {
+		// section of an unknown layout
+		return nil, nil
+	}
+
+	tags := make(map[string]string)
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	for k, v := range s.tags {
+		tags[k] = v
+	}
+	tags["object"] = object
+
+	values := make(map[string]interface{})
+	for i := start; i < len(fields) && i < len(header); i++ {
+		name := fieldName(header[i])
+		value := strings.TrimSpace(fields[i])
+		if name == "" || value == "" {
+			continue
+		}
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			values[name] = v
+		} else {
+			// ie the command of TOP
+			tags[name] = value
+		}
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	t := s.timestamp
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return metric.New("nmon_"+section(key), tags, values, t, telegraf.Gauge)
+}
+
+// parseAAA keeps the description of the system as tags.
+//
+//   AAA,host,rcvioc03
+//   AAA,SerialNumber,06B86A1
+//   AAA,MachineType,IBM,9117-MMA
+//   AAA,LPARNumberName,3,rc_06B86A1_VIOC3
+func (s *state) parseAAA(fields []string) {
+	if len(fields) < 3 {
+		return
+	}
+	switch fields[1] {
+	case "host":
+		s.tags["host"] = fields[2]
+	case "SerialNumber":
+		s.tags["serial_number"] = fields[2]
+	case "MachineType":
+		s.tags["machine_type"] = fields[len(fields)-1]
+	case "LPARNumberName":
+		if len(fields) > 3 {
+			s.tags["lpar_id"] = fields[2]
+			s.tags["lpar"] = fields[3]
+		}
+	}
+}
+
+// parseZZZZ returns the time of a snapshot.
+//
+//   ZZZZ,T0955,15:59:34,24-JAN-2018
+func (p *Parser) parseZZZZ(fields []string) (time.Time, error) {
+	if len(fields) < 4 {
+		return time.Time{}, fmt.Errorf("ZZZZ line format wrong: %s", strings.Join(fields, fieldSep))
+	}
+	loc := p.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	return time.ParseInLocation(zzzzLayout, fields[2]+" "+fields[3], loc)
+}
+
+// header returns the header line of a section: its own, the one of its
+// family for the numbered sections, ie CPU01, or the default one.
+func (s *state) header(key string) []string {
+	if header, ok := s.headers[key]; ok {
+		return header
+	}
+	if header, ok := s.headers[section(key)]; ok {
+		return header
+	}
+	if header, ok := defaultHeaders[section(key)]; ok {
+		return strings.Split(header, fieldSep)
+	}
+	return nil
+}
+
+// isSnapshot returns true for the snapshot id of the data lines, ie T0955.
+func isSnapshot(s string) bool {
+	if len(s) < 2 || s[0] != 'T' {
+		return false
+	}
+	for _, c := range s[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// section returns the section of a key without its number, ie CPU for
+// CPU01 or DISKBUSY for DISKBUSY1.
+func section(key string) string {
+	return strings.TrimRight(key, "0123456789")
+}
+
+// fieldName returns the field of a header column, ie user for User% or
+// real_free(mb) for Real free(MB).
+func fieldName(column string) string {
+	name := strings.ToLower(strings.Replace(column, "%", "", -1))
+	return strings.Join(strings.Fields(name), "_")
+}
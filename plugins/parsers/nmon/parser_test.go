@@ -0,0 +1,180 @@
+package nmon
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+)
+
+const aixNmon = `AAA,progname,topas_nmon
+AAA,host,rcvioc03
+AAA,SerialNumber,06B86A1
+AAA,MachineType,IBM,9117-MMA
+AAA,LPARNumberName,3,rc_06B86A1_VIOC3
+BBBN,000,NetworkName,MTU,Mbits,Name
+BBBN,001,en0,1500,10240,Standard Ethernet Network Interface
+CPU01,CPU 1 rcvioc03,User%,Sys%,Wait%,Idle%
+CPU_ALL,CPU Total rcvioc03,User%,Sys%,Wait%,Idle%,Busy,PhysicalCPUs
+NET,Network I/O rcvioc03,en0-read-KB/s,en0-write-KB/s
+DISKBUSY,Disk %Busy rcvioc03,hdisk0,hdisk1
+JFSFILE,JFS Filespace %Used rcvioc03,/,/home
+TOP,%CPU Utilisation
+TOP,+PID,Time,%CPU,%Usr,%Sys,Threads,Size,ResText,ResData,CharIO,%RAM,Paging,Command,WLMclass
+ZZZZ,T0001,15:59:34,24-JAN-2018
+CPU01,T0001,2.1,2.1,0.0,95.7
+CPU_ALL,T0001,2.9,1.5,0.3,95.3,,4
+NET,T0001,0.2,0.1
+DISKBUSY,T0001,1.3,0.0
+JFSFILE,T0001,29.8,6.0
+TOP,8519710,T0001,0.17,0.11,0.06,77,32344,3440,27888,2558,1,54,kuxagent,Unclassified
+ZZZZ,T0002,16:00:34,24-JAN-2018
+CPU_ALL,T0002,3.0,1.5,0.3,95.2,,4
+`
+
+func metricsByName(metrics []telegraf.Metric) map[string][]telegraf.Metric {
+	byName := make(map[string][]telegraf.Metric)
+	for _, m := range metrics {
+		byName[m.Name()] = append(byName[m.Name()], m)
+	}
+	return byName
+}
+
+func TestParseAIX(t *testing.T) {
+	p := &Parser{Location: time.UTC}
+	metrics, err := p.Parse([]byte(aixNmon))
+	require.NoError(t, err)
+	require.Len(t, metrics, 7)
+	byName := metricsByName(metrics)
+
+	system := map[string]string{
+		"host":          "rcvioc03",
+		"serial_number": "06B86A1",
+		"machine_type":  "9117-MMA",
+		"lpar":          "rc_06B86A1_VIOC3",
+		"lpar_id":       "3",
+	}
+	withObject := func(object string) map[string]string {
+		tags := map[string]string{"object": object}
+		for k, v := range system {
+			tags[k] = v
+		}
+		return tags
+	}
+
+	cpu := byName["nmon_CPU"][0]
+	assert.Equal(t, withObject("CPU01"), cpu.Tags())
+	assert.Equal(t, map[string]interface{}{
+		"user": 2.1, "sys": 2.1, "wait": 0.0, "idle": 95.7,
+	}, cpu.Fields())
+	assert.Equal(t, telegraf.Gauge, cpu.Type())
+
+	require.Len(t, byName["nmon_CPU_ALL"], 2)
+	all := byName["nmon_CPU_ALL"]
+	assert.Equal(t, map[string]interface{}{
+		"user": 2.9, "sys": 1.5, "wait": 0.3, "idle": 95.3, "physicalcpus": 4.0,
+	}, all[0].Fields())
+	assert.Equal(t, time.Date(2018, 1, 24, 15, 59, 34, 0, time.UTC).UnixNano(),
+		all[0].UnixNano())
+	assert.Equal(t, time.Date(2018, 1, 24, 16, 0, 34, 0, time.UTC).UnixNano(),
+		all[1].UnixNano())
+
+	assert.Equal(t, map[string]interface{}{
+		"en0-read-kb/s": 0.2, "en0-write-kb/s": 0.1,
+	}, byName["nmon_NET"][0].Fields())
+	assert.Equal(t, map[string]interface{}{
+		"hdisk0": 1.3, "hdisk1": 0.0,
+	}, byName["nmon_DISKBUSY"][0].Fields())
+	assert.Equal(t, map[string]interface{}{
+		"/": 29.8, "/home": 6.0,
+	}, byName["nmon_JFSFILE"][0].Fields())
+
+	top := byName["nmon_TOP"][0]
+	assert.Equal(t, "8519710", top.Tags()["object"])
+	assert.Equal(t, "kuxagent", top.Tags()["command"])
+	assert.Equal(t, "Unclassified", top.Tags()["wlmclass"])
+	assert.Equal(t, 0.17, top.Fields()["cpu"])
+	assert.Equal(t, 32344.0, top.Fields()["size"])
+}
+
+func TestParseLinux(t *testing.T) {
+	data := `AAA,host,web01
+CPU001,CPU 1 web01,User%,Sys%,Wait%,Idle%
+MEM,Memory MB web01,memtotal,hightotal,lowtotal,swaptotal,memfree
+DISKBUSY1,Disk %Busy web01,sda,sdb
+ZZZZ,T0001,10:00:00,01-JAN-2018
+CPU001,T0001,10.0,2.0,0.0,88.0
+MEM,T0001,7822.0,0.0,7822.0,2048.0,512.5
+DISKBUSY1,T0001,5.0,0.5
+`
+	p := &Parser{Location: time.UTC}
+	metrics, err := p.Parse([]byte(data))
+	require.NoError(t, err)
+	byName := metricsByName(metrics)
+
+	require.Len(t, byName["nmon_CPU"], 1)
+	assert.Equal(t, "CPU001", byName["nmon_CPU"][0].Tags()["object"])
+	assert.Equal(t, 10.0, byName["nmon_CPU"][0].Fields()["user"])
+	assert.Equal(t, 512.5, byName["nmon_MEM"][0].Fields()["memfree"])
+	require.Len(t, byName["nmon_DISKBUSY"], 1)
+	assert.Equal(t, "DISKBUSY1", byName["nmon_DISKBUSY"][0].Tags()["object"])
+	assert.Equal(t, "web01", byName["nmon_DISKBUSY"][0].Tags()["host"])
+}
+
+// the incremental reports only hold the header lines of the sections with
+// a column per device
+func TestParseDefaultHeaders(t *testing.T) {
+	data := `ZZZZ,T0001,10:00:00,01-JAN-2018
+CPU_ALL,T0001,10.1,2.0,0.5,87.4,,4
+MEMNEW,T0001,19.7,55.9,23.0,1.4,23.5,72.1
+NET,T0001,0.2,0.1
+`
+	p := &Parser{DefaultTags: map[string]string{"dc": "eu"}}
+	metrics, err := p.Parse([]byte(data))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	assert.Equal(t, map[string]string{"dc": "eu", "object": "CPU_ALL"}, metrics[0].Tags())
+	assert.Equal(t, 10.1, metrics[0].Fields()["user"])
+	assert.Equal(t, 72.1, metrics[1].Fields()["user"])
+	assert.Equal(t, time.Date(2018, 1, 1, 10, 0, 0, 0, time.Local).UnixNano(),
+		metrics[0].UnixNano())
+}
+
+func TestParseLine(t *testing.T) {
+	p := &Parser{Location: time.UTC}
+	var metrics []telegraf.Metric
+	for _, line := range strings.Split(aixNmon, "\n") {
+		m, err := p.ParseLine(line)
+		require.NoError(t, err)
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	expected, err := (&Parser{Location: time.UTC}).Parse([]byte(aixNmon))
+	require.NoError(t, err)
+	require.Len(t, metrics, len(expected))
+	for i := range expected {
+		assert.Equal(t, expected[i].Name(), metrics[i].Name())
+		assert.Equal(t, expected[i].Tags(), metrics[i].Tags())
+		assert.Equal(t, expected[i].Fields(), metrics[i].Fields())
+		assert.Equal(t, expected[i].UnixNano(), metrics[i].UnixNano())
+	}
+}
+
+func TestParseInvalidZZZZ(t *testing.T) {
+	p := &Parser{}
+	_, err := p.Parse([]byte("ZZZZ,T0001,10:00:00,01-XXX-2018\n"))
+	assert.Error(t, err)
+}
+
+func TestFieldName(t *testing.T) {
+	assert.Equal(t, "user", fieldName("User%"))
+	assert.Equal(t, "real_free", fieldName("Real Free %"))
+	assert.Equal(t, "real_free(mb)", fieldName("Real free(MB)"))
+	assert.Equal(t, "lruable_pages", fieldName(" lruable pages"))
+	assert.Equal(t, "numperm", fieldName("%numperm"))
+}
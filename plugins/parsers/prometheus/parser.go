@@ -21,8 +21,48 @@ import (
 	"github.com/prometheus/common/expfmt"
 )
 
+// Parser parses the Prometheus text exposition format.
+type Parser struct {
+	DefaultTags map[string]string
+}
+
+// Parse returns the metrics of the text exposition format in buf. The
+// default tags are added to the metrics which do not have such a label.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	metrics, err := Parse(buf, http.Header{})
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range metrics {
+		for k, v := range p.DefaultTags {
+			if !m.HasTag(k) {
+				m.AddTag(k, v)
+			}
+		}
+	}
+	return metrics, nil
+}
+
+// ParseLine parses a single sample.
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line + "\n"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, fmt.Errorf("Can not parse the line: %s, for data format: prometheus", line)
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
 // Parse returns a slice of Metrics from a text representation of a
-// metrics
+// metrics, or from delimited protocol buffers when the header tells so
 func Parse(buf []byte, header http.Header) ([]telegraf.Metric, error) {
 	var metrics []telegraf.Metric
 	var parser expfmt.TextParser
@@ -66,13 +106,13 @@ func Parse(buf []byte, header http.Header) ([]telegraf.Metric, error) {
 			if mf.GetType() == dto.MetricType_SUMMARY {
 				// summary metric
 				fields = makeQuantiles(m)
-				fields["count"] = float64(m.GetSummary().GetSampleCount())
-				fields["sum"] = float64(m.GetSummary().GetSampleSum())
+				fields[metric.CountField] = float64(m.GetSummary().GetSampleCount())
+				fields[metric.SumField] = float64(m.GetSummary().GetSampleSum())
 			} else if mf.GetType() == dto.MetricType_HISTOGRAM {
 				// histogram metric
 				fields = makeBuckets(m)
-				fields["count"] = float64(m.GetHistogram().GetSampleCount())
-				fields["sum"] = float64(m.GetHistogram().GetSampleSum())
+				fields[metric.CountField] = float64(m.GetHistogram().GetSampleCount())
+				fields[metric.SumField] = float64(m.GetHistogram().GetSampleSum())
 
 			} else {
 				// standard metric
@@ -117,7 +157,7 @@ func makeQuantiles(m *dto.Metric) map[string]interface{} {
 	fields := make(map[string]interface{})
 	for _, q := range m.GetSummary().Quantile {
 		if !math.IsNaN(q.GetValue()) {
-			fields[fmt.Sprint(q.GetQuantile())] = float64(q.GetValue())
+			fields[metric.BoundField(q.GetQuantile())] = float64(q.GetValue())
 		}
 	}
 	return fields
@@ -127,7 +167,7 @@ func makeQuantiles(m *dto.Metric) map[string]interface{} {
 func makeBuckets(m *dto.Metric) map[string]interface{} {
 	fields := make(map[string]interface{})
 	for _, b := range m.GetHistogram().Bucket {
-		fields[fmt.Sprint(b.GetUpperBound())] = float64(b.GetCumulativeCount())
+		fields[metric.BoundField(b.GetUpperBound())] = float64(b.GetCumulativeCount())
 	}
 	return fields
 }
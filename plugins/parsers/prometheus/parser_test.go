@@ -163,3 +163,29 @@ func TestParseValidPrometheus(t *testing.T) {
 		metrics[0].Tags())
 
 }
+
+func TestParserDefaultTags(t *testing.T) {
+	parser := Parser{
+		DefaultTags: map[string]string{"host": "web01", "handler": "default"},
+	}
+	metrics, err := parser.Parse([]byte(validUniqueSummary))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, map[string]string{
+		"handler": "prometheus",
+		"host":    "web01",
+	}, metrics[0].Tags())
+
+	m, err := parser.ParseLine(`get_token_fail_count{source="cron"} 3`)
+	assert.NoError(t, err)
+	assert.Equal(t, "get_token_fail_count", m.Name())
+	assert.Equal(t, map[string]interface{}{"value": float64(3)}, m.Fields())
+	assert.Equal(t, map[string]string{
+		"source":  "cron",
+		"handler": "default",
+		"host":    "web01",
+	}, m.Tags())
+
+	_, err = parser.ParseLine("not prometheus")
+	assert.Error(t, err)
+}
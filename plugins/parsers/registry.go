@@ -2,14 +2,18 @@ package parsers
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/influxdata/telegraf"
 
 	"github.com/influxdata/telegraf/plugins/parsers/collectd"
+	"github.com/influxdata/telegraf/plugins/parsers/csv"
 	"github.com/influxdata/telegraf/plugins/parsers/graphite"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
 	"github.com/influxdata/telegraf/plugins/parsers/nagios"
+	"github.com/influxdata/telegraf/plugins/parsers/nmon"
+	"github.com/influxdata/telegraf/plugins/parsers/prometheus"
 	"github.com/influxdata/telegraf/plugins/parsers/value"
 )
 
@@ -41,7 +45,8 @@ type Parser interface {
 // Config is a struct that covers the data types needed for all parser types,
 // and can be used to instantiate _any_ of the parsers.
 type Config struct {
-	// Dataformat can be one of: json, influx, graphite, value, nagios
+	// Dataformat can be one of: json, influx, graphite, value, nagios,
+	// collectd, prometheus, csv, nmon
 	DataFormat string
 
 	// Separator only applied to Graphite data.
@@ -51,6 +56,17 @@ type Config struct {
 
 	// TagKeys only apply to JSON data
 	TagKeys []string
+	// JSONQuery is the path of the object or array of objects to parse
+	JSONQuery string
+	// JSONNameKey is the path of the measurement name
+	JSONNameKey string
+	// JSONTimeKey is the path of the timestamp, parsed with JSONTimeFormat
+	JSONTimeKey    string
+	JSONTimeFormat string
+	// JSONFieldKeys are the paths of the values turned into fields
+	JSONFieldKeys []string
+	// JSONStringFields are the fields whose string values are kept
+	JSONStringFields []string
 	// MetricName applies to JSON & value. This will be the name of the measurement.
 	MetricName string
 
@@ -61,6 +77,23 @@ type Config struct {
 	// Dataset specification for collectd
 	CollectdTypesDB []string
 
+	// CSV configuration, see csv.Parser
+	CSVHeaderRowCount    int
+	CSVSkipRows          int
+	CSVSkipColumns       int
+	CSVDelimiter         string
+	CSVComment           string
+	CSVTrimSpace         bool
+	CSVColumnNames       []string
+	CSVColumnTypes       []string
+	CSVTagColumns        []string
+	CSVMeasurementColumn string
+	CSVTimestampColumn   string
+	CSVTimestampFormat   string
+
+	// NmonTimezone is the time zone of the nmon snapshots, ie Asia/Shanghai
+	NmonTimezone string
+
 	// DataType only applies to value, this will be the type to parse value to
 	DataType string
 
@@ -74,8 +107,7 @@ func NewParser(config *Config) (Parser, error) {
 	var parser Parser
 	switch config.DataFormat {
 	case "json":
-		parser, err = NewJSONParser(config.MetricName,
-			config.TagKeys, config.DefaultTags)
+		parser, err = newJSONParser(config)
 	case "value":
 		parser, err = NewValueParser(config.MetricName,
 			config.DataType, config.DefaultTags)
@@ -89,6 +121,12 @@ func NewParser(config *Config) (Parser, error) {
 	case "collectd":
 		parser, err = NewCollectdParser(config.CollectdAuthFile,
 			config.CollectdSecurityLevel, config.CollectdTypesDB)
+	case "prometheus":
+		parser, err = NewPrometheusParser(config.DefaultTags)
+	case "csv":
+		parser, err = newCSVParser(config)
+	case "nmon":
+		parser, err = newNmonParser(config)
 	default:
 		err = fmt.Errorf("Invalid data format: %s", config.DataFormat)
 	}
@@ -108,6 +146,24 @@ func NewJSONParser(
 	return parser, nil
 }
 
+func newJSONParser(config *Config) (Parser, error) {
+	parser := &json.JSONParser{
+		MetricName:   config.MetricName,
+		TagKeys:      config.TagKeys,
+		DefaultTags:  config.DefaultTags,
+		Query:        config.JSONQuery,
+		NameKey:      config.JSONNameKey,
+		TimeKey:      config.JSONTimeKey,
+		TimeFormat:   config.JSONTimeFormat,
+		FieldKeys:    config.JSONFieldKeys,
+		StringFields: config.JSONStringFields,
+	}
+	if err := parser.Init(); err != nil {
+		return nil, err
+	}
+	return parser, nil
+}
+
 func NewNagiosParser() (Parser, error) {
 	return &nagios.NagiosParser{}, nil
 }
@@ -143,3 +199,44 @@ func NewCollectdParser(
 ) (Parser, error) {
 	return collectd.NewCollectdParser(authFile, securityLevel, typesDB)
 }
+
+func NewPrometheusParser(defaultTags map[string]string) (Parser, error) {
+	return &prometheus.Parser{DefaultTags: defaultTags}, nil
+}
+
+func newCSVParser(config *Config) (Parser, error) {
+	parser := &csv.Parser{
+		MetricName:        config.MetricName,
+		HeaderRowCount:    config.CSVHeaderRowCount,
+		SkipRows:          config.CSVSkipRows,
+		SkipColumns:       config.CSVSkipColumns,
+		Delimiter:         config.CSVDelimiter,
+		Comment:           config.CSVComment,
+		TrimSpace:         config.CSVTrimSpace,
+		ColumnNames:       config.CSVColumnNames,
+		ColumnTypes:       config.CSVColumnTypes,
+		TagColumns:        config.CSVTagColumns,
+		MeasurementColumn: config.CSVMeasurementColumn,
+		TimestampColumn:   config.CSVTimestampColumn,
+		TimestampFormat:   config.CSVTimestampFormat,
+		DefaultTags:       config.DefaultTags,
+	}
+	if err := parser.Init(); err != nil {
+		return nil, err
+	}
+	return parser, nil
+}
+
+func newNmonParser(config *Config) (Parser, error) {
+	parser := &nmon.Parser{
+		DefaultTags: config.DefaultTags,
+	}
+	if config.NmonTimezone != "" {
+		loc, err := time.LoadLocation(config.NmonTimezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid nmon_timezone %s, %s", config.NmonTimezone, err)
+		}
+		parser.Location = loc
+	}
+	return parser, nil
+}
@@ -1,5 +1,10 @@
 package all
 
 import (
+	_ "github.com/influxdata/telegraf/plugins/processors/converter"
+	_ "github.com/influxdata/telegraf/plugins/processors/enum"
+	_ "github.com/influxdata/telegraf/plugins/processors/override"
 	_ "github.com/influxdata/telegraf/plugins/processors/printer"
+	_ "github.com/influxdata/telegraf/plugins/processors/regex"
+	_ "github.com/influxdata/telegraf/plugins/processors/rename"
 )
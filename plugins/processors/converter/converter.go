@@ -0,0 +1,344 @@
+package converter
+
+import (
+	"log"
+	"math"
+	"strconv"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+var sampleConfig = `
+  ## Tags to convert
+  ##
+  ## The table key determines the target type, and the array of key-values
+  ## select the keys to convert.  The array may contain globs.
+  ##   <target-type> = [<tag-key>...]
+  [processors.converter.tags]
+    string = []
+    integer = []
+    boolean = []
+    float = []
+
+  ## Fields to convert
+  ##
+  ## The table key determines the target type, and the array of key-values
+  ## select the keys to convert.  The array may contain globs.
+  ##   <target-type> = [<field-key>...]
+  [processors.converter.fields]
+    tag = []
+    string = []
+    integer = []
+    boolean = []
+    float = []
+`
+
+type Conversion struct {
+	Tag     []string `toml:"tag"`
+	String  []string `toml:"string"`
+	Integer []string `toml:"integer"`
+	Boolean []string `toml:"boolean"`
+	Float   []string `toml:"float"`
+}
+
+type Converter struct {
+	Tags   *Conversion `toml:"tags"`
+	Fields *Conversion `toml:"fields"`
+
+	initialized      bool
+	tagConversions   *ConversionFilter
+	fieldConversions *ConversionFilter
+}
+
+type ConversionFilter struct {
+	Tag     filter.Filter
+	String  filter.Filter
+	Integer filter.Filter
+	Boolean filter.Filter
+	Float   filter.Filter
+}
+
+func (p *Converter) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Converter) Description() string {
+	return "Convert values to another metric value type"
+}
+
+func (p *Converter) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
+	if !p.initialized {
+		err := p.compile()
+		if err != nil {
+			log.Printf("E! [processors.converter] could not compile filters: %s", err)
+			return metrics
+		}
+	}
+
+	out := make([]telegraf.Metric, 0, len(metrics))
+	for _, m := range metrics {
+		out = append(out, p.convert(m))
+	}
+	return out
+}
+
+func (p *Converter) compile() error {
+	tf, err := compileFilter(p.Tags)
+	if err != nil {
+		return err
+	}
+
+	ff, err := compileFilter(p.Fields)
+	if err != nil {
+		return err
+	}
+
+	p.tagConversions = tf
+	p.fieldConversions = ff
+	p.initialized = true
+	return nil
+}
+
+func compileFilter(conv *Conversion) (*ConversionFilter, error) {
+	if conv == nil {
+		return nil, nil
+	}
+
+	var err error
+	cf := &ConversionFilter{}
+	cf.Tag, err = filter.Compile(conv.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	cf.String, err = filter.Compile(conv.String)
+	if err != nil {
+		return nil, err
+	}
+
+	cf.Integer, err = filter.Compile(conv.Integer)
+	if err != nil {
+		return nil, err
+	}
+
+	cf.Boolean, err = filter.Compile(conv.Boolean)
+	if err != nil {
+		return nil, err
+	}
+
+	cf.Float, err = filter.Compile(conv.Float)
+	if err != nil {
+		return nil, err
+	}
+
+	return cf, nil
+}
+
+func match(f filter.Filter, key string) bool {
+	return f != nil && f.Match(key)
+}
+
+// convert returns a new metric with the converted tags and fields, or the
+// metric itself if nothing was converted.
+func (p *Converter) convert(m telegraf.Metric) telegraf.Metric {
+	tags := m.Tags()
+	fields := m.Fields()
+
+	changed := false
+	if p.tagConversions != nil {
+		changed = p.convertTags(tags, fields) || changed
+	}
+	if p.fieldConversions != nil {
+		changed = p.convertFields(tags, fields) || changed
+	}
+	if !changed {
+		return m
+	}
+
+	converted, err := metric.New(m.Name(), tags, fields, m.Time(), m.Type())
+	if err != nil {
+		log.Printf("E! [processors.converter] could not convert metric %s: %s", m.Name(), err)
+		return m
+	}
+	converted.SetAggregate(m.IsAggregate())
+	return converted
+}
+
+// convertTags moves the selected tags to fields of the target type.
+func (p *Converter) convertTags(tags map[string]string, fields map[string]interface{}) bool {
+	changed := false
+	for key, value := range tags {
+		switch {
+		case match(p.tagConversions.String, key):
+			fields[key] = value
+		case match(p.tagConversions.Integer, key):
+			v, ok := toInteger(value)
+			if !ok {
+				logPrintf("Unable to convert tag '%s' to integer", key)
+				continue
+			}
+			fields[key] = v
+		case match(p.tagConversions.Boolean, key):
+			v, ok := toBool(value)
+			if !ok {
+				logPrintf("Unable to convert tag '%s' to boolean", key)
+				continue
+			}
+			fields[key] = v
+		case match(p.tagConversions.Float, key):
+			v, ok := toFloat(value)
+			if !ok {
+				logPrintf("Unable to convert tag '%s' to float", key)
+				continue
+			}
+			fields[key] = v
+		default:
+			continue
+		}
+		delete(tags, key)
+		changed = true
+	}
+	return changed
+}
+
+// convertFields changes the type of the selected fields, or moves them to
+// tags.
+func (p *Converter) convertFields(tags map[string]string, fields map[string]interface{}) bool {
+	changed := false
+	for key, value := range fields {
+		switch {
+		case match(p.fieldConversions.Tag, key):
+			v, ok := toString(value)
+			if !ok {
+				logPrintf("Unable to convert field '%s' to tag", key)
+				continue
+			}
+			delete(fields, key)
+			tags[key] = v
+		case match(p.fieldConversions.Float, key):
+			v, ok := toFloat(value)
+			if !ok {
+				logPrintf("Unable to convert field '%s' to float", key)
+				continue
+			}
+			fields[key] = v
+		case match(p.fieldConversions.Integer, key):
+			v, ok := toInteger(value)
+			if !ok {
+				logPrintf("Unable to convert field '%s' to integer", key)
+				continue
+			}
+			fields[key] = v
+		case match(p.fieldConversions.Boolean, key):
+			v, ok := toBool(value)
+			if !ok {
+				logPrintf("Unable to convert field '%s' to bool", key)
+				continue
+			}
+			fields[key] = v
+		case match(p.fieldConversions.String, key):
+			v, ok := toString(value)
+			if !ok {
+				logPrintf("Unable to convert field '%s' to string", key)
+				continue
+			}
+			fields[key] = v
+		default:
+			continue
+		}
+		changed = true
+	}
+	return changed
+}
+
+func toBool(v interface{}) (bool, bool) {
+	switch value := v.(type) {
+	case int64:
+		return value != 0, true
+	case float64:
+		return value != 0, true
+	case bool:
+		return value, true
+	case string:
+		result, err := strconv.ParseBool(value)
+		return result, err == nil
+	}
+	return false, false
+}
+
+func toInteger(v interface{}) (int64, bool) {
+	switch value := v.(type) {
+	case int64:
+		return value, true
+	case float64:
+		if value < float64(math.MinInt64) {
+			return math.MinInt64, true
+		} else if value > float64(math.MaxInt64) {
+			return math.MaxInt64, true
+		} else {
+			return int64(value), true
+		}
+	case bool:
+		if value {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		result, err := strconv.ParseInt(value, 0, 64)
+		if err != nil {
+			// accept float strings such as "42.0"
+			f, ferr := strconv.ParseFloat(value, 64)
+			if ferr != nil {
+				return 0, false
+			}
+			return toInteger(f)
+		}
+		return result, true
+	}
+	return 0, false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch value := v.(type) {
+	case int64:
+		return float64(value), true
+	case float64:
+		return value, true
+	case bool:
+		if value {
+			return 1.0, true
+		}
+		return 0.0, true
+	case string:
+		result, err := strconv.ParseFloat(value, 64)
+		return result, err == nil
+	}
+	return 0.0, false
+}
+
+func toString(v interface{}) (string, bool) {
+	switch value := v.(type) {
+	case int64:
+		return strconv.FormatInt(value, 10), true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(value), true
+	case string:
+		return value, true
+	}
+	return "", false
+}
+
+func logPrintf(format string, v ...interface{}) {
+	log.Printf("D! [processors.converter] "+format, v...)
+}
+
+func init() {
+	processors.Add("converter", func() telegraf.Processor {
+		return &Converter{}
+	})
+}
@@ -0,0 +1,253 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Metric(v telegraf.Metric, err error) telegraf.Metric {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func TestConverter(t *testing.T) {
+	tests := []struct {
+		name      string
+		converter *Converter
+		input     telegraf.Metric
+		expected  telegraf.Metric
+	}{
+		{
+			name:      "empty",
+			converter: &Converter{},
+			input: Metric(
+				metric.New(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"value": 42.0,
+					},
+					time.Unix(0, 0),
+				),
+			),
+			expected: Metric(
+				metric.New(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"value": 42.0,
+					},
+					time.Unix(0, 0),
+				),
+			),
+		},
+		{
+			name: "from tag",
+			converter: &Converter{
+				Tags: &Conversion{
+					String:  []string{"string"},
+					Integer: []string{"int"},
+					Boolean: []string{"bool"},
+					Float:   []string{"float"},
+				},
+			},
+			input: Metric(
+				metric.New(
+					"cpu",
+					map[string]string{
+						"float":  "42",
+						"int":    "42",
+						"bool":   "true",
+						"string": "howdy",
+						"other":  "unchanged",
+					},
+					map[string]interface{}{
+						"value": 42.0,
+					},
+					time.Unix(0, 0),
+				),
+			),
+			expected: Metric(
+				metric.New(
+					"cpu",
+					map[string]string{
+						"other": "unchanged",
+					},
+					map[string]interface{}{
+						"value":  42.0,
+						"float":  42.0,
+						"int":    int64(42),
+						"bool":   true,
+						"string": "howdy",
+					},
+					time.Unix(0, 0),
+				),
+			),
+		},
+		{
+			name: "from string field",
+			converter: &Converter{
+				Fields: &Conversion{
+					Tag:     []string{"a"},
+					Integer: []string{"b", "b1", "b2"},
+					Boolean: []string{"c", "c1"},
+					Float:   []string{"d"},
+				},
+			},
+			input: Metric(
+				metric.New(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"a":  "howdy",
+						"b":  "42",
+						"b1": "42.2",
+						"b2": "0x2A",
+						"c":  "true",
+						"c1": "0",
+						"d":  "42.0",
+					},
+					time.Unix(0, 0),
+				),
+			),
+			expected: Metric(
+				metric.New(
+					"cpu",
+					map[string]string{
+						"a": "howdy",
+					},
+					map[string]interface{}{
+						"b":  int64(42),
+						"b1": int64(42),
+						"b2": int64(42),
+						"c":  true,
+						"c1": false,
+						"d":  42.0,
+					},
+					time.Unix(0, 0),
+				),
+			),
+		},
+		{
+			name: "from float field",
+			converter: &Converter{
+				Fields: &Conversion{
+					Tag:     []string{"a"},
+					String:  []string{"b"},
+					Integer: []string{"c"},
+					Boolean: []string{"d"},
+				},
+			},
+			input: Metric(
+				metric.New(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"a": 42.5,
+						"b": 42.5,
+						"c": 42.5,
+						"d": 42.5,
+					},
+					time.Unix(0, 0),
+				),
+			),
+			expected: Metric(
+				metric.New(
+					"cpu",
+					map[string]string{
+						"a": "42.5",
+					},
+					map[string]interface{}{
+						"b": "42.5",
+						"c": int64(42),
+						"d": true,
+					},
+					time.Unix(0, 0),
+				),
+			),
+		},
+		{
+			name: "globbing",
+			converter: &Converter{
+				Fields: &Conversion{
+					Integer: []string{"int_*"},
+				},
+			},
+			input: Metric(
+				metric.New(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"int_a":   "1",
+						"int_b":   "2",
+						"float_a": 1.0,
+					},
+					time.Unix(0, 0),
+				),
+			),
+			expected: Metric(
+				metric.New(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"int_a":   int64(1),
+						"int_b":   int64(2),
+						"float_a": 1.0,
+					},
+					time.Unix(0, 0),
+				),
+			),
+		},
+		{
+			name: "invalid values are left unchanged",
+			converter: &Converter{
+				Fields: &Conversion{
+					Integer: []string{"a"},
+					Boolean: []string{"b"},
+				},
+			},
+			input: Metric(
+				metric.New(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"a": "not a number",
+						"b": "maybe",
+					},
+					time.Unix(0, 0),
+				),
+			),
+			expected: Metric(
+				metric.New(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"a": "not a number",
+						"b": "maybe",
+					},
+					time.Unix(0, 0),
+				),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := tt.converter.Apply(tt.input)
+
+			require.Len(t, metrics, 1)
+			assert.Equal(t, tt.expected.Name(), metrics[0].Name())
+			assert.Equal(t, tt.expected.Tags(), metrics[0].Tags())
+			assert.Equal(t, tt.expected.Fields(), metrics[0].Fields())
+			assert.Equal(t, tt.expected.Time(), metrics[0].Time())
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package enum
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+var sampleConfig = `
+  [[processors.enum.mapping]]
+    ## Name of the field to map
+    field = "status"
+
+    ## Name of the tag to map, instead of a field
+    # tag = "status"
+
+    ## Destination field or tag to be used for the mapped value.  By default
+    ## the source field or tag is used, overwriting the original value.
+    # dest = "status_code"
+
+    ## Default value to be used for all values not contained in the mapping
+    ## table.  When unset, the unmodified value for the field will be used if
+    ## no match is found.
+    # default = 0
+
+    ## Table of mappings
+    [processors.enum.mapping.value_mappings]
+      green = 1
+      yellow = 2
+      red = 3
+`
+
+type EnumMapper struct {
+	Mappings []Mapping `toml:"mapping"`
+}
+
+type Mapping struct {
+	Tag           string
+	Field         string
+	Dest          string
+	Default       interface{}
+	ValueMappings map[string]interface{}
+}
+
+func (mapper *EnumMapper) SampleConfig() string {
+	return sampleConfig
+}
+
+func (mapper *EnumMapper) Description() string {
+	return "Map enum values according to given table."
+}
+
+func (mapper *EnumMapper) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		out = append(out, mapper.applyMappings(m))
+	}
+	return out
+}
+
+func (mapper *EnumMapper) applyMappings(m telegraf.Metric) telegraf.Metric {
+	tags := m.Tags()
+	fields := m.Fields()
+
+	changed := false
+	for _, mapping := range mapper.Mappings {
+		switch {
+		case mapping.Field != "":
+			value, ok := fields[mapping.Field]
+			if !ok {
+				continue
+			}
+			adjusted, ok := mapping.mapValue(toString(value))
+			if !ok {
+				continue
+			}
+			fields[mapping.getDestination()] = adjusted
+			changed = true
+		case mapping.Tag != "":
+			value, ok := tags[mapping.Tag]
+			if !ok {
+				continue
+			}
+			adjusted, ok := mapping.mapValue(value)
+			if !ok {
+				continue
+			}
+			// tag values are always strings
+			tags[mapping.getDestination()] = toString(adjusted)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return m
+	}
+
+	mapped, err := metric.New(m.Name(), tags, fields, m.Time(), m.Type())
+	if err != nil {
+		log.Printf("E! [processors.enum] could not map metric %s: %s", m.Name(), err)
+		return m
+	}
+	mapped.SetAggregate(m.IsAggregate())
+	return mapped
+}
+
+// mapValue returns the mapped value of the original value, or the default
+// value if there is no mapping for it.
+func (mapping *Mapping) mapValue(original string) (interface{}, bool) {
+	if mapped, found := mapping.ValueMappings[original]; found {
+		return mapped, true
+	}
+	if mapping.Default != nil {
+		return mapping.Default, true
+	}
+	return nil, false
+}
+
+func (mapping *Mapping) getDestination() string {
+	if mapping.Dest != "" {
+		return mapping.Dest
+	}
+	if mapping.Field != "" {
+		return mapping.Field
+	}
+	return mapping.Tag
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+func init() {
+	processors.Add("enum", func() telegraf.Processor {
+		return &EnumMapper{}
+	})
+}
@@ -0,0 +1,126 @@
+package enum
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestMetric() telegraf.Metric {
+	m, _ := metric.New("m1",
+		map[string]string{"tag": "tag_value"},
+		map[string]interface{}{
+			"string_value": "test",
+			"int_value":    int64(13),
+			"true_value":   true,
+		},
+		time.Now(),
+	)
+	return m
+}
+
+func calculateProcessedValues(mapper EnumMapper, m telegraf.Metric) map[string]interface{} {
+	processed := mapper.Apply(m)
+	return processed[0].Fields()
+}
+
+func calculateProcessedTags(mapper EnumMapper, m telegraf.Metric) map[string]string {
+	processed := mapper.Apply(m)
+	return processed[0].Tags()
+}
+
+func assertFieldValue(t *testing.T, expected interface{}, field string, fields map[string]interface{}) {
+	value, present := fields[field]
+	require.True(t, present, "value of field '"+field+"' was not present")
+	assert.EqualValues(t, expected, value)
+}
+
+func TestRetainsMetric(t *testing.T) {
+	mapper := EnumMapper{}
+	source := createTestMetric()
+
+	target := mapper.Apply(source)[0]
+	fields := target.Fields()
+
+	assertFieldValue(t, "test", "string_value", fields)
+	assertFieldValue(t, 13, "int_value", fields)
+	assertFieldValue(t, true, "true_value", fields)
+	assert.Equal(t, "m1", target.Name())
+	assert.Equal(t, source.Tags(), target.Tags())
+	assert.Equal(t, source.Time(), target.Time())
+}
+
+func TestMapsSingleStringValue(t *testing.T) {
+	mapper := EnumMapper{Mappings: []Mapping{{Field: "string_value", ValueMappings: map[string]interface{}{"test": int64(1)}}}}
+
+	fields := calculateProcessedValues(mapper, createTestMetric())
+
+	assertFieldValue(t, 1, "string_value", fields)
+}
+
+func TestMapsNonStringValues(t *testing.T) {
+	mapper := EnumMapper{Mappings: []Mapping{
+		{Field: "true_value", ValueMappings: map[string]interface{}{"true": int64(1)}},
+		{Field: "int_value", ValueMappings: map[string]interface{}{"13": "thirteen"}},
+	}}
+
+	fields := calculateProcessedValues(mapper, createTestMetric())
+
+	assertFieldValue(t, 1, "true_value", fields)
+	assertFieldValue(t, "thirteen", "int_value", fields)
+}
+
+func TestMapsSingleStringValueTag(t *testing.T) {
+	mapper := EnumMapper{Mappings: []Mapping{{Tag: "tag", ValueMappings: map[string]interface{}{"tag_value": "valuable"}}}}
+
+	tags := calculateProcessedTags(mapper, createTestMetric())
+
+	assert.Equal(t, "valuable", tags["tag"])
+}
+
+func TestNoFailureOnMappingsOnNonExistingFields(t *testing.T) {
+	mapper := EnumMapper{Mappings: []Mapping{{Field: "field_missing", ValueMappings: map[string]interface{}{"missing": "nada"}}}}
+
+	fields := calculateProcessedValues(mapper, createTestMetric())
+
+	assertFieldValue(t, "test", "string_value", fields)
+}
+
+func TestRetainsUnmappedValue(t *testing.T) {
+	mapper := EnumMapper{Mappings: []Mapping{{Field: "string_value", ValueMappings: map[string]interface{}{"other": int64(1)}}}}
+
+	fields := calculateProcessedValues(mapper, createTestMetric())
+
+	assertFieldValue(t, "test", "string_value", fields)
+}
+
+func TestMapsToDefaultValueOnUnknownSourceValue(t *testing.T) {
+	mapper := EnumMapper{Mappings: []Mapping{{Field: "string_value", Default: int64(42), ValueMappings: map[string]interface{}{"other": int64(1)}}}}
+
+	fields := calculateProcessedValues(mapper, createTestMetric())
+
+	assertFieldValue(t, 42, "string_value", fields)
+}
+
+func TestWritesToDestination(t *testing.T) {
+	mapper := EnumMapper{Mappings: []Mapping{{Field: "string_value", Dest: "string_code", ValueMappings: map[string]interface{}{"test": int64(1)}}}}
+
+	fields := calculateProcessedValues(mapper, createTestMetric())
+
+	assertFieldValue(t, "test", "string_value", fields)
+	assertFieldValue(t, 1, "string_code", fields)
+}
+
+func TestWritesTagToDestination(t *testing.T) {
+	mapper := EnumMapper{Mappings: []Mapping{{Tag: "tag", Dest: "tag_code", ValueMappings: map[string]interface{}{"tag_value": int64(1)}}}}
+
+	tags := calculateProcessedTags(mapper, createTestMetric())
+
+	assert.Equal(t, "tag_value", tags["tag"])
+	assert.Equal(t, "1", tags["tag_code"])
+}
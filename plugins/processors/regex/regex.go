@@ -0,0 +1,143 @@
+package regex
+
+import (
+	"log"
+	"regexp"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+type Regex struct {
+	Tags   []converter
+	Fields []converter
+
+	regexCache map[string]*regexp.Regexp
+}
+
+type converter struct {
+	Key         string
+	Pattern     string
+	Replacement string
+	ResultKey   string
+}
+
+const sampleConfig = `
+  ## Tag and field conversions defined in a separate sub-tables
+  # [[processors.regex.tags]]
+  #   ## Tag to change
+  #   key = "resp_code"
+  #   ## Regular expression to match on a tag value
+  #   pattern = "^(\\d)\\d\\d$"
+  #   ## Pattern for constructing a new value (${1} represents first subgroup)
+  #   replacement = "${1}xx"
+
+  # [[processors.regex.fields]]
+  #   key = "request"
+  #   ## All the power of the Go regular expressions available here
+  #   ## For example, named subgroups
+  #   pattern = "^/api(?P<method>/[\\w/]+)\\S*"
+  #   replacement = "${method}"
+  #   ## If result_key is present, a new field will be created
+  #   ## instead of changing existing field
+  #   result_key = "method"
+
+  ## Multiple conversions may be applied for one field sequentially
+  ## Let's extract one more value
+  # [[processors.regex.fields]]
+  #   key = "request"
+  #   pattern = ".*category=(\\w+).*"
+  #   replacement = "${1}"
+  #   result_key = "search_category"
+`
+
+func NewRegex() *Regex {
+	return &Regex{
+		regexCache: make(map[string]*regexp.Regexp),
+	}
+}
+
+func (r *Regex) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Regex) Description() string {
+	return "Transforms tag and field values with regex pattern"
+}
+
+func (r *Regex) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		out = append(out, r.apply(m))
+	}
+	return out
+}
+
+func (r *Regex) apply(m telegraf.Metric) telegraf.Metric {
+	tags := m.Tags()
+	fields := m.Fields()
+
+	changed := false
+	for _, c := range r.Tags {
+		if value, ok := tags[c.Key]; ok {
+			if key, newValue := r.convert(c, value); newValue != "" {
+				tags[key] = newValue
+				changed = true
+			}
+		}
+	}
+
+	for _, c := range r.Fields {
+		if value, ok := fields[c.Key]; ok {
+			// only string fields can be converted
+			if value, ok := value.(string); ok {
+				if key, newValue := r.convert(c, value); newValue != "" {
+					fields[key] = newValue
+					changed = true
+				}
+			}
+		}
+	}
+
+	if !changed {
+		return m
+	}
+
+	converted, err := metric.New(m.Name(), tags, fields, m.Time(), m.Type())
+	if err != nil {
+		log.Printf("E! [processors.regex] could not convert metric %s: %s", m.Name(), err)
+		return m
+	}
+	converted.SetAggregate(m.IsAggregate())
+	return converted
+}
+
+// convert returns the key and the new value for a matching value, or an
+// empty value if the pattern does not match.
+func (r *Regex) convert(c converter, src string) (string, string) {
+	regex, compiled := r.regexCache[c.Pattern]
+	if !compiled {
+		var err error
+		regex, err = regexp.Compile(c.Pattern)
+		if err != nil {
+			log.Printf("E! [processors.regex] invalid pattern %q: %s", c.Pattern, err)
+		}
+		r.regexCache[c.Pattern] = regex
+	}
+	if regex == nil || !regex.MatchString(src) {
+		return "", ""
+	}
+
+	value := regex.ReplaceAllString(src, c.Replacement)
+	if c.ResultKey == "" {
+		return c.Key, value
+	}
+	return c.ResultKey, value
+}
+
+func init() {
+	processors.Add("regex", func() telegraf.Processor {
+		return NewRegex()
+	})
+}
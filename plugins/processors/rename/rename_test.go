@@ -0,0 +1,84 @@
+package rename
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMetric(name string, tags map[string]string, fields map[string]interface{}) telegraf.Metric {
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	m, _ := metric.New(name, tags, fields, time.Now())
+	return m
+}
+
+func TestMeasurementRename(t *testing.T) {
+	r := Rename{
+		Replaces: []Replace{
+			{Measurement: "foo", Dest: "bar"},
+			{Measurement: "baz", Dest: "quux"},
+		},
+	}
+	m1 := newMetric("foo", nil, map[string]interface{}{"value": 42})
+	m2 := newMetric("bar", nil, map[string]interface{}{"value": 42})
+	m3 := newMetric("baz", nil, map[string]interface{}{"value": 42})
+	results := r.Apply(m1, m2, m3)
+	require.Len(t, results, 3)
+	assert.Equal(t, "bar", results[0].Name())
+	assert.Equal(t, "bar", results[1].Name())
+	assert.Equal(t, "quux", results[2].Name())
+}
+
+func TestTagRename(t *testing.T) {
+	r := Rename{
+		Replaces: []Replace{
+			{Tag: "hostname", Dest: "host"},
+		},
+	}
+	m := newMetric("foo",
+		map[string]string{"hostname": "localhost", "region": "east-1"},
+		map[string]interface{}{"value": 42})
+
+	results := r.Apply(m)
+	require.Len(t, results, 1)
+	assert.Equal(t, map[string]string{"host": "localhost", "region": "east-1"},
+		results[0].Tags())
+}
+
+func TestFieldRename(t *testing.T) {
+	r := Rename{
+		Replaces: []Replace{
+			{Field: "time_msec", Dest: "time"},
+		},
+	}
+	m := newMetric("foo", nil, map[string]interface{}{"time_msec": int64(1250), "count": int64(1)})
+
+	results := r.Apply(m)
+	require.Len(t, results, 1)
+	assert.Equal(t, map[string]interface{}{"time": int64(1250), "count": int64(1)},
+		results[0].Fields())
+}
+
+func TestUnchangedMetricIsReturned(t *testing.T) {
+	r := Rename{
+		Replaces: []Replace{
+			{Tag: "hostname", Dest: "host"},
+		},
+	}
+	m := newMetric("foo", map[string]string{"host": "localhost"},
+		map[string]interface{}{"value": 42})
+
+	results := r.Apply(m)
+	require.Len(t, results, 1)
+	assert.True(t, m == results[0])
+}
@@ -0,0 +1,96 @@
+package carbon2
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+var sanitizer = strings.NewReplacer(" ", "_", "=", "_")
+
+// Carbon2Serializer writes one line per field in the Carbon 2.0 format:
+//
+//   metric=<name> field=<field> <intrinsic tags>  <meta tags> <value> <time>
+//
+// The intrinsic tags identify the series, the meta tags, separated from them
+// by two spaces, only describe it.
+type Carbon2Serializer struct {
+	// MetaTags are the tags written as meta tags, all other tags are
+	// intrinsic tags.
+	MetaTags []string
+}
+
+func (s *Carbon2Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	meta := make(map[string]bool, len(s.MetaTags))
+	for _, k := range s.MetaTags {
+		meta[k] = true
+	}
+
+	var intrinsic, metaTags bytes.Buffer
+	tags := metric.Tags()
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b := &intrinsic
+		if meta[k] {
+			b = &metaTags
+		}
+		b.WriteByte(' ')
+		b.WriteString(sanitizer.Replace(k))
+		b.WriteByte('=')
+		b.WriteString(sanitizer.Replace(tags[k]))
+	}
+
+	fields := metric.Fields()
+	names := make([]string, 0, len(fields))
+	for k := range fields {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	timestamp := strconv.FormatInt(metric.UnixNano()/1000000000, 10)
+	var out bytes.Buffer
+	for _, field := range names {
+		value, ok := formatValue(fields[field])
+		if !ok {
+			continue
+		}
+		out.WriteString("metric=")
+		out.WriteString(sanitizer.Replace(metric.Name()))
+		out.WriteString(" field=")
+		out.WriteString(sanitizer.Replace(field))
+		out.Write(intrinsic.Bytes())
+		out.WriteByte(' ')
+		out.Write(metaTags.Bytes())
+		out.WriteByte(' ')
+		out.WriteString(value)
+		out.WriteByte(' ')
+		out.WriteString(timestamp)
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), nil
+}
+
+// formatValue returns the value of a numeric or boolean field.
+func formatValue(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		if v {
+			return "1", true
+		}
+		return "0", true
+	}
+	return "", false
+}
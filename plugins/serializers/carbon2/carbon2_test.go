@@ -0,0 +1,40 @@
+package carbon2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/influxdata/telegraf/metric"
+)
+
+func TestSerializeMetric(t *testing.T) {
+	now := time.Unix(1538733600, 0)
+	m, err := metric.New("cpu",
+		map[string]string{"cpu": "cpu 0", "dc": "east"},
+		map[string]interface{}{
+			"usage_idle": float64(91.5),
+			"usage_user": int64(5),
+			"healthy":    true,
+			"state":      "ok",
+		},
+		now)
+	assert.NoError(t, err)
+
+	s := Carbon2Serializer{}
+	buf, err := s.Serialize(m)
+	assert.NoError(t, err)
+	assert.Equal(t, "metric=cpu field=healthy cpu=cpu_0 dc=east  1 1538733600\n"+
+		"metric=cpu field=usage_idle cpu=cpu_0 dc=east  91.5 1538733600\n"+
+		"metric=cpu field=usage_user cpu=cpu_0 dc=east  5 1538733600\n",
+		string(buf))
+
+	s = Carbon2Serializer{MetaTags: []string{"dc"}}
+	buf, err = s.Serialize(m)
+	assert.NoError(t, err)
+	assert.Equal(t, "metric=cpu field=healthy cpu=cpu_0  dc=east 1 1538733600\n"+
+		"metric=cpu field=usage_idle cpu=cpu_0  dc=east 91.5 1538733600\n"+
+		"metric=cpu field=usage_user cpu=cpu_0  dc=east 5 1538733600\n",
+		string(buf))
+}
@@ -2,11 +2,22 @@ package influx
 
 import (
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 )
 
 type InfluxSerializer struct {
 }
 
+// Serialize writes a metric in the line protocol. Histograms and summaries
+// are written as a line with their sum and count, and a line per bucket or
+// quantile tagged with its bound, see metric.Flatten.
 func (s *InfluxSerializer) Serialize(m telegraf.Metric) ([]byte, error) {
-	return m.Serialize(), nil
+	if !metric.IsDistribution(m) {
+		return m.Serialize(), nil
+	}
+	var buf []byte
+	for _, fm := range metric.Flatten([]telegraf.Metric{m}) {
+		buf = append(buf, fm.Serialize()...)
+	}
+	return buf, nil
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
 )
 
@@ -70,3 +71,22 @@ func TestSerializeMetricString(t *testing.T) {
 	expS := []string{fmt.Sprintf("cpu,cpu=cpu0 usage_idle=\"foobar\" %d", now.UnixNano())}
 	assert.Equal(t, expS, mS)
 }
+
+func TestSerializeHistogram(t *testing.T) {
+	m, err := metric.New("latency",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"0.1": float64(2), "+Inf": float64(3), "sum": float64(1.5), "count": float64(3)},
+		time.Unix(0, 0),
+		telegraf.Histogram,
+	)
+	assert.NoError(t, err)
+
+	s := InfluxSerializer{}
+	buf, err := s.Serialize(m)
+	assert.NoError(t, err)
+	mS := strings.Split(strings.TrimSpace(string(buf)), "\n")
+	// the bounds are tags instead of fields
+	assert.Len(t, mS, 3)
+	assert.Equal(t, "latency,host=a,le=0.1 bucket=2 0", mS[1])
+	assert.Equal(t, "latency,host=a,le=+Inf bucket=3 0", mS[2])
+}
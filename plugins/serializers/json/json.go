@@ -2,28 +2,33 @@ package json
 
 import (
 	ejson "encoding/json"
+	"strconv"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 )
 
 type JsonSerializer struct {
 	TimestampUnits time.Duration
 }
 
-func (s *JsonSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
-	m := make(map[string]interface{})
+func (s *JsonSerializer) Serialize(m telegraf.Metric) ([]byte, error) {
+	obj := make(map[string]interface{})
 	units_nanoseconds := s.TimestampUnits.Nanoseconds()
 	// if the units passed in were less than or equal to zero,
 	// then serialize the timestamp in seconds (the default)
 	if units_nanoseconds <= 0 {
 		units_nanoseconds = 1000000000
 	}
-	m["tags"] = metric.Tags()
-	m["fields"] = metric.Fields()
-	m["name"] = metric.Name()
-	m["timestamp"] = metric.UnixNano() / units_nanoseconds
-	serialized, err := ejson.Marshal(m)
+	obj["tags"] = m.Tags()
+	obj["fields"] = m.Fields()
+	obj["name"] = m.Name()
+	obj["timestamp"] = m.UnixNano() / units_nanoseconds
+	if metric.IsDistribution(m) {
+		addBuckets(obj, m)
+	}
+	serialized, err := ejson.Marshal(obj)
 	if err != nil {
 		return []byte{}, err
 	}
@@ -31,3 +36,26 @@ func (s *JsonSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 
 	return serialized, nil
 }
+
+// addBuckets moves the buckets of a histogram, or the quantiles of a
+// summary, from the fields to a buckets or quantiles object keyed by bound,
+// and sets the type.
+func addBuckets(obj map[string]interface{}, m telegraf.Metric) {
+	key, typ := "buckets", "histogram"
+	if m.Type() == telegraf.Summary {
+		key, typ = "quantiles", "summary"
+	}
+
+	fields := make(map[string]interface{})
+	buckets := make(map[string]interface{})
+	for k, v := range m.Fields() {
+		if _, err := strconv.ParseFloat(k, 64); err == nil {
+			buckets[k] = v
+		} else {
+			fields[k] = v
+		}
+	}
+	obj["fields"] = fields
+	obj[key] = buckets
+	obj["type"] = typ
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
 )
 
@@ -108,3 +109,18 @@ func TestSerializeMetricWithEscapes(t *testing.T) {
 	expS := []byte(fmt.Sprintf(`{"fields":{"U,age=Idle":90},"name":"My CPU","tags":{"cpu tag":"cpu0"},"timestamp":%d}`, now.Unix()) + "\n")
 	assert.Equal(t, string(expS), string(buf))
 }
+
+func TestSerializeSummary(t *testing.T) {
+	m, err := metric.New("rpc_duration",
+		map[string]string{"service": "a"},
+		map[string]interface{}{"0.5": float64(0.02), "0.99": float64(0.2), "sum": float64(7), "count": int64(100)},
+		time.Unix(0, 0),
+		telegraf.Summary,
+	)
+	assert.NoError(t, err)
+
+	s := JsonSerializer{}
+	buf, err := s.Serialize(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"fields":{"count":100,"sum":7},"name":"rpc_duration","quantiles":{"0.5":0.02,"0.99":0.2},"tags":{"service":"a"},"timestamp":0,"type":"summary"}`+"\n", string(buf))
+}
@@ -0,0 +1,261 @@
+package prometheus
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// PrometheusSerializer writes metrics in the Prometheus text exposition
+// format. It is the reverse of the prometheus parser: the "counter", "gauge"
+// and "value" fields are written as the metric itself, other fields as
+// <name>_<field>, and summaries and histograms as their quantile, bucket, sum
+// and count samples.
+type PrometheusSerializer struct {
+	// ExportTimestamp adds the time of the metric to every sample.
+	ExportTimestamp bool
+}
+
+// family is a Prometheus metric family. label is the label of the quantile
+// or bucket of the summary and histogram samples.
+type family struct {
+	name    string
+	typ     string
+	label   string
+	samples []Sample
+}
+
+// Sample is a Prometheus sample, the series of a metric family and its
+// value.
+type Sample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// Samples returns the Prometheus samples of a metric, the ones written by
+// Serialize.
+func Samples(m telegraf.Metric) []Sample {
+	var samples []Sample
+	for _, f := range families(m) {
+		samples = append(samples, f.samples...)
+	}
+	return samples
+}
+
+// Serialize writes the metric families of one metric, each with its TYPE
+// line.
+func (s *PrometheusSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return s.SerializeBatch([]telegraf.Metric{metric})
+}
+
+// SerializeBatch writes the metric families of the metrics, each family with
+// a single TYPE line followed by the samples of all the metrics in it, as
+// required by the parsers of the exposition format.
+func (s *PrometheusSerializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var buf bytes.Buffer
+	var order []*family
+	index := make(map[string]*family)
+	lines := make(map[*family][][]byte)
+	for _, m := range metrics {
+		var ts string
+		if s.ExportTimestamp {
+			ts = strconv.FormatInt(m.UnixNano()/1000000, 10)
+		}
+		for _, f := range families(m) {
+			prev, ok := index[f.name]
+			if !ok {
+				prev = f
+				index[f.name] = f
+				order = append(order, f)
+			}
+			for _, sample := range f.samples {
+				lines[prev] = append(lines[prev], formatSample(sample, f.label, ts))
+			}
+		}
+	}
+
+	for _, f := range order {
+		buf.WriteString("# TYPE ")
+		buf.WriteString(f.name)
+		buf.WriteByte(' ')
+		buf.WriteString(f.typ)
+		buf.WriteByte('\n')
+		for _, line := range lines[f] {
+			buf.Write(line)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// families returns the metric families of a metric.
+func families(m telegraf.Metric) []*family {
+	name := sanitizeName(m.Name())
+	labels := make(map[string]string)
+	for k, v := range m.Tags() {
+		labels[sanitizeLabel(k)] = v
+	}
+	// Prometheus doesn't have a string value type, so string fields are
+	// turned into labels, like the prometheus_client output does.
+	fields := make(map[string]float64)
+	for k, v := range m.Fields() {
+		switch v := v.(type) {
+		case string:
+			labels[sanitizeLabel(k)] = v
+		case int64:
+			fields[k] = float64(v)
+		case uint64:
+			fields[k] = float64(v)
+		case float64:
+			fields[k] = v
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	if metric.IsDistribution(m) {
+		f := &family{name: name, typ: "summary", label: metric.QuantileTag}
+		suffix := ""
+		if m.Type() == telegraf.Histogram {
+			f.typ, f.label = "histogram", metric.BucketTag
+			suffix = "_bucket"
+		}
+		buckets, sum, count := metric.Buckets(m)
+		for _, b := range buckets {
+			f.samples = append(f.samples, Sample{
+				Name:   name + suffix,
+				Labels: withLabel(labels, f.label, metric.BoundField(b.Bound)),
+				Value:  b.Value,
+			})
+		}
+		f.samples = append(f.samples,
+			Sample{Name: name + "_sum", Labels: labels, Value: sum},
+			Sample{Name: name + "_count", Labels: labels, Value: count})
+		return []*family{f}
+	}
+
+	typ := "untyped"
+	value := "value"
+	switch m.Type() {
+	case telegraf.Counter:
+		typ, value = "counter", "counter"
+	case telegraf.Gauge:
+		typ, value = "gauge", "gauge"
+	}
+	var families []*family
+	for _, k := range keys {
+		fname := name
+		if k != value && k != "value" {
+			fname = sanitizeName(m.Name() + "_" + k)
+		}
+		families = append(families, &family{
+			name:    fname,
+			typ:     typ,
+			samples: []Sample{{Name: fname, Labels: labels, Value: fields[k]}},
+		})
+	}
+	return families
+}
+
+// withLabel returns a copy of the labels with an extra label.
+func withLabel(labels map[string]string, name, value string) map[string]string {
+	l := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		l[k] = v
+	}
+	l[name] = value
+	return l
+}
+
+// formatSample formats a sample line, with its labels sorted except the
+// quantile or bucket label, which comes last.
+func formatSample(sample Sample, last string, ts string) []byte {
+	keys := make([]string, 0, len(sample.Labels))
+	for k := range sample.Labels {
+		if k != last {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	if _, ok := sample.Labels[last]; ok {
+		keys = append(keys, last)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(sample.Name)
+	if len(keys) > 0 {
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeLabel(&buf, k, sample.Labels[k])
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(formatValue(sample.Value))
+	if ts != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(ts)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func writeLabel(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	buf.WriteString(`="`)
+	buf.WriteString(labelValueEscaper.Replace(value))
+	buf.WriteByte('"')
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sanitizeName replaces the characters not allowed in metric names.
+func sanitizeName(name string) string {
+	return sanitize(name, true)
+}
+
+// sanitizeLabel replaces the characters not allowed in label names.
+func sanitizeLabel(name string) string {
+	return sanitize(name, false)
+}
+
+func sanitize(name string, colon bool) string {
+	b := []byte(name)
+	for i, c := range b {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		case c >= '0' && c <= '9' && i > 0:
+		case c == ':' && colon:
+		default:
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,116 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/prometheus"
+)
+
+func TestSerializeUntyped(t *testing.T) {
+	now := time.Unix(1538733600, 0)
+	m, err := metric.New("cpu",
+		map[string]string{"cpu": "cpu0", "host.name": "web01"},
+		map[string]interface{}{
+			"usage_idle": float64(91.5),
+			"usage_user": int64(5),
+			"state":      "ok",
+		},
+		now)
+	require.NoError(t, err)
+
+	s := PrometheusSerializer{}
+	buf, err := s.Serialize(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `# TYPE cpu_usage_idle untyped
+cpu_usage_idle{cpu="cpu0",host_name="web01",state="ok"} 91.5
+# TYPE cpu_usage_user untyped
+cpu_usage_user{cpu="cpu0",host_name="web01",state="ok"} 5
+`, string(buf))
+
+	s = PrometheusSerializer{ExportTimestamp: true}
+	buf, err = s.Serialize(m)
+	assert.NoError(t, err)
+	assert.Contains(t, string(buf), "} 91.5 1538733600000\n")
+}
+
+func TestSerializeCounterAndGauge(t *testing.T) {
+	now := time.Now()
+	c, err := metric.New("requests", map[string]string{"path": `/a"b`},
+		map[string]interface{}{"counter": float64(42)}, now, telegraf.Counter)
+	require.NoError(t, err)
+	g, err := metric.New("temperature", nil,
+		map[string]interface{}{"gauge": float64(-3.5)}, now, telegraf.Gauge)
+	require.NoError(t, err)
+
+	s := PrometheusSerializer{}
+	buf, err := s.SerializeBatch([]telegraf.Metric{c, g})
+	assert.NoError(t, err)
+	assert.Equal(t, `# TYPE requests counter
+requests{path="/a\"b"} 42
+# TYPE temperature gauge
+temperature -3.5
+`, string(buf))
+}
+
+func TestSerializeBatchGroupsFamilies(t *testing.T) {
+	now := time.Now()
+	var metrics []telegraf.Metric
+	for _, host := range []string{"a", "b"} {
+		m, err := metric.New("up", map[string]string{"host": host},
+			map[string]interface{}{"gauge": float64(1)}, now, telegraf.Gauge)
+		require.NoError(t, err)
+		metrics = append(metrics, m)
+	}
+
+	s := PrometheusSerializer{}
+	buf, err := s.SerializeBatch(metrics)
+	assert.NoError(t, err)
+	assert.Equal(t, `# TYPE up gauge
+up{host="a"} 1
+up{host="b"} 1
+`, string(buf))
+}
+
+const summaryAndHistogram = `# TYPE http_request_duration_microseconds summary
+http_request_duration_microseconds{handler="prometheus",quantile="0.5"} 552048.506
+http_request_duration_microseconds{handler="prometheus",quantile="0.9"} 5.876804288e+06
+http_request_duration_microseconds_sum{handler="prometheus"} 1.8909097205e+07
+http_request_duration_microseconds_count{handler="prometheus"} 9
+# TYPE apiserver_request_latencies histogram
+apiserver_request_latencies_bucket{resource="bindings",le="125000"} 1994
+apiserver_request_latencies_bucket{resource="bindings",le="250000"} 1997
+apiserver_request_latencies_bucket{resource="bindings",le="+Inf"} 2025
+apiserver_request_latencies_sum{resource="bindings"} 1.02726334e+08
+apiserver_request_latencies_count{resource="bindings"} 2025
+`
+
+func TestSerializeRoundTrip(t *testing.T) {
+	p := prometheus.Parser{}
+	metrics, err := p.Parse([]byte(summaryAndHistogram))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	s := PrometheusSerializer{}
+	buf, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	parsed, err := p.Parse(buf)
+	require.NoError(t, err)
+	require.Len(t, parsed, 2)
+	byName := make(map[string]telegraf.Metric)
+	for _, m := range parsed {
+		byName[m.Name()] = m
+	}
+	for _, m := range metrics {
+		require.Contains(t, byName, m.Name())
+		assert.Equal(t, m.Type(), byName[m.Name()].Type())
+		assert.Equal(t, m.Tags(), byName[m.Name()].Tags())
+		assert.Equal(t, m.Fields(), byName[m.Name()].Fields())
+	}
+}
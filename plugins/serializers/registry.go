@@ -6,9 +6,13 @@ import (
 
 	"github.com/influxdata/telegraf"
 
+	"github.com/influxdata/telegraf/plugins/serializers/carbon2"
 	"github.com/influxdata/telegraf/plugins/serializers/graphite"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/plugins/serializers/json"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
+	"github.com/influxdata/telegraf/plugins/serializers/splunkmetric"
+	"github.com/influxdata/telegraf/plugins/serializers/template"
 )
 
 // SerializerOutput is an interface for output plugins that are able to
@@ -27,10 +31,20 @@ type Serializer interface {
 	Serialize(metric telegraf.Metric) ([]byte, error)
 }
 
+// BatchSerializer is implemented by serializers whose format has to see all
+// the metrics written at once, ie to group them. Outputs writing a batch of
+// metrics as a single document should prefer SerializeBatch.
+type BatchSerializer interface {
+	// SerializeBatch takes a batch of telegraf metrics and turns them into
+	// a single byte buffer, with a newline at the end of the buffer.
+	SerializeBatch(metrics []telegraf.Metric) ([]byte, error)
+}
+
 // Config is a struct that covers the data types needed for all serializer types,
 // and can be used to instantiate _any_ of the serializers.
 type Config struct {
-	// Dataformat can be one of: influx, graphite, or json
+	// Dataformat can be one of: influx, graphite, json, prometheus, carbon2,
+	// splunkmetric, or template
 	DataFormat string
 
 	// Prefix to add to all measurements, only supports Graphite
@@ -42,6 +56,18 @@ type Config struct {
 
 	// Timestamp units to use for JSON formatted output
 	TimestampUnits time.Duration
+
+	// Add the timestamp to the samples of the Prometheus format
+	PrometheusExportTimestamp bool
+
+	// Tags written as meta tags by the Carbon2 format
+	Carbon2MetaTags []string
+
+	// Wrap the Splunk metrics in HTTP Event Collector events
+	SplunkMetricHecRouting bool
+
+	// Go template of the template format
+	LineTemplate string
 }
 
 // NewSerializer a Serializer interface based on the given config.
@@ -55,6 +81,14 @@ func NewSerializer(config *Config) (Serializer, error) {
 		serializer, err = NewGraphiteSerializer(config.Prefix, config.Template)
 	case "json":
 		serializer, err = NewJsonSerializer(config.TimestampUnits)
+	case "prometheus":
+		serializer, err = NewPrometheusSerializer(config.PrometheusExportTimestamp)
+	case "carbon2":
+		serializer, err = NewCarbon2Serializer(config.Carbon2MetaTags)
+	case "splunkmetric":
+		serializer, err = NewSplunkMetricSerializer(config.SplunkMetricHecRouting)
+	case "template":
+		serializer, err = NewTemplateSerializer(config.LineTemplate)
 	default:
 		err = fmt.Errorf("Invalid data format: %s", config.DataFormat)
 	}
@@ -75,3 +109,21 @@ func NewGraphiteSerializer(prefix, template string) (Serializer, error) {
 		Template: template,
 	}, nil
 }
+
+func NewPrometheusSerializer(exportTimestamp bool) (Serializer, error) {
+	return &prometheus.PrometheusSerializer{
+		ExportTimestamp: exportTimestamp,
+	}, nil
+}
+
+func NewCarbon2Serializer(metaTags []string) (Serializer, error) {
+	return &carbon2.Carbon2Serializer{MetaTags: metaTags}, nil
+}
+
+func NewSplunkMetricSerializer(hecRouting bool) (Serializer, error) {
+	return &splunkmetric.SplunkMetricSerializer{HecRouting: hecRouting}, nil
+}
+
+func NewTemplateSerializer(lineTemplate string) (Serializer, error) {
+	return template.NewTemplateSerializer(lineTemplate)
+}
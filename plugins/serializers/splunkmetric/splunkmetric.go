@@ -0,0 +1,87 @@
+package splunkmetric
+
+import (
+	ejson "encoding/json"
+	"sort"
+
+	"github.com/influxdata/telegraf"
+)
+
+// SplunkMetricSerializer writes one JSON object per field in the format of
+// the Splunk metrics index, with the metric name as <measurement>.<field>
+// and the tags as dimensions.
+type SplunkMetricSerializer struct {
+	// HecRouting wraps every object in a HTTP Event Collector event, with
+	// the host tag as the host of the event, so it can be posted to the HEC
+	// endpoint as is.
+	HecRouting bool
+}
+
+func (s *SplunkMetricSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	fields := metric.Fields()
+	names := make([]string, 0, len(fields))
+	for k := range fields {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	// time in seconds with a millisecond precision
+	ts := float64(metric.UnixNano()/1000000) / 1000
+
+	var out []byte
+	for _, field := range names {
+		value, ok := numericValue(fields[field])
+		if !ok {
+			continue
+		}
+
+		dims := make(map[string]interface{})
+		for k, v := range metric.Tags() {
+			dims[k] = v
+		}
+		dims["metric_name"] = metric.Name() + "." + field
+		dims["_value"] = value
+
+		var obj map[string]interface{}
+		if s.HecRouting {
+			obj = map[string]interface{}{
+				"time":   ts,
+				"event":  "metric",
+				"fields": dims,
+			}
+			if host, ok := dims["host"]; ok {
+				obj["host"] = host
+				delete(dims, "host")
+			}
+		} else {
+			obj = dims
+			obj["time"] = ts
+		}
+
+		b, err := ejson.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b...)
+		out = append(out, '\n')
+	}
+	return out, nil
+}
+
+// numericValue returns the value of a numeric or boolean field as a float.
+func numericValue(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
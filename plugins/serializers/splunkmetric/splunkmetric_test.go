@@ -0,0 +1,35 @@
+package splunkmetric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/influxdata/telegraf/metric"
+)
+
+func TestSerializeMetric(t *testing.T) {
+	now := time.Unix(1538733600, 123000000)
+	m, err := metric.New("cpu",
+		map[string]string{"cpu": "cpu0", "host": "web01"},
+		map[string]interface{}{
+			"usage_idle": float64(91.5),
+			"state":      "ok",
+		},
+		now)
+	assert.NoError(t, err)
+
+	s := SplunkMetricSerializer{}
+	buf, err := s.Serialize(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"_value":91.5,"cpu":"cpu0","host":"web01",`+
+		`"metric_name":"cpu.usage_idle","time":1538733600.123}`+"\n", string(buf))
+
+	s = SplunkMetricSerializer{HecRouting: true}
+	buf, err = s.Serialize(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"event":"metric","fields":{"_value":91.5,"cpu":"cpu0",`+
+		`"metric_name":"cpu.usage_idle"},"host":"web01","time":1538733600.123}`+"\n",
+		string(buf))
+}
@@ -0,0 +1,57 @@
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// TemplateSerializer writes every metric with a Go text/template. The
+// template is executed once per metric with a Metric, a newline is added to
+// its output when it does not end with one.
+type TemplateSerializer struct {
+	tmpl *template.Template
+}
+
+// Metric is the data passed to the template.
+type Metric struct {
+	Name   string
+	Tags   map[string]string
+	Fields map[string]interface{}
+	Time   time.Time
+	// Timestamp is the time in seconds since the epoch.
+	Timestamp int64
+}
+
+// NewTemplateSerializer parses the template.
+func NewTemplateSerializer(text string) (*TemplateSerializer, error) {
+	if text == "" {
+		return nil, fmt.Errorf("line_template is required by the template data format")
+	}
+	tmpl, err := template.New("line_template").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid line_template, %s", err)
+	}
+	return &TemplateSerializer{tmpl: tmpl}, nil
+}
+
+func (s *TemplateSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	var buf bytes.Buffer
+	err := s.tmpl.Execute(&buf, Metric{
+		Name:      metric.Name(),
+		Tags:      metric.Tags(),
+		Fields:    metric.Fields(),
+		Time:      metric.Time(),
+		Timestamp: metric.UnixNano() / 1000000000,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() > 0 && buf.Bytes()[buf.Len()-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
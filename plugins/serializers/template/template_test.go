@@ -0,0 +1,46 @@
+package template
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/metric"
+)
+
+func TestSerializeMetric(t *testing.T) {
+	now := time.Unix(1538733600, 0)
+	m, err := metric.New("cpu",
+		map[string]string{"cpu": "cpu0", "host": "web01"},
+		map[string]interface{}{
+			"usage_idle": float64(91.5),
+			"usage_user": int64(5),
+		},
+		now)
+	require.NoError(t, err)
+
+	s, err := NewTemplateSerializer(
+		`{{range $k, $v := .Fields}}{{$.Tags.host}}.{{$.Name}}.{{$k}} {{$v}} {{$.Timestamp}}
+{{end}}`)
+	require.NoError(t, err)
+	buf, err := s.Serialize(m)
+	assert.NoError(t, err)
+	assert.Equal(t, "web01.cpu.usage_idle 91.5 1538733600\n"+
+		"web01.cpu.usage_user 5 1538733600\n", string(buf))
+
+	// a newline is added to single line templates
+	s, err = NewTemplateSerializer(`{{.Name}} {{.Tags.missing}}{{.Time.Year}}`)
+	require.NoError(t, err)
+	buf, err = s.Serialize(m)
+	assert.NoError(t, err)
+	assert.Equal(t, "cpu 2018\n", string(buf))
+}
+
+func TestInvalidTemplate(t *testing.T) {
+	_, err := NewTemplateSerializer("")
+	assert.Error(t, err)
+	_, err = NewTemplateSerializer("{{.Name")
+	assert.Error(t, err)
+}
@@ -0,0 +1,26 @@
+package telegraf
+
+// StateStore is a key-value store for the state a plugin keeps across
+// restarts. Every plugin gets its own store, which the agent saves to the
+// state directory while it runs and when it stops.
+type StateStore interface {
+	// Get returns the value of a key, false when it is not set.
+	Get(key string) ([]byte, bool)
+
+	// Set sets the value of a key.
+	Set(key string, value []byte)
+
+	// Delete removes a key.
+	Delete(key string)
+
+	// Keys returns the keys which are set, in sorted order.
+	Keys() []string
+}
+
+// StatefulPlugin is a plugin, of any type, which keeps state in a StateStore.
+// The agent sets the store before the plugin is started, connected or
+// first used.
+type StatefulPlugin interface {
+	// SetStateStore sets the store of the plugin.
+	SetStateStore(store StateStore)
+}